@@ -5,15 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 
 	db "github.com/Andrew-2609/simple-bank/db/sqlc"
 	"github.com/Andrew-2609/simple-bank/token"
+	"github.com/Andrew-2609/simple-bank/util"
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 )
 
 type createAccountRequest struct {
-	Currency string `json:"currency" binding:"required,currency"`
+	Currency string `json:"currency" binding:"required"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
@@ -24,6 +26,11 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
+	if err := server.checkEnabledCurrency(req.Currency); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
 	arg := db.CreateAccountParams{
@@ -51,8 +58,9 @@ func (server *Server) createAccount(ctx *gin.Context) {
 }
 
 type listAccountsRequest struct {
-	Page     int32 `form:"page" binding:"required,min=1"`
-	Quantity int32 `form:"quantity" binding:"max=200"`
+	Page     int32  `form:"page" binding:"required,min=1"`
+	Quantity int32  `form:"quantity" binding:"max=200"`
+	Owner    string `form:"owner" binding:"omitempty,alphanum"`
 }
 
 func (server *Server) listAccounts(ctx *gin.Context) {
@@ -69,8 +77,20 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
+	owner := authPayload.Username
+
+	if len(req.Owner) > 0 && req.Owner != owner {
+		if !slices.Contains([]string{util.BankerRole, util.AdminRole}, authPayload.Role) {
+			err := errors.New("only bankers and admins can list accounts of other users")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		owner = req.Owner
+	}
+
 	foundAccounts, err := server.store.ListAccountsByOwner(ctx, db.ListAccountsByOwnerParams{
-		Owner:  authPayload.Username,
+		Owner:  owner,
 		Limit:  req.Quantity,
 		Offset: (req.Page - 1) * req.Quantity,
 	})
@@ -108,7 +128,7 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	if authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload); foundAccount.Owner != authPayload.Username {
+	if authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload); !hasAccountAccess(authPayload, foundAccount, util.BankerRole, util.AdminRole) {
 		err := errors.New("account doesn't belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
@@ -151,7 +171,7 @@ func (server *Server) updateAccount(ctx *gin.Context) {
 		return
 	}
 
-	if authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload); originalAccount.Owner != authPayload.Username {
+	if authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload); !hasAccountAccess(authPayload, originalAccount, util.AdminRole) {
 		err := errors.New("account doesn't belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
@@ -194,7 +214,7 @@ func (server *Server) deleteAccount(ctx *gin.Context) {
 		return
 	}
 
-	if authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload); originalAccount.Owner != authPayload.Username {
+	if authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload); !hasAccountAccess(authPayload, originalAccount, util.AdminRole) {
 		err := errors.New("account doesn't belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
@@ -207,3 +227,46 @@ func (server *Server) deleteAccount(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusNoContent, nil)
 }
+
+type freezeAccountRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) freezeAccount(ctx *gin.Context) {
+	server.setAccountFrozen(ctx, true)
+}
+
+func (server *Server) unfreezeAccount(ctx *gin.Context) {
+	server.setAccountFrozen(ctx, false)
+}
+
+func (server *Server) setAccountFrozen(ctx *gin.Context, isFrozen bool) {
+	var req freezeAccountRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	updatedAccount, err := server.store.UpdateAccountFrozen(ctx, db.UpdateAccountFrozenParams{
+		ID:       req.ID,
+		IsFrozen: isFrozen,
+	})
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updatedAccount)
+}
+
+// hasAccountAccess reports whether the authenticated user owns the account or holds one of the given staff roles.
+func hasAccountAccess(authPayload *token.Payload, account db.Account, staffRoles ...string) bool {
+	return account.Owner == authPayload.Username || slices.Contains(staffRoles, authPayload.Role)
+}
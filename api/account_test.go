@@ -61,6 +61,20 @@ func TestGetAccountAPI(t *testing.T) {
 				require.Exactly(t, account, unmarshallAccount(t, recorder.Body))
 			},
 		},
+		{
+			name:      "Banker OK",
+			accountId: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Exactly(t, account, unmarshallAccount(t, recorder.Body))
+			},
+		},
 		{
 			name:      "Unauthorized",
 			accountId: account.ID,
@@ -137,6 +151,7 @@ func TestGetAccountAPI(t *testing.T) {
 			// build stubs
 			store := mockdb.NewMockStore(ctrl)
 			testCase.buildStubs(store)
+			stubAuthChecks(store)
 
 			// start test server and send request
 			server := newTestServer(t, store)
@@ -198,6 +213,18 @@ func TestCreateAccountAPI(t *testing.T) {
 				require.Exactly(t, expectedAccount, unmarshallAccount(t, recorder.Body))
 			},
 		},
+		{
+			name: "Disabled Currency",
+			arg:  db.CreateAccountParams{Owner: user.Username, Currency: util.GBP},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) { store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0) },
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "currency GBP is not supported"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
 		{
 			name:       "No Authorization",
 			arg:        validArg,
@@ -208,6 +235,24 @@ func TestCreateAccountAPI(t *testing.T) {
 				require.Exactly(t, map[string]interface{}{"error": "authorization header was not provided"}, UnmarshallAny(t, recorder.Body))
 			},
 		},
+		{
+			name: "Email Not Verified",
+			arg:  validArg,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "email address has not been verified"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
 		{
 			name: "Bad Request",
 			arg:  db.CreateAccountParams{},
@@ -269,6 +314,8 @@ func TestCreateAccountAPI(t *testing.T) {
 			// build stubs
 			store := mockdb.NewMockStore(ctrl)
 			testCase.buildStubs(store)
+			stubAuthChecks(store)
+			stubVerifiedEmail(store)
 
 			// start test server and send request
 			server := newTestServer(t, store)
@@ -316,6 +363,7 @@ func TestListAccountsAPI(t *testing.T) {
 		name          string
 		page          int32
 		quantity      int32
+		owner         string
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
@@ -338,6 +386,40 @@ func TestListAccountsAPI(t *testing.T) {
 				fmt.Printf("Array format: %v", recorder.Body)
 			},
 		},
+		{
+			name:     "Banker OK With Another Owner",
+			page:     1,
+			quantity: 3,
+			owner:    user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountsByOwner(gomock.Any(), gomock.Eq(expectedSpecifiedArg)).
+					Times(1).
+					Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Depositor Forbidden With Another Owner",
+			page:     1,
+			quantity: 3,
+			owner:    user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "depositor", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByOwner(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "only bankers and admins can list accounts of other users"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
 		{
 			name:     "OK With Default Parameters Values",
 			page:     1,
@@ -410,6 +492,7 @@ func TestListAccountsAPI(t *testing.T) {
 			// build stubs
 			store := mockdb.NewMockStore(ctrl)
 			testCase.buildStubs(store)
+			stubAuthChecks(store)
 
 			// start test server and send request
 			server := newTestServer(t, store)
@@ -417,6 +500,10 @@ func TestListAccountsAPI(t *testing.T) {
 
 			url := fmt.Sprintf("/accounts?page=%d&quantity=%d", testCase.page, testCase.quantity)
 
+			if len(testCase.owner) > 0 {
+				url += fmt.Sprintf("&owner=%s", testCase.owner)
+			}
+
 			request, err := http.NewRequest("GET", url, nil)
 			require.NoError(t, err)
 
@@ -477,6 +564,45 @@ func TestUpdateAccountAPI(t *testing.T) {
 				require.Exactly(t, updatedAccount, unmarshallAccount(t, recorder.Body))
 			},
 		},
+		{
+			name: "Admin OK",
+			arg:  validArg,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(validArg.ID)).
+					Times(1).
+					Return(originalAccount, nil)
+				store.EXPECT().
+					UpdateAccount(gomock.Any(), gomock.Eq(validArg)).
+					Times(1).
+					Return(updatedAccount, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Exactly(t, updatedAccount, unmarshallAccount(t, recorder.Body))
+			},
+		},
+		{
+			name: "Banker Unauthorized",
+			arg:  validArg,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(validArg.ID)).
+					Times(1).
+					Return(originalAccount, nil)
+				store.EXPECT().UpdateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "account doesn't belong to the authenticated user"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
 		{
 			name: "Unauthorized",
 			arg:  validArg,
@@ -605,6 +731,7 @@ func TestUpdateAccountAPI(t *testing.T) {
 			// build stubs
 			store := mockdb.NewMockStore(ctrl)
 			testCase.buildStubs(store)
+			stubAuthChecks(store)
 
 			// start test server and send request
 			server := newTestServer(t, store)
@@ -758,6 +885,7 @@ func TestDeleteAccountAPI(t *testing.T) {
 			// build stubs
 			store := mockdb.NewMockStore(ctrl)
 			testCase.buildStubs(store)
+			stubAuthChecks(store)
 
 			// start test server and send request
 			server := newTestServer(t, store)
@@ -778,3 +906,138 @@ func TestDeleteAccountAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestFreezeAccountAPI(t *testing.T) {
+	user, _ := createRandomUser()
+
+	account := createRandomAccount(user.Username)
+
+	frozenAccount := account
+	frozenAccount.IsFrozen = true
+
+	testCases := []struct {
+		name          string
+		accountId     int64
+		action        string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Freeze OK",
+			accountId: account.ID,
+			action:    "freeze",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAccountFrozen(gomock.Any(), gomock.Eq(db.UpdateAccountFrozenParams{ID: account.ID, IsFrozen: true})).
+					Times(1).
+					Return(frozenAccount, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Exactly(t, frozenAccount, unmarshallAccount(t, recorder.Body))
+			},
+		},
+		{
+			name:      "Unfreeze OK",
+			accountId: account.ID,
+			action:    "unfreeze",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAccountFrozen(gomock.Any(), gomock.Eq(db.UpdateAccountFrozenParams{ID: account.ID, IsFrozen: false})).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Exactly(t, account, unmarshallAccount(t, recorder.Body))
+			},
+		},
+		{
+			name:      "Banker Forbidden",
+			accountId: account.ID,
+			action:    "freeze",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountFrozen(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "role banker is not allowed to access this resource"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:      "Not Found",
+			accountId: account.ID,
+			action:    "freeze",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAccountFrozen(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrNoRows.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:      "Internal Server Error",
+			accountId: account.ID,
+			action:    "freeze",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAccountFrozen(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// build stubs
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			// start test server and send request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/%s", testCase.accountId, testCase.action)
+
+			request, err := http.NewRequest("POST", url, nil)
+			require.NoError(t, err)
+
+			// setup authorization middleware
+			testCase.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			// check response
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
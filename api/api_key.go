@@ -0,0 +1,174 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/token"
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const apiKeyPrefixLength = 12
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=64"`
+	Scopes    []string   `json:"scopes" binding:"omitempty,dive,scope"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	KeyPrefix string     `json:"key_prefix"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type CreateAPIKeyResponse struct {
+	Key    string         `json:"key"`
+	APIKey APIKeyResponse `json:"api_key"`
+}
+
+func formatAPIKeyResponse(apiKey db.ApiKey) APIKeyResponse {
+	response := APIKeyResponse{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		KeyPrefix: apiKey.KeyPrefix,
+		Scopes:    apiKey.Scopes,
+		CreatedAt: apiKey.CreatedAt,
+	}
+
+	if apiKey.ExpiresAt.Valid {
+		response.ExpiresAt = &apiKey.ExpiresAt.Time
+	}
+
+	return response
+}
+
+func (server *Server) createAPIKey(ctx *gin.Context) {
+	var req CreateAPIKeyRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		err := errors.New("api key expiration must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	scopes := req.Scopes
+
+	if len(scopes) == 0 {
+		scopes = authPayload.Scopes
+	}
+
+	// a key can't be used to escalate the privileges of the token that created it
+	for _, scope := range scopes {
+		if !authPayload.HasScope(scope) {
+			err := fmt.Errorf("token is missing the %s scope", scope)
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+	}
+
+	key, err := util.GenerateAPIKey()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateAPIKeyParams{
+		ID:        uuid.New(),
+		Username:  authPayload.Username,
+		Name:      req.Name,
+		KeyPrefix: key[:apiKeyPrefixLength],
+		HashedKey: util.HashAPIKey(key),
+		Scopes:    scopes,
+	}
+
+	if req.ExpiresAt != nil {
+		arg.ExpiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	apiKey, err := server.store.CreateAPIKey(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, CreateAPIKeyResponse{
+		Key:    key,
+		APIKey: formatAPIKeyResponse(apiKey),
+	})
+}
+
+func (server *Server) listAPIKeys(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	apiKeys, err := server.store.ListAPIKeys(ctx, authPayload.Username)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]APIKeyResponse, len(apiKeys))
+
+	for i, apiKey := range apiKeys {
+		response[i] = formatAPIKeyResponse(apiKey)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type revokeAPIKeyRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+func (server *Server) revokeAPIKey(ctx *gin.Context) {
+	var req revokeAPIKeyRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	apiKey, err := server.store.GetAPIKey(ctx, uuid.MustParse(req.ID))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload); apiKey.Username != authPayload.Username {
+		err := errors.New("api key doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if err := server.store.DeleteAPIKey(ctx, apiKey.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
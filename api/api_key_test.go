@@ -0,0 +1,386 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/Andrew-2609/simple-bank/db/mock"
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/token"
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAPIKey(username string) db.ApiKey {
+	return db.ApiKey{
+		ID:        uuid.New(),
+		Username:  username,
+		Name:      util.RandomString(8),
+		KeyPrefix: "sbk_" + util.RandomString(8),
+		HashedKey: util.HashAPIKey(util.RandomString(32)),
+		Scopes:    []string{token.ScopeAccountsRead},
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestCreateAPIKeyAPI(t *testing.T) {
+	user, _ := createRandomUser()
+	expiresAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	testCases := []struct {
+		name          string
+		body          map[string]any
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Created",
+			body: map[string]any{"name": "reconciliation", "scopes": []string{token.ScopeAccountsRead}, "expires_at": expiresAt},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, "reconciliation", arg.Name)
+						require.Equal(t, []string{token.ScopeAccountsRead}, arg.Scopes)
+						require.Equal(t, sql.NullTime{Time: expiresAt, Valid: true}, arg.ExpiresAt)
+						require.Len(t, arg.HashedKey, 64)
+
+						return db.ApiKey{
+							ID:        arg.ID,
+							Username:  arg.Username,
+							Name:      arg.Name,
+							KeyPrefix: arg.KeyPrefix,
+							HashedKey: arg.HashedKey,
+							Scopes:    arg.Scopes,
+							ExpiresAt: arg.ExpiresAt,
+							CreatedAt: time.Now(),
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				response, err := util.UnmarshallJsonBody[CreateAPIKeyResponse](recorder.Body)
+				require.NoError(t, err)
+
+				require.Regexp(t, `^sbk_[0-9a-f]{64}$`, response.Key)
+				require.Equal(t, response.Key[:apiKeyPrefixLength], response.APIKey.KeyPrefix)
+				require.Equal(t, "reconciliation", response.APIKey.Name)
+				require.Equal(t, []string{token.ScopeAccountsRead}, response.APIKey.Scopes)
+				require.NotNil(t, response.APIKey.ExpiresAt)
+				require.True(t, expiresAt.Equal(*response.APIKey.ExpiresAt))
+			},
+		},
+		{
+			name: "Created Without Expiration Inherits Token Scopes",
+			body: map[string]any{"name": "reconciliation"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithScopes(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, []string{token.ScopeAccountsRead, token.ScopeUsersWrite}, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+						require.Equal(t, []string{token.ScopeAccountsRead, token.ScopeUsersWrite}, arg.Scopes)
+						require.False(t, arg.ExpiresAt.Valid)
+
+						return db.ApiKey{ID: arg.ID, Scopes: arg.Scopes}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				response, err := util.UnmarshallJsonBody[CreateAPIKeyResponse](recorder.Body)
+				require.NoError(t, err)
+
+				require.Nil(t, response.APIKey.ExpiresAt)
+			},
+		},
+		{
+			name: "Forbidden - Scope Escalation",
+			body: map[string]any{"name": "reconciliation", "scopes": []string{token.ScopeTransfersCreate}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithScopes(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, []string{token.ScopeAccountsRead, token.ScopeUsersWrite}, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "token is missing the transfers:create scope"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Bad Request - Expiration In The Past",
+			body: map[string]any{"name": "reconciliation", "expires_at": time.Now().Add(-time.Minute)},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "api key expiration must be in the future"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Bad Request - Unsupported Scope",
+			body: map[string]any{"name": "reconciliation", "scopes": []string{"accounts:delete"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Internal Server Error",
+			body: map[string]any{"name": "reconciliation"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApiKey{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api-keys", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			testCase.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAPIKeysAPI(t *testing.T) {
+	user, _ := createRandomUser()
+
+	apiKeys := []db.ApiKey{createRandomAPIKey(user.Username), createRandomAPIKey(user.Username)}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAPIKeys(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(apiKeys, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "hashed_key")
+
+				response, err := util.UnmarshallJsonBody[[]APIKeyResponse](recorder.Body)
+				require.NoError(t, err)
+
+				require.Len(t, response, len(apiKeys))
+
+				for i, apiKey := range apiKeys {
+					require.Equal(t, apiKey.ID, response[i].ID)
+					require.Equal(t, apiKey.KeyPrefix, response[i].KeyPrefix)
+				}
+			},
+		},
+		{
+			name: "Internal Server Error",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAPIKeys(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/api-keys", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRevokeAPIKeyAPI(t *testing.T) {
+	user, _ := createRandomUser()
+	apiKey := createRandomAPIKey(user.Username)
+
+	testCases := []struct {
+		name          string
+		id            string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "No Content",
+			id:       apiKey.ID.String(),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(apiKey, nil)
+				store.EXPECT().
+					DeleteAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:     "Unauthorized User",
+			id:       apiKey.ID.String(),
+			username: "unauthorized",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(apiKey, nil)
+				store.EXPECT().DeleteAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "api key doesn't belong to the authenticated user"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Not Found",
+			id:       apiKey.ID.String(),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(db.ApiKey{}, sql.ErrNoRows)
+				store.EXPECT().DeleteAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "Bad Request",
+			id:       "123",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "Internal Server Error",
+			id:       apiKey.ID.String(),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(apiKey, nil)
+				store.EXPECT().
+					DeleteAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/api-keys/%s", testCase.id), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, testCase.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) listCurrencies(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, server.currencies.List())
+}
+
+type enableCurrencyRequest struct {
+	Code string `uri:"code" binding:"required,len=3,uppercase"`
+}
+
+func (server *Server) enableCurrency(ctx *gin.Context) {
+	server.setCurrencyEnabled(ctx, true)
+}
+
+func (server *Server) disableCurrency(ctx *gin.Context) {
+	server.setCurrencyEnabled(ctx, false)
+}
+
+func (server *Server) setCurrencyEnabled(ctx *gin.Context, isEnabled bool) {
+	var req enableCurrencyRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	updatedCurrency, err := server.store.UpdateCurrencyEnabled(ctx, db.UpdateCurrencyEnabledParams{
+		Code:      req.Code,
+		IsEnabled: isEnabled,
+	})
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	currency := toRegistryCurrency(updatedCurrency)
+	server.currencies.Set(currency)
+
+	ctx.JSON(http.StatusOK, currency)
+}
+
+// checkCurrency returns an error unless the code is of a currency in the registry.
+// Disabled currencies pass, since accounts already opened in them can still transfer money.
+// Currencies are checked here rather than with a binding validator, which would be shared by every server
+// while each of them has its own registry
+func (server *Server) checkCurrency(code string) error {
+	if _, ok := server.currencies.Get(code); !ok {
+		return fmt.Errorf("currency %s is not supported", code)
+	}
+
+	return nil
+}
+
+// checkEnabledCurrency is like checkCurrency, but also rejects disabled currencies, which new accounts can't be opened in
+func (server *Server) checkEnabledCurrency(code string) error {
+	if !server.currencies.IsSupported(code) {
+		return fmt.Errorf("currency %s is not supported", code)
+	}
+
+	return nil
+}
+
+func toRegistryCurrency(currency db.Currency) util.Currency {
+	return util.Currency{
+		Code:        currency.Code,
+		NumericCode: currency.NumericCode,
+		Exponent:    currency.Exponent,
+		Symbol:      currency.Symbol,
+		IsEnabled:   currency.IsEnabled,
+	}
+}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	mockdb "github.com/Andrew-2609/simple-bank/db/mock"
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/token"
+	"github.com/Andrew-2609/simple-bank/util"
+)
+
+func TestListCurrenciesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	stubAuthChecks(store)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest("GET", "/currencies", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	currencies, err := util.UnmarshallJsonBody[[]util.Currency](recorder.Body)
+	require.NoError(t, err)
+	require.Equal(t, util.DefaultCurrencies, currencies)
+}
+
+func TestEnableCurrencyAPI(t *testing.T) {
+	jpy := db.Currency{
+		Code:        util.JPY,
+		NumericCode: 392,
+		Exponent:    0,
+		Symbol:      "¥",
+		IsEnabled:   true,
+		CreatedAt:   time.Now(),
+	}
+
+	testCases := []struct {
+		name          string
+		code          string
+		action        string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server)
+	}{
+		{
+			name:   "Enable OK",
+			code:   util.JPY,
+			action: "enable",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCurrencyEnabled(gomock.Any(), gomock.Eq(db.UpdateCurrencyEnabledParams{Code: util.JPY, IsEnabled: true})).
+					Times(1).
+					Return(jpy, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Exactly(t, map[string]interface{}{
+					"code":         util.JPY,
+					"numeric_code": float64(392),
+					"exponent":     float64(0),
+					"symbol":       "¥",
+					"is_enabled":   true,
+				}, UnmarshallAny(t, recorder.Body))
+				require.True(t, server.currencies.IsSupported(util.JPY))
+			},
+		},
+		{
+			name:   "Disable OK",
+			code:   util.USD,
+			action: "disable",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCurrencyEnabled(gomock.Any(), gomock.Eq(db.UpdateCurrencyEnabledParams{Code: util.USD, IsEnabled: false})).
+					Times(1).
+					Return(db.Currency{Code: util.USD, NumericCode: 840, Exponent: 2, Symbol: "$", IsEnabled: false}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.False(t, server.currencies.IsSupported(util.USD))
+			},
+		},
+		{
+			name:   "Bad Request",
+			code:   "usd",
+			action: "enable",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "Banker Forbidden",
+			code:   util.JPY,
+			action: "enable",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.False(t, server.currencies.IsSupported(util.JPY))
+			},
+		},
+		{
+			name:   "Not Found",
+			code:   "XYZ",
+			action: "enable",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Currency{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrNoRows.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:   "Internal Server Error",
+			code:   util.JPY,
+			action: "enable",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Currency{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.False(t, server.currencies.IsSupported(util.JPY))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// build stubs
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			// start test server and send request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/currencies/%s/%s", testCase.code, testCase.action)
+
+			request, err := http.NewRequest("POST", url, nil)
+			require.NoError(t, err)
+
+			// setup authorization middleware
+			testCase.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			// check response
+			testCase.checkResponse(t, recorder, server)
+		})
+	}
+}
+
+func TestLoadCurrencies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		Return([]db.Currency{
+			{Code: util.JPY, NumericCode: 392, Exponent: 0, Symbol: "¥", IsEnabled: true},
+			{Code: util.USD, NumericCode: 840, Exponent: 2, Symbol: "$", IsEnabled: false},
+		}, nil)
+
+	server := newTestServer(t, store)
+
+	require.NoError(t, server.LoadCurrencies(context.Background()))
+	require.True(t, server.currencies.IsSupported(util.JPY))
+	require.False(t, server.currencies.IsSupported(util.USD))
+
+	// currencies missing from the database are no longer supported
+	require.False(t, server.currencies.IsSupported(util.BRL))
+
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		Return(nil, sql.ErrConnDone)
+
+	require.ErrorIs(t, server.LoadCurrencies(context.Background()), sql.ErrConnDone)
+	require.True(t, server.currencies.IsSupported(util.JPY))
+}
+
+func TestReloadCurrencies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	// a currency disabled through another instance stops being supported by this one
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		MinTimes(1).
+		DoAndReturn(func(_ context.Context) ([]db.Currency, error) {
+			cancel()
+			return []db.Currency{{Code: util.USD, NumericCode: 840, Exponent: 2, Symbol: "$", IsEnabled: false}}, nil
+		})
+
+	server := newTestServer(t, store)
+	require.True(t, server.currencies.IsSupported(util.USD))
+
+	done := make(chan struct{})
+
+	go func() {
+		server.ReloadCurrencies(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("currencies were not reloaded")
+	}
+
+	require.False(t, server.currencies.IsSupported(util.USD))
+}
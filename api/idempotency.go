@@ -0,0 +1,89 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+const (
+	idempotencyKeyHeader         = "Idempotency-Key"
+	idempotentReplayedHeader     = "Idempotent-Replayed"
+	idempotencyKeyMaxLength      = 255
+	idempotencyKeyPkeyConstraint = "idempotency_keys_pkey"
+)
+
+var errIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+
+// getTransferIdempotencyKey reads the idempotency key of the transfer request, which is nil when the header isn't sent
+func getTransferIdempotencyKey(ctx *gin.Context, username string, req any) (*db.TransferIdempotencyKey, error) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+
+	if len(key) == 0 {
+		return nil, nil
+	}
+
+	if len(key) > idempotencyKeyMaxLength {
+		return nil, fmt.Errorf("%s header must have at most %d characters", idempotencyKeyHeader, idempotencyKeyMaxLength)
+	}
+
+	requestHash, err := util.HashRequest(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &db.TransferIdempotencyKey{
+		Username:    username,
+		Key:         key,
+		RequestHash: requestHash,
+	}, nil
+}
+
+// replayTransfer responds with the result stored for the idempotency key, if there is one.
+// It reports whether a response was sent, which includes errors and keys reused for different requests
+func (server *Server) replayTransfer(ctx *gin.Context, idempotencyKey *db.TransferIdempotencyKey) bool {
+	storedKey, err := server.store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username: idempotencyKey.Username,
+		Key:      idempotencyKey.Key,
+	})
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return true
+	}
+
+	if storedKey.RequestHash != idempotencyKey.RequestHash {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(errIdempotencyKeyReused))
+		return true
+	}
+
+	var result db.TransferTxResult
+
+	if err := json.Unmarshal(storedKey.Response, &result); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return true
+	}
+
+	ctx.Header(idempotentReplayedHeader, "true")
+	ctx.JSON(http.StatusCreated, result)
+	return true
+}
+
+// isIdempotencyKeyConflict reports whether the error comes from a concurrent request storing the same idempotency key
+func isIdempotencyKeyConflict(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+
+	return ok && pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == idempotencyKeyPkeyConstraint
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxLoginLockoutDuration = 24 * time.Hour
+	// loginLockoutMemory is how far back previous lockouts make the next one longer
+	loginLockoutMemory = 24 * time.Hour
+)
+
+// errInvalidCredentials is returned for both unknown users and wrong passwords, so usernames can't be enumerated.
+var errInvalidCredentials = errors.New("invalid username or password")
+
+// checkLoginThrottle aborts the login if the user is locked out or the client has failed too many logins recently.
+func (server *Server) checkLoginThrottle(ctx *gin.Context, username string) error {
+	lockout, err := server.store.GetActiveLoginLockout(ctx, username)
+
+	if err == nil {
+		ctx.Header("Retry-After", fmt.Sprint(int(time.Until(lockout.LockedUntil).Seconds())+1))
+		err := fmt.Errorf("too many failed login attempts, try again after %s", lockout.LockedUntil.Format(time.RFC3339))
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(err))
+		return err
+	}
+
+	if err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return err
+	}
+
+	failedAttempts, err := server.store.CountFailedLoginAttemptsByIP(ctx, db.CountFailedLoginAttemptsByIPParams{
+		ClientIp: ctx.ClientIP(),
+		Since:    time.Now().Add(-server.config.LoginAttemptWindow),
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return err
+	}
+
+	if failedAttempts >= server.config.LoginMaxFailedAttemptsPerIP {
+		ctx.Header("Retry-After", fmt.Sprint(int(server.config.LoginAttemptWindow.Seconds())))
+		err := errors.New("too many failed login attempts from this client")
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(err))
+		return err
+	}
+
+	return nil
+}
+
+// recordLoginSuccess records a successful login, which resets the failed attempts of the user.
+func (server *Server) recordLoginSuccess(ctx *gin.Context, username string) error {
+	err := server.store.CreateLoginAttempt(ctx, db.CreateLoginAttemptParams{
+		Username:  username,
+		ClientIp:  ctx.ClientIP(),
+		Succeeded: true,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	return err
+}
+
+// rejectLogin records a failed login and responds with errInvalidCredentials.
+func (server *Server) rejectLogin(ctx *gin.Context, username string) {
+	if err := server.recordLoginFailure(ctx, username); err != nil {
+		return
+	}
+
+	ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials))
+}
+
+// recordLoginFailure records a failed login, either a wrong password or a wrong mfa code.
+// Once the user reaches the maximum failed attempts they are locked out,
+// for a duration that doubles with every lockout they had recently.
+func (server *Server) recordLoginFailure(ctx *gin.Context, username string) error {
+	err := server.store.CreateLoginAttempt(ctx, db.CreateLoginAttemptParams{
+		Username:  username,
+		ClientIp:  ctx.ClientIP(),
+		Succeeded: false,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return err
+	}
+
+	failedAttempts, err := server.store.CountFailedLoginAttempts(ctx, db.CountFailedLoginAttemptsParams{
+		Username: username,
+		Since:    time.Now().Add(-server.config.LoginAttemptWindow),
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return err
+	}
+
+	if failedAttempts >= server.config.LoginMaxFailedAttempts {
+		previousLockouts, err := server.store.CountLoginLockouts(ctx, db.CountLoginLockoutsParams{
+			Username: username,
+			Since:    time.Now().Add(-loginLockoutMemory),
+		})
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return err
+		}
+
+		_, err = server.store.CreateLoginLockout(ctx, db.CreateLoginLockoutParams{
+			Username:       username,
+			ClientIp:       ctx.ClientIP(),
+			FailedAttempts: int32(failedAttempts),
+			LockedUntil:    time.Now().Add(loginLockoutDuration(server.config.LoginLockoutDuration, previousLockouts)),
+		})
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loginLockoutDuration doubles the base duration for every previous lockout, up to maxLoginLockoutDuration.
+func loginLockoutDuration(baseDuration time.Duration, previousLockouts int64) time.Duration {
+	duration := baseDuration
+
+	for i := int64(0); i < previousLockouts && duration < maxLoginLockoutDuration; i++ {
+		duration *= 2
+	}
+
+	return min(duration, maxLoginLockoutDuration)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/Andrew-2609/simple-bank/db/mock"
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubLoginThrottle lets every login pass the throttling checks, recording attempts without ever locking users out.
+func stubLoginThrottle(store *mockdb.MockStore) {
+	store.EXPECT().
+		GetActiveLoginLockout(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(db.LoginLockout{}, sql.ErrNoRows)
+	store.EXPECT().
+		CountFailedLoginAttemptsByIP(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(int64(0), nil)
+	store.EXPECT().
+		CreateLoginAttempt(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(nil)
+	store.EXPECT().
+		CountFailedLoginAttempts(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(int64(1), nil)
+}
+
+func TestLoginLockoutDuration(t *testing.T) {
+	require.Equal(t, time.Minute, loginLockoutDuration(time.Minute, 0))
+	require.Equal(t, 2*time.Minute, loginLockoutDuration(time.Minute, 1))
+	require.Equal(t, 8*time.Minute, loginLockoutDuration(time.Minute, 3))
+	require.Equal(t, maxLoginLockoutDuration, loginLockoutDuration(time.Minute, 20))
+	require.Equal(t, maxLoginLockoutDuration, loginLockoutDuration(time.Minute, 1000))
+}
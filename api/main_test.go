@@ -19,13 +19,36 @@ var (
 	testDB      *sql.DB
 )
 
-func newTestServer(t *testing.T, store db.Store) *Server {
-	config := util.Config{
-		TokenSymmetricKey:   util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+func newTestConfig() util.Config {
+	return util.Config{
+		AppBaseURL:           "http://localhost:8080",
+		TokenSymmetricKey:    util.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+		MFAChallengeDuration: time.Minute,
+
+		// cheap enough to keep the tests fast
+		PasswordArgon2Memory:      1024,
+		PasswordArgon2Time:        1,
+		PasswordArgon2Parallelism: 1,
+
+		LoginMaxFailedAttempts:      3,
+		LoginMaxFailedAttemptsPerIP: 10,
+		LoginAttemptWindow:          15 * time.Minute,
+		LoginLockoutDuration:        time.Minute,
+
+		EmailVerificationDuration: time.Hour,
+		PasswordResetDuration:     time.Hour,
+
+		TransferHoldDuration: time.Hour,
 	}
+}
 
-	server, err := NewServer(config, store)
+// testPasswordHasher hashes passwords like the test server does, so they are never rehashed on login
+var testPasswordHasher, _ = util.NewPasswordHasher(newTestConfig())
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	server, err := NewServer(newTestConfig(), store)
 	require.NoError(t, err)
 
 	return server
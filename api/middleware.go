@@ -1,23 +1,43 @@
 package api
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
 	"github.com/Andrew-2609/simple-bank/token"
+	"github.com/Andrew-2609/simple-bank/util"
 	"github.com/gin-gonic/gin"
 )
 
 const (
 	authorizationHeaderKey  = "Authorization"
 	authorizationTypeBearer = "bearer"
+	authorizationTypeAPIKey = "apikey"
 	authorizationPayloadKey = "authorization_payload"
+	apiKeyHeaderKey         = "X-API-Key"
+	// authorizationMethodKey holds how the request was authenticated, either authorizationTypeBearer or authorizationTypeAPIKey
+	authorizationMethodKey = "authorization_method"
 )
 
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// authMiddleware authenticates requests either with a bearer access token or with an API key,
+// sent in the X-API-Key header or as an ApiKey authorization.
+func authMiddleware(tokenMaker token.Maker, store db.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		if apiKey := ctx.GetHeader(apiKeyHeaderKey); len(apiKey) > 0 {
+			if payload, err := verifyAPIKey(ctx, store, apiKey); err == nil {
+				ctx.Set(authorizationPayloadKey, payload)
+				ctx.Set(authorizationMethodKey, authorizationTypeAPIKey)
+				ctx.Next()
+			}
+			return
+		}
+
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 
 		if len(authorizationHeader) == 0 {
@@ -34,22 +54,224 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 			return
 		}
 
-		if authorizationType := strings.ToLower(fields[0]); authorizationType != authorizationTypeBearer {
+		var payload *token.Payload
+		var err error
+
+		authorizationType := strings.ToLower(fields[0])
+
+		switch authorizationType {
+		case authorizationTypeBearer:
+			payload, err = verifyAccessToken(ctx, tokenMaker, store, fields[1])
+		case authorizationTypeAPIKey:
+			payload, err = verifyAPIKey(ctx, store, fields[1])
+		default:
 			err := fmt.Errorf("invalid authorization type: %s", authorizationType)
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
 		}
 
-		accessToken := fields[1]
+		if err != nil {
+			return
+		}
+
+		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Set(authorizationMethodKey, authorizationType)
+		ctx.Next()
+	}
+}
+
+// verifyAccessToken aborts the request if the access token is invalid, issued for another purpose,
+// revoked or issued before the last password change.
+func verifyAccessToken(ctx *gin.Context, tokenMaker token.Maker, store db.Store, accessToken string) (*token.Payload, error) {
+	payload, err := tokenMaker.VerifyToken(accessToken)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		return nil, err
+	}
 
-		payload, err := tokenMaker.VerifyToken(accessToken)
+	if payload.Purpose != "" {
+		err := fmt.Errorf("%s token can't be used as an access token", payload.Purpose)
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		return nil, err
+	}
 
-		if err != nil {
+	isRevoked, err := store.IsTokenRevoked(ctx, db.IsTokenRevokedParams{
+		ID:        payload.ID,
+		SessionID: payload.SessionID,
+	})
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, err
+	}
+
+	if isRevoked {
+		err := errors.New("token has been revoked")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		return nil, err
+	}
+
+	if err := checkPasswordChange(ctx, store, payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// verifyAPIKey aborts the request if the API key doesn't exist or has expired.
+// Otherwise it returns a payload carrying the key's scopes and the current role of its owner,
+// so handlers don't need to know how the request was authenticated.
+func verifyAPIKey(ctx *gin.Context, store db.Store, key string) (*token.Payload, error) {
+	apiKey, err := store.GetAPIKeyByHashedKey(ctx, util.HashAPIKey(key))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.New("invalid api key")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return nil, err
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, err
+	}
+
+	if apiKey.ExpiresAt.Valid && time.Now().After(apiKey.ExpiresAt.Time) {
+		err := errors.New("api key has expired")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		return nil, err
+	}
+
+	keyUser, err := store.GetUser(ctx, apiKey.Username)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.New("api key user doesn't exist")
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return nil, err
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, err
+	}
+
+	payload := &token.Payload{
+		ID:        apiKey.ID,
+		Username:  apiKey.Username,
+		Role:      keyUser.Role,
+		Scopes:    apiKey.Scopes,
+		IssuedAt:  apiKey.CreatedAt,
+		ExpiredAt: apiKey.ExpiresAt.Time,
+	}
+
+	return payload, nil
+}
+
+// roleMiddleware only lets through requests whose token carries one of the allowed roles.
+// It must be used after authMiddleware.
+func roleMiddleware(allowedRoles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		if !slices.Contains(allowedRoles, authPayload.Role) {
+			err := fmt.Errorf("role %s is not allowed to access this resource", authPayload.Role)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
 			return
 		}
 
-		ctx.Set(authorizationPayloadKey, payload)
 		ctx.Next()
 	}
 }
+
+// accessTokenMiddleware only lets through requests authenticated with a bearer access token.
+// It guards the routes that act on the login session, which API keys aren't tied to,
+// so logging out with a key can't look successful while the key keeps working.
+// It must be used after authMiddleware.
+func accessTokenMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetString(authorizationMethodKey) != authorizationTypeBearer {
+			err := errors.New("api keys can't be used to access this resource, revoke the key instead")
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		if authPayload.Purpose != "" {
+			err := fmt.Errorf("%s token can't be used as an access token", authPayload.Purpose)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// scopeMiddleware only lets through requests whose token was granted the required scope.
+// It must be used after authMiddleware.
+func scopeMiddleware(requiredScope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		if !authPayload.HasScope(requiredScope) {
+			err := fmt.Errorf("token is missing the %s scope", requiredScope)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// verifiedEmailMiddleware only lets through users who have verified their email address.
+// It must be used after authMiddleware.
+func verifiedEmailMiddleware(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		user, err := store.GetUser(ctx, authPayload.Username)
+
+		if err != nil {
+			if err == sql.ErrNoRows {
+				err = errors.New("token user doesn't exist")
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if !user.IsEmailVerified {
+			err := errors.New("email address has not been verified")
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// checkPasswordChange aborts the request if the token was issued before the last password change of its user.
+func checkPasswordChange(ctx *gin.Context, store db.Store, payload *token.Payload) error {
+	passwordChangedAt, err := store.GetUserPasswordChangedAt(ctx, payload.Username)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.New("token user doesn't exist")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return err
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return err
+	}
+
+	if payload.IssuedAt.Before(passwordChangedAt) {
+		err := errors.New("token was issued before the last password change")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		return err
+	}
+
+	return nil
+}
@@ -1,20 +1,37 @@
 package api
 
 import (
+	"database/sql"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	mockdb "github.com/Andrew-2609/simple-bank/db/mock"
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
 	"github.com/Andrew-2609/simple-bank/token"
 	"github.com/Andrew-2609/simple-bank/util"
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
 func addAuthorization(t *testing.T, request *http.Request, tokenMaker token.Maker, authorizationType string, username string, duration time.Duration) {
-	token, err := tokenMaker.CreateToken(username, duration)
+	addAuthorizationWithRole(t, request, tokenMaker, authorizationType, username, util.DepositorRole, duration)
+}
+
+func addAuthorizationWithRole(t *testing.T, request *http.Request, tokenMaker token.Maker, authorizationType string, username string, role string, duration time.Duration) {
+	addAuthorizationWithScopes(t, request, tokenMaker, authorizationType, username, role, token.AllScopes, duration)
+}
+
+func addAuthorizationWithScopes(t *testing.T, request *http.Request, tokenMaker token.Maker, authorizationType string, username string, role string, scopes []string, duration time.Duration) {
+	payload, err := token.NewPayload(username, role, duration)
+	require.NoError(t, err)
+
+	payload.Scopes = scopes
+
+	token, err := tokenMaker.CreateToken(payload)
 	require.NoError(t, err)
 
 	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, token)
@@ -22,22 +39,247 @@ func addAuthorization(t *testing.T, request *http.Request, tokenMaker token.Make
 	request.Header.Set(authorizationHeaderKey, authorizationHeader)
 }
 
+const testAPIKey = "sbk_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// stubAuthChecks lets every token pass the checks authMiddleware runs against the store.
+func stubAuthChecks(store *mockdb.MockStore) {
+	store.EXPECT().
+		IsTokenRevoked(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(false, nil)
+	store.EXPECT().
+		GetUserPasswordChangedAt(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(time.Time{}, nil)
+}
+
+// stubVerifiedEmail lets every user pass the check verifiedEmailMiddleware runs against the store.
+func stubVerifiedEmail(store *mockdb.MockStore) {
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(db.User{IsEmailVerified: true}, nil)
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	testCases := []struct {
 		name          string
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder httptest.ResponseRecorder)
 	}{{
 		name: "OK",
 		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", time.Minute)
 		},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				IsTokenRevoked(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(false, nil)
+			store.EXPECT().
+				GetUserPasswordChangedAt(gomock.Any(), gomock.Eq("user")).
+				Times(1).
+				Return(time.Now().Add(-time.Hour), nil)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusOK, recorder.Code)
+		},
+	}, {
+		name: "Token Issued Before Password Change",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", time.Minute)
+		},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				IsTokenRevoked(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(false, nil)
+			store.EXPECT().
+				GetUserPasswordChangedAt(gomock.Any(), gomock.Eq("user")).
+				Times(1).
+				Return(time.Now().Add(time.Second), nil)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "token was issued before the last password change"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Token User Not Found",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", time.Minute)
+		},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				IsTokenRevoked(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(false, nil)
+			store.EXPECT().
+				GetUserPasswordChangedAt(gomock.Any(), gomock.Eq("user")).
+				Times(1).
+				Return(time.Time{}, sql.ErrNoRows)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "token user doesn't exist"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Revoked Token",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", time.Minute)
+		},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				IsTokenRevoked(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(true, nil)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "token has been revoked"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Revocation Check Internal Server Error",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", time.Minute)
+		},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				IsTokenRevoked(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(false, sql.ErrConnDone)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "API Key Header OK",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			request.Header.Set(apiKeyHeaderKey, testAPIKey)
+		},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetAPIKeyByHashedKey(gomock.Any(), gomock.Eq(util.HashAPIKey(testAPIKey))).
+				Times(1).
+				Return(db.ApiKey{Username: "user", Scopes: token.AllScopes}, nil)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq("user")).
+				Times(1).
+				Return(db.User{Username: "user", Role: util.DepositorRole}, nil)
+			store.EXPECT().
+				IsTokenRevoked(gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusOK, recorder.Code)
+		},
+	}, {
+		name: "API Key Authorization Type OK",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("ApiKey %s", testAPIKey))
+		},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetAPIKeyByHashedKey(gomock.Any(), gomock.Eq(util.HashAPIKey(testAPIKey))).
+				Times(1).
+				Return(db.ApiKey{Username: "user", Scopes: token.AllScopes}, nil)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq("user")).
+				Times(1).
+				Return(db.User{Username: "user", Role: util.DepositorRole}, nil)
+		},
 		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
 			require.Equal(t, http.StatusOK, recorder.Code)
 		},
 	}, {
-		name:      "No Authorization Provided",
-		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+		name: "Invalid API Key",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			request.Header.Set(apiKeyHeaderKey, testAPIKey)
+		},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetAPIKeyByHashedKey(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.ApiKey{}, sql.ErrNoRows)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "invalid api key"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Expired API Key",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			request.Header.Set(apiKeyHeaderKey, testAPIKey)
+		},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetAPIKeyByHashedKey(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.ApiKey{
+					Username:  "user",
+					Scopes:    token.AllScopes,
+					ExpiresAt: sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+				}, nil)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "api key has expired"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "API Key Internal Server Error",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			request.Header.Set(apiKeyHeaderKey, testAPIKey)
+		},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetAPIKeyByHashedKey(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.ApiKey{}, sql.ErrConnDone)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusInternalServerError, recorder.Code)
+		},
+	}, {
+		name: "MFA Challenge Token",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			mfaToken, _ := createMFAToken(t, tokenMaker, "user", token.PurposeMFAChallenge)
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, mfaToken))
+		},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				IsTokenRevoked(gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "mfa_challenge token can't be used as an access token"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Refresh Token",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			refreshToken, _ := createRefreshToken(t, tokenMaker, "user", time.Minute)
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken))
+		},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				IsTokenRevoked(gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "refresh token can't be used as an access token"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name:       "No Authorization Provided",
+		setupAuth:  func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+		buildStubs: func(store *mockdb.MockStore) {},
 		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
 			require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			require.Exactly(t, map[string]interface{}{"error": "authorization header was not provided"}, UnmarshallAny(t, recorder.Body))
@@ -47,6 +289,7 @@ func TestAuthMiddleware(t *testing.T) {
 		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			addAuthorization(t, request, tokenMaker, "", "user", time.Minute)
 		},
+		buildStubs: func(store *mockdb.MockStore) {},
 		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
 			require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			require.Exactly(t, map[string]interface{}{"error": "invalid authorization header format"}, UnmarshallAny(t, recorder.Body))
@@ -56,6 +299,7 @@ func TestAuthMiddleware(t *testing.T) {
 		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			addAuthorization(t, request, tokenMaker, "oauth", "user", time.Minute)
 		},
+		buildStubs: func(store *mockdb.MockStore) {},
 		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
 			require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			require.Exactly(t, map[string]interface{}{"error": "invalid authorization type: oauth"}, UnmarshallAny(t, recorder.Body))
@@ -67,6 +311,7 @@ func TestAuthMiddleware(t *testing.T) {
 			require.NoError(t, err)
 			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", time.Minute)
 		},
+		buildStubs: func(store *mockdb.MockStore) {},
 		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
 			require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			require.Exactly(t, map[string]interface{}{"error": "invalid token"}, UnmarshallAny(t, recorder.Body))
@@ -76,6 +321,7 @@ func TestAuthMiddleware(t *testing.T) {
 		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", -time.Minute)
 		},
+		buildStubs: func(store *mockdb.MockStore) {},
 		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
 			require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			require.Exactly(t, map[string]interface{}{"error": "token has expired"}, UnmarshallAny(t, recorder.Body))
@@ -84,13 +330,19 @@ func TestAuthMiddleware(t *testing.T) {
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			server := newTestServer(t, nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
 
 			authPath := "/auth"
 
 			server.router.GET(
 				authPath,
-				authMiddleware(server.tokenMaker),
+				authMiddleware(server.tokenMaker, server.store),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				},
@@ -109,3 +361,220 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestAccessTokenMiddleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		method        string
+		purpose       string
+		checkResponse func(t *testing.T, recorder httptest.ResponseRecorder)
+	}{{
+		name:   "OK",
+		method: authorizationTypeBearer,
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusOK, recorder.Code)
+		},
+	}, {
+		name:   "API Key",
+		method: authorizationTypeAPIKey,
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusForbidden, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "api keys can't be used to access this resource, revoke the key instead"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name:    "Refresh Token",
+		method:  authorizationTypeBearer,
+		purpose: token.PurposeRefresh,
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "refresh token can't be used as an access token"}, UnmarshallAny(t, recorder.Body))
+		},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+			accessTokenPath := "/access_token"
+
+			server.router.GET(
+				accessTokenPath,
+				func(ctx *gin.Context) {
+					payload, err := token.NewPayload("user", util.DepositorRole, time.Minute)
+					require.NoError(t, err)
+
+					payload.Purpose = testCase.purpose
+
+					ctx.Set(authorizationPayloadKey, payload)
+					ctx.Set(authorizationMethodKey, testCase.method)
+				},
+				accessTokenMiddleware(),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest("GET", accessTokenPath, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, *recorder)
+		})
+	}
+}
+
+func TestScopeMiddleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		scopes        []string
+		checkResponse func(t *testing.T, recorder httptest.ResponseRecorder)
+	}{{
+		name:   "OK",
+		scopes: []string{token.ScopeAccountsRead, token.ScopeTransfersCreate},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusOK, recorder.Code)
+		},
+	}, {
+		name:   "Missing Scope",
+		scopes: []string{token.ScopeAccountsRead},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusForbidden, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "token is missing the transfers:create scope"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name:   "No Scopes",
+		scopes: nil,
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusForbidden, recorder.Code)
+		},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubAuthChecks(store)
+
+			server := newTestServer(t, store)
+
+			scopePath := "/scope"
+
+			server.router.GET(
+				scopePath,
+				authMiddleware(server.tokenMaker, server.store),
+				scopeMiddleware(token.ScopeTransfersCreate),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest("GET", scopePath, nil)
+			require.NoError(t, err)
+
+			addAuthorizationWithScopes(t, request, server.tokenMaker, authorizationTypeBearer, "user", util.DepositorRole, testCase.scopes, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, *recorder)
+		})
+	}
+}
+
+func TestVerifiedEmailMiddleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder httptest.ResponseRecorder)
+	}{{
+		name: "OK",
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq("user")).
+				Times(1).
+				Return(db.User{Username: "user", IsEmailVerified: true}, nil)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusOK, recorder.Code)
+		},
+	}, {
+		name: "Email Not Verified",
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq("user")).
+				Times(1).
+				Return(db.User{Username: "user"}, nil)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusForbidden, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "email address has not been verified"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "User Not Found",
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq("user")).
+				Times(1).
+				Return(db.User{}, sql.ErrNoRows)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "token user doesn't exist"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Internal Server Error",
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq("user")).
+				Times(1).
+				Return(db.User{}, sql.ErrConnDone)
+		},
+		checkResponse: func(t *testing.T, recorder httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusInternalServerError, recorder.Code)
+		},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			server := newTestServer(t, store)
+
+			verifiedPath := "/verified"
+
+			server.router.GET(
+				verifiedPath,
+				authMiddleware(server.tokenMaker, server.store),
+				verifiedEmailMiddleware(server.store),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest("GET", verifiedPath, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, *recorder)
+		})
+	}
+}
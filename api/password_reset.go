@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidPasswordResetCode = errors.New("invalid or expired password reset code")
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// forgotPassword emails a password reset code to the user with the given email.
+// It responds the same whether the user exists or not, so emails can't be enumerated:
+// the code is created and sent in the background, so finding the user doesn't make the response slower,
+// and failures after the user is found are only logged.
+func (server *Server) forgotPassword(ctx *gin.Context) {
+	var req ForgotPasswordRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	foundUser, err := server.store.GetUserByEmail(ctx, req.Email)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusAccepted, nil)
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.background.Add(1)
+
+	go func() {
+		defer server.background.Done()
+
+		if err := server.sendPasswordReset(context.Background(), foundUser); err != nil {
+			log.Printf("could not send password reset to %s: %v", foundUser.Username, err)
+		}
+	}()
+
+	ctx.JSON(http.StatusAccepted, nil)
+}
+
+// sendPasswordReset creates a single use password reset code and emails it to the user
+func (server *Server) sendPasswordReset(ctx context.Context, user db.User) error {
+	code, err := util.GenerateSecretCode()
+
+	if err != nil {
+		return err
+	}
+
+	passwordReset, err := server.store.CreatePasswordReset(ctx, db.CreatePasswordResetParams{
+		Username:   user.Username,
+		HashedCode: util.HashSecretCode(code),
+		ExpiredAt:  time.Now().Add(server.config.PasswordResetDuration),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	subject := "Reset your Simple Bank password"
+	content := fmt.Sprintf(`Hello %s,<br/>
+We received a request to reset your password. Your password reset code is:<br/>
+<b>%s</b><br/>
+The code expires at %s. If you didn't request it, you can ignore this email.<br/>`, html.EscapeString(user.Name), code, passwordReset.ExpiredAt.Format("2006-01-02 15:04 MST"))
+
+	return server.mailer.SendEmail(subject, content, []string{user.Email})
+}
+
+type ResetPasswordRequest struct {
+	Code        string `json:"code" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req ResetPasswordRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hashedCode := util.HashSecretCode(req.Code)
+
+	resetUser, err := server.store.GetPasswordResetUser(ctx, hashedCode)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidPasswordResetCode))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := server.checkPasswordPolicy(ctx, req.NewPassword, resetUser.Username, resetUser.Email); err != nil {
+		return
+	}
+
+	hashedPassword, err := server.passwordHasher.Hash(req.NewPassword)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	updatedUser, err := server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		HashedCode:        hashedCode,
+		HashedPassword:    hashedPassword,
+		PasswordChangedAt: time.Now(),
+	})
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidPasswordResetCode))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, formatUserResponse(updatedUser))
+}
@@ -0,0 +1,404 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	mockdb "github.com/Andrew-2609/simple-bank/db/mock"
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	mockmail "github.com/Andrew-2609/simple-bank/mail/mock"
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForgotPasswordAPI(t *testing.T) {
+	user, _ := createRandomUser()
+
+	body := ForgotPasswordRequest{Email: user.Email}
+
+	var hashedCode string
+
+	testCases := []struct {
+		name          string
+		body          ForgotPasswordRequest
+		buildStubs    func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{{
+		name: "Accepted",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+			store.EXPECT().
+				GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				CreatePasswordReset(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CreatePasswordResetParams) (db.PasswordReset, error) {
+					require.Equal(t, user.Username, arg.Username)
+					require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiredAt, time.Second)
+					hashedCode = arg.HashedCode
+					return db.PasswordReset{Username: arg.Username, HashedCode: arg.HashedCode, ExpiredAt: arg.ExpiredAt}, nil
+				})
+			mailer.EXPECT().
+				SendEmail(gomock.Any(), gomock.Any(), gomock.Eq([]string{user.Email})).
+				Times(1).
+				DoAndReturn(func(_ string, content string, _ []string) error {
+					code := regexp.MustCompile(`<b>([0-9a-f]{64})</b>`).FindStringSubmatch(content)
+					require.Len(t, code, 2)
+					require.Equal(t, hashedCode, util.HashSecretCode(code[1]))
+					return nil
+				})
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusAccepted, recorder.Code)
+		},
+	}, {
+		name: "User Not Found",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+			store.EXPECT().
+				GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+				Times(1).
+				Return(db.User{}, sql.ErrNoRows)
+			store.EXPECT().CreatePasswordReset(gomock.Any(), gomock.Any()).Times(0)
+			mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusAccepted, recorder.Code)
+		},
+	}, {
+		name: "Send Email Error",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+			store.EXPECT().
+				GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				CreatePasswordReset(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.PasswordReset{}, nil)
+			mailer.EXPECT().
+				SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(errors.New("could not send email"))
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			// the failure must not reveal that the user exists
+			require.Equal(t, http.StatusAccepted, recorder.Code)
+		},
+	}, {
+		name: "Bad Request",
+		body: ForgotPasswordRequest{Email: "not an email"},
+		buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+			store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+		},
+	}, {
+		name: "Internal Server Error",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+			store.EXPECT().
+				GetUserByEmail(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.User{}, sql.ErrConnDone)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+		},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			mailer := mockmail.NewMockEmailSender(ctrl)
+			testCase.buildStubs(store, mailer)
+
+			server := newTestServer(t, store)
+			server.mailer = mailer
+			recorder := httptest.NewRecorder()
+
+			var buf bytes.Buffer
+
+			err := json.NewEncoder(&buf).Encode(testCase.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest("POST", "/users/password/forgot", &buf)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			server.background.Wait()
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestForgotPasswordRespondsBeforeSending(t *testing.T) {
+	user, _ := createRandomUser()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// sending is held back until the response was checked, so it must not be waited for
+	release := make(chan struct{})
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		CreatePasswordReset(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.PasswordReset{Username: user.Username}, nil)
+
+	mailer := mockmail.NewMockEmailSender(ctrl)
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Eq([]string{user.Email})).
+		Times(1).
+		DoAndReturn(func(_ string, _ string, _ []string) error {
+			<-release
+			return nil
+		})
+
+	server := newTestServer(t, store)
+	server.mailer = mailer
+	recorder := httptest.NewRecorder()
+
+	var buf bytes.Buffer
+
+	err := json.NewEncoder(&buf).Encode(ForgotPasswordRequest{Email: user.Email})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest("POST", "/users/password/forgot", &buf)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	close(release)
+	server.background.Wait()
+}
+
+type eqResetPasswordTxParamsMatcher struct {
+	hashedCode  string
+	rawPassword string
+}
+
+func (eq eqResetPasswordTxParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.ResetPasswordTxParams)
+
+	if !ok {
+		return false
+	}
+
+	if err := util.CheckPassword(arg.HashedPassword, eq.rawPassword); err != nil {
+		return false
+	}
+
+	return arg.HashedCode == eq.hashedCode && time.Since(arg.PasswordChangedAt) < time.Minute
+}
+
+func (eq eqResetPasswordTxParamsMatcher) String() string {
+	return "reset password of code " + eq.hashedCode + " to " + eq.rawPassword
+}
+
+func EqResetPasswordTxParams(hashedCode string, rawPassword string) gomock.Matcher {
+	return eqResetPasswordTxParamsMatcher{hashedCode, rawPassword}
+}
+
+func TestResetPasswordAPI(t *testing.T) {
+	user, _ := createRandomUser()
+
+	code := util.RandomString(64)
+	newPassword := util.RandomString(8)
+
+	body := ResetPasswordRequest{Code: code, NewPassword: newPassword}
+
+	testCases := []struct {
+		name          string
+		body          ResetPasswordRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{{
+		name: "OK",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetPasswordResetUser(gomock.Any(), gomock.Eq(util.HashSecretCode(code))).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				ResetPasswordTx(gomock.Any(), EqResetPasswordTxParams(util.HashSecretCode(code), newPassword)).
+				Times(1).
+				Return(user, nil)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.Equal(t, user.Username, unmarshallUserResponse(t, recorder.Body).Username)
+		},
+	}, {
+		name: "Bad Request",
+		body: ResetPasswordRequest{Code: code},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().GetPasswordResetUser(gomock.Any(), gomock.Any()).Times(0)
+			store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+		},
+	}, {
+		name: "Password Policy Violation",
+		body: ResetPasswordRequest{Code: code, NewPassword: "short"},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetPasswordResetUser(gomock.Any(), gomock.Eq(util.HashSecretCode(code))).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			require.Exactly(t, map[string]interface{}{
+				"error":      "password must have at least 8 characters",
+				"violations": []interface{}{map[string]interface{}{"rule": util.PasswordMinLengthRule, "message": "must have at least 8 characters"}},
+			}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Invalid Or Expired Code",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetPasswordResetUser(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.User{}, sql.ErrNoRows)
+			store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "invalid or expired password reset code"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Code Used Concurrently",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetPasswordResetUser(gomock.Any(), gomock.Eq(util.HashSecretCode(code))).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				ResetPasswordTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.User{}, sql.ErrNoRows)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "invalid or expired password reset code"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Lookup Internal Server Error",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetPasswordResetUser(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.User{}, sql.ErrConnDone)
+			store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Internal Server Error",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetPasswordResetUser(gomock.Any(), gomock.Eq(util.HashSecretCode(code))).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				ResetPasswordTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.User{}, sql.ErrConnDone)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+		},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var buf bytes.Buffer
+
+			err := json.NewEncoder(&buf).Encode(testCase.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest("POST", "/users/password/reset", &buf)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSendPasswordResetEscapesName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := createRandomUser()
+	user.Name = `<a href="https://phishing.example">Claim your bonus</a>`
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreatePasswordReset(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.PasswordReset{Username: user.Username, ExpiredAt: time.Now().Add(time.Hour)}, nil)
+
+	mailer := mockmail.NewMockEmailSender(ctrl)
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Eq([]string{user.Email})).
+		Times(1).
+		DoAndReturn(func(_ string, content string, _ []string) error {
+			require.NotContains(t, content, user.Name)
+			require.Contains(t, content, "Hello &lt;a href=&#34;https://phishing.example&#34;&gt;Claim your bonus&lt;/a&gt;,")
+			return nil
+		})
+
+	server := newTestServer(t, store)
+	server.mailer = mailer
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	require.NoError(t, server.sendPasswordReset(ctx, user))
+}
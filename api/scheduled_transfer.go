@@ -0,0 +1,436 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/token"
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateScheduledTransferRequest struct {
+	FromAccountID int64      `json:"fromAccountId" binding:"required,min=1"`
+	ToAccountID   int64      `json:"toAccountId" binding:"required,min=1"`
+	Amount        int64      `json:"amount" binding:"required,gt=0"`
+	Currency      string     `json:"currency" binding:"required"`
+	Frequency     string     `json:"frequency" binding:"omitempty,frequency"`
+	Interval      int32      `json:"interval" binding:"omitempty,min=1,max=366"`
+	StartAt       time.Time  `json:"startAt" binding:"required"`
+	EndAt         *time.Time `json:"endAt"`
+}
+
+type ScheduledTransferResponse struct {
+	ID            int64      `json:"id"`
+	Owner         string     `json:"owner"`
+	FromAccountID int64      `json:"from_account_id"`
+	ToAccountID   int64      `json:"to_account_id"`
+	Amount        int64      `json:"amount"`
+	Frequency     string     `json:"frequency"`
+	Interval      int32      `json:"interval"`
+	StartAt       time.Time  `json:"start_at"`
+	EndAt         *time.Time `json:"end_at"`
+	NextRunAt     *time.Time `json:"next_run_at"`
+	CancelledAt   *time.Time `json:"cancelled_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type ScheduledTransferExecutionResponse struct {
+	ID           int64     `json:"id"`
+	TransferID   *int64    `json:"transfer_id"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+	Error        *string   `json:"error"`
+	ExecutedAt   time.Time `json:"executed_at"`
+}
+
+func formatScheduledTransferResponse(scheduledTransfer db.ScheduledTransfer) ScheduledTransferResponse {
+	return ScheduledTransferResponse{
+		ID:            scheduledTransfer.ID,
+		Owner:         scheduledTransfer.Owner,
+		FromAccountID: scheduledTransfer.FromAccountID,
+		ToAccountID:   scheduledTransfer.ToAccountID,
+		Amount:        scheduledTransfer.Amount,
+		Frequency:     scheduledTransfer.Frequency,
+		Interval:      scheduledTransfer.FrequencyInterval,
+		StartAt:       scheduledTransfer.StartAt,
+		EndAt:         nullTimePointer(scheduledTransfer.EndAt),
+		NextRunAt:     nullTimePointer(scheduledTransfer.NextRunAt),
+		CancelledAt:   nullTimePointer(scheduledTransfer.CancelledAt),
+		CreatedAt:     scheduledTransfer.CreatedAt,
+	}
+}
+
+func formatScheduledTransferExecutionResponse(execution db.ScheduledTransferExecution) ScheduledTransferExecutionResponse {
+	response := ScheduledTransferExecutionResponse{
+		ID:           execution.ID,
+		ScheduledFor: execution.ScheduledFor,
+		ExecutedAt:   execution.ExecutedAt,
+	}
+
+	if execution.TransferID.Valid {
+		response.TransferID = &execution.TransferID.Int64
+	}
+
+	if execution.Error.Valid {
+		response.Error = &execution.Error.String
+	}
+
+	return response
+}
+
+func nullTimePointer(nullTime sql.NullTime) *time.Time {
+	if !nullTime.Valid {
+		return nil
+	}
+
+	return &nullTime.Time
+}
+
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req CreateScheduledTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := server.checkCurrency(req.Currency); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.StartAt.After(time.Now()) {
+		err := errors.New("scheduled transfer start must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.EndAt != nil && req.EndAt.Before(req.StartAt) {
+		err := errors.New("scheduled transfer end can't be before its start")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Frequency == "" {
+		req.Frequency = util.FrequencyOnce
+	}
+
+	if req.Interval == 0 {
+		req.Interval = 1
+	}
+
+	fromAccount, isValid := server.validateAccountTransfer(ctx, req.FromAccountID)
+
+	if !isValid {
+		return
+	}
+
+	if fromAccount.Currency != req.Currency {
+		err := fmt.Errorf("Account %d currency mismatch: %s should be %s", req.FromAccountID, req.Currency, fromAccount.Currency)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	toAccount, isValid := server.validateAccountTransfer(ctx, req.ToAccountID)
+
+	if !isValid {
+		return
+	}
+
+	// the exchange rate isn't known until the transfer runs, so there's no way to tell how much would be credited
+	if toAccount.Currency != fromAccount.Currency {
+		err := fmt.Errorf("Account %d currency mismatch: scheduled transfers between %s and %s aren't supported", req.ToAccountID, fromAccount.Currency, toAccount.Currency)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateScheduledTransferParams{
+		Owner:             authPayload.Username,
+		FromAccountID:     req.FromAccountID,
+		ToAccountID:       req.ToAccountID,
+		Amount:            req.Amount,
+		Frequency:         req.Frequency,
+		FrequencyInterval: req.Interval,
+		StartAt:           req.StartAt,
+		NextRunAt:         sql.NullTime{Time: req.StartAt, Valid: true},
+	}
+
+	if req.EndAt != nil {
+		arg.EndAt = sql.NullTime{Time: *req.EndAt, Valid: true}
+	}
+
+	scheduledTransfer, err := server.store.CreateScheduledTransfer(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, formatScheduledTransferResponse(scheduledTransfer))
+}
+
+type listScheduledTransfersRequest struct {
+	Page     int32 `form:"page" binding:"required,min=1"`
+	Quantity int32 `form:"quantity" binding:"max=200"`
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+
+	if err := ctx.BindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Quantity == 0 {
+		req.Quantity = 40
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	scheduledTransfers, err := server.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.Quantity,
+		Offset: (req.Page - 1) * req.Quantity,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]ScheduledTransferResponse, len(scheduledTransfers))
+
+	for i, scheduledTransfer := range scheduledTransfers {
+		response[i] = formatScheduledTransferResponse(scheduledTransfer)
+	}
+
+	ctx.Header("total", fmt.Sprint(len(response)))
+	ctx.JSON(http.StatusOK, response)
+}
+
+type getScheduledTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var req getScheduledTransferRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, isValid := server.validateScheduledTransferAccess(ctx, req.ID, util.BankerRole, util.AdminRole)
+
+	if !isValid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, formatScheduledTransferResponse(scheduledTransfer))
+}
+
+type updateScheduledTransferRequest struct {
+	params struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+	body struct {
+		Amount *int64     `json:"amount" binding:"omitempty,gt=0"`
+		EndAt  *time.Time `json:"endAt"`
+	}
+}
+
+func (server *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var req updateScheduledTransferRequest
+
+	if err := ctx.ShouldBindUri(&req.params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req.body); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.body.Amount == nil && req.body.EndAt == nil {
+		err := errors.New("at least one of amount and endAt must be provided")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, isValid := server.validateScheduledTransferAccess(ctx, req.params.ID)
+
+	if !isValid {
+		return
+	}
+
+	if scheduledTransfer.CancelledAt.Valid {
+		err := fmt.Errorf("Scheduled transfer %d is cancelled", scheduledTransfer.ID)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	// only the given fields are sent, so a run the executor makes meanwhile isn't undone, and the query itself
+	// drops the next run if the schedule now ends before it
+	arg := db.UpdateScheduledTransferParams{
+		ID: scheduledTransfer.ID,
+	}
+
+	if req.body.Amount != nil {
+		arg.Amount = sql.NullInt64{Int64: *req.body.Amount, Valid: true}
+	}
+
+	if req.body.EndAt != nil {
+		if req.body.EndAt.Before(scheduledTransfer.StartAt) {
+			err := errors.New("scheduled transfer end can't be before its start")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		arg.EndAt = sql.NullTime{Time: *req.body.EndAt, Valid: true}
+	}
+
+	updatedScheduledTransfer, err := server.store.UpdateScheduledTransfer(ctx, arg)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, formatScheduledTransferResponse(updatedScheduledTransfer))
+}
+
+type cancelScheduledTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	var req cancelScheduledTransferRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, isValid := server.validateScheduledTransferAccess(ctx, req.ID)
+
+	if !isValid {
+		return
+	}
+
+	if scheduledTransfer.CancelledAt.Valid {
+		err := fmt.Errorf("Scheduled transfer %d is already cancelled", scheduledTransfer.ID)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	if _, err := server.store.CancelScheduledTransfer(ctx, scheduledTransfer.ID); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+type listScheduledTransferExecutionsRequest struct {
+	params struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+	query struct {
+		Page     int32 `form:"page" binding:"required,min=1"`
+		Quantity int32 `form:"quantity" binding:"max=200"`
+	}
+}
+
+func (server *Server) listScheduledTransferExecutions(ctx *gin.Context) {
+	var req listScheduledTransferExecutionsRequest
+
+	if err := ctx.ShouldBindUri(&req.params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.BindQuery(&req.query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.query.Quantity == 0 {
+		req.query.Quantity = 40
+	}
+
+	scheduledTransfer, isValid := server.validateScheduledTransferAccess(ctx, req.params.ID, util.BankerRole, util.AdminRole)
+
+	if !isValid {
+		return
+	}
+
+	executions, err := server.store.ListScheduledTransferExecutions(ctx, db.ListScheduledTransferExecutionsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               req.query.Quantity,
+		Offset:              (req.query.Page - 1) * req.query.Quantity,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]ScheduledTransferExecutionResponse, len(executions))
+
+	for i, execution := range executions {
+		response[i] = formatScheduledTransferExecutionResponse(execution)
+	}
+
+	ctx.Header("total", fmt.Sprint(len(response)))
+	ctx.JSON(http.StatusOK, response)
+}
+
+// validateScheduledTransferAccess gets the scheduled transfer if the authenticated user owns it or holds one of the given staff roles
+func (server *Server) validateScheduledTransferAccess(ctx *gin.Context, id int64, staffRoles ...string) (db.ScheduledTransfer, bool) {
+	scheduledTransfer, err := server.store.GetScheduledTransfer(ctx, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return scheduledTransfer, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return scheduledTransfer, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if scheduledTransfer.Owner != authPayload.Username && !slices.Contains(staffRoles, authPayload.Role) {
+		err := errors.New("scheduled transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return scheduledTransfer, false
+	}
+
+	return scheduledTransfer, true
+}
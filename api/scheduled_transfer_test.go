@@ -0,0 +1,667 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/Andrew-2609/simple-bank/db/mock"
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(accounts [2]db.Account) db.ScheduledTransfer {
+	startAt := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+
+	return db.ScheduledTransfer{
+		ID:                util.RandomInt(1, 1000),
+		Owner:             accounts[0].Owner,
+		FromAccountID:     accounts[0].ID,
+		ToAccountID:       accounts[1].ID,
+		Amount:            util.RandomAmount(),
+		Frequency:         util.FrequencyMonthly,
+		FrequencyInterval: 1,
+		StartAt:           startAt,
+		NextRunAt:         sql.NullTime{Time: startAt, Valid: true},
+		CreatedAt:         time.Now().Truncate(time.Second).UTC(),
+	}
+}
+
+func unmarshallScheduledTransfer(t *testing.T, responseBody *bytes.Buffer) ScheduledTransferResponse {
+	response, err := util.UnmarshallJsonBody[ScheduledTransferResponse](responseBody)
+	require.NoError(t, err)
+	return response
+}
+
+func TestCreateScheduledTransferAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+	scheduledTransfer := createRandomScheduledTransfer(accounts)
+
+	validArg := CreateScheduledTransferRequest{
+		FromAccountID: accounts[0].ID,
+		ToAccountID:   accounts[1].ID,
+		Amount:        scheduledTransfer.Amount,
+		Currency:      util.BRL,
+		Frequency:     util.FrequencyMonthly,
+		StartAt:       scheduledTransfer.StartAt,
+	}
+
+	stubAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+			Times(1).
+			Return(accounts[0], nil)
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+			Times(1).
+			Return(accounts[1], nil)
+	}
+
+	testCases := []struct {
+		name          string
+		arg           CreateScheduledTransferRequest
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Created",
+			arg:      validArg,
+			username: accounts[0].Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					CreateScheduledTransfer(gomock.Any(), gomock.Eq(db.CreateScheduledTransferParams{
+						Owner:             accounts[0].Owner,
+						FromAccountID:     accounts[0].ID,
+						ToAccountID:       accounts[1].ID,
+						Amount:            scheduledTransfer.Amount,
+						Frequency:         util.FrequencyMonthly,
+						FrequencyInterval: 1,
+						StartAt:           scheduledTransfer.StartAt,
+						NextRunAt:         scheduledTransfer.NextRunAt,
+					})).
+					Times(1).
+					Return(scheduledTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Equal(t, formatScheduledTransferResponse(scheduledTransfer), unmarshallScheduledTransfer(t, recorder.Body))
+			},
+		},
+		{
+			name: "Created - Once By Default",
+			arg: CreateScheduledTransferRequest{
+				FromAccountID: accounts[0].ID,
+				ToAccountID:   accounts[1].ID,
+				Amount:        scheduledTransfer.Amount,
+				Currency:      util.BRL,
+				StartAt:       scheduledTransfer.StartAt,
+				EndAt:         &scheduledTransfer.StartAt,
+			},
+			username: accounts[0].Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					CreateScheduledTransfer(gomock.Any(), gomock.Eq(db.CreateScheduledTransferParams{
+						Owner:             accounts[0].Owner,
+						FromAccountID:     accounts[0].ID,
+						ToAccountID:       accounts[1].ID,
+						Amount:            scheduledTransfer.Amount,
+						Frequency:         util.FrequencyOnce,
+						FrequencyInterval: 1,
+						StartAt:           scheduledTransfer.StartAt,
+						EndAt:             scheduledTransfer.NextRunAt,
+						NextRunAt:         scheduledTransfer.NextRunAt,
+					})).
+					Times(1).
+					Return(scheduledTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "Bad Request - Unsupported Frequency",
+			arg: CreateScheduledTransferRequest{
+				FromAccountID: accounts[0].ID,
+				ToAccountID:   accounts[1].ID,
+				Amount:        scheduledTransfer.Amount,
+				Currency:      util.BRL,
+				Frequency:     "yearly",
+				StartAt:       scheduledTransfer.StartAt,
+			},
+			username: accounts[0].Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "Key: 'CreateScheduledTransferRequest.Frequency' Error:Field validation for 'Frequency' failed on the 'frequency' tag"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Bad Request - Start In The Past",
+			arg: CreateScheduledTransferRequest{
+				FromAccountID: accounts[0].ID,
+				ToAccountID:   accounts[1].ID,
+				Amount:        scheduledTransfer.Amount,
+				Currency:      util.BRL,
+				StartAt:       time.Now().Add(-time.Minute),
+			},
+			username: accounts[0].Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "scheduled transfer start must be in the future"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Bad Request - End Before Start",
+			arg: CreateScheduledTransferRequest{
+				FromAccountID: accounts[0].ID,
+				ToAccountID:   accounts[1].ID,
+				Amount:        scheduledTransfer.Amount,
+				Currency:      util.BRL,
+				StartAt:       scheduledTransfer.StartAt,
+				EndAt:         &scheduledTransfer.CreatedAt,
+			},
+			username: accounts[0].Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "scheduled transfer end can't be before its start"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Unauthorized - From Account Of Another User",
+			arg:      validArg,
+			username: accounts[1].Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "from account doesn't belong to the authenticated user"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Unprocessable Entity - Different Currencies",
+			arg:      validArg,
+			username: accounts[0].Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				usdAccount := accounts[1]
+				usdAccount.Currency = util.USD
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(1).
+					Return(usdAccount, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("Account %d currency mismatch: scheduled transfers between BRL and USD aren't supported", accounts[1].ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Internal Server Error",
+			arg:      validArg,
+			username: accounts[0].Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					CreateScheduledTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ScheduledTransfer{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+			stubVerifiedEmail(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var buf bytes.Buffer
+
+			err := json.NewEncoder(&buf).Encode(testCase.arg)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest("POST", "/scheduled-transfers", &buf)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, testCase.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetScheduledTransferAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+	scheduledTransfer := createRandomScheduledTransfer(accounts)
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: scheduledTransfer.Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+					Times(1).
+					Return(scheduledTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, formatScheduledTransferResponse(scheduledTransfer), unmarshallScheduledTransfer(t, recorder.Body))
+			},
+		},
+		{
+			name:     "OK - Banker",
+			username: "banker",
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+					Times(1).
+					Return(scheduledTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Unauthorized",
+			username: accounts[1].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+					Times(1).
+					Return(scheduledTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "scheduled transfer doesn't belong to the authenticated user"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Not Found",
+			username: scheduledTransfer.Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetScheduledTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ScheduledTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/scheduled-transfers/%d", scheduledTransfer.ID)
+
+			request, err := http.NewRequest("GET", url, nil)
+			require.NoError(t, err)
+
+			addAuthorizationWithRole(t, request, server.tokenMaker, authorizationTypeBearer, testCase.username, testCase.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateScheduledTransferAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+	scheduledTransfer := createRandomScheduledTransfer(accounts)
+
+	cancelledScheduledTransfer := scheduledTransfer
+	cancelledScheduledTransfer.NextRunAt = sql.NullTime{}
+	cancelledScheduledTransfer.CancelledAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	endAt := scheduledTransfer.StartAt.AddDate(0, 6, 0)
+	newAmount := scheduledTransfer.Amount + 1
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"amount": newAmount, "endAt": endAt},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+					Times(1).
+					Return(scheduledTransfer, nil)
+
+				updatedScheduledTransfer := scheduledTransfer
+				updatedScheduledTransfer.Amount = newAmount
+				updatedScheduledTransfer.EndAt = sql.NullTime{Time: endAt, Valid: true}
+
+				store.EXPECT().
+					UpdateScheduledTransfer(gomock.Any(), gomock.Eq(db.UpdateScheduledTransferParams{
+						ID:     scheduledTransfer.ID,
+						Amount: sql.NullInt64{Int64: newAmount, Valid: true},
+						EndAt:  updatedScheduledTransfer.EndAt,
+					})).
+					Times(1).
+					Return(updatedScheduledTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				response := unmarshallScheduledTransfer(t, recorder.Body)
+				require.Equal(t, newAmount, response.Amount)
+				require.True(t, endAt.Equal(*response.EndAt))
+			},
+		},
+		{
+			name: "OK - Only End",
+			body: gin.H{"endAt": scheduledTransfer.StartAt},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+					Times(1).
+					Return(scheduledTransfer, nil)
+				// neither the amount nor the next run that was read are written back
+				store.EXPECT().
+					UpdateScheduledTransfer(gomock.Any(), gomock.Eq(db.UpdateScheduledTransferParams{
+						ID:    scheduledTransfer.ID,
+						EndAt: sql.NullTime{Time: scheduledTransfer.StartAt, Valid: true},
+					})).
+					Times(1).
+					Return(scheduledTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Bad Request - Empty Body",
+			body: gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "at least one of amount and endAt must be provided"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Unprocessable Entity - Cancelled",
+			body: gin.H{"amount": newAmount},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+					Times(1).
+					Return(cancelledScheduledTransfer, nil)
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("Scheduled transfer %d is cancelled", scheduledTransfer.ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+			stubVerifiedEmail(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var buf bytes.Buffer
+
+			err := json.NewEncoder(&buf).Encode(testCase.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/scheduled-transfers/%d", scheduledTransfer.ID)
+
+			request, err := http.NewRequest("PATCH", url, &buf)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, scheduledTransfer.Owner, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCancelScheduledTransferAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+	scheduledTransfer := createRandomScheduledTransfer(accounts)
+
+	cancelledScheduledTransfer := scheduledTransfer
+	cancelledScheduledTransfer.NextRunAt = sql.NullTime{}
+	cancelledScheduledTransfer.CancelledAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "No Content",
+			username: scheduledTransfer.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+					Times(1).
+					Return(scheduledTransfer, nil)
+				store.EXPECT().
+					CancelScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+					Times(1).
+					Return(cancelledScheduledTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:     "Unauthorized",
+			username: accounts[1].Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+					Times(1).
+					Return(scheduledTransfer, nil)
+				store.EXPECT().CancelScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "Unprocessable Entity - Already Cancelled",
+			username: scheduledTransfer.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+					Times(1).
+					Return(cancelledScheduledTransfer, nil)
+				store.EXPECT().CancelScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("Scheduled transfer %d is already cancelled", scheduledTransfer.ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Internal Server Error",
+			username: scheduledTransfer.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+					Times(1).
+					Return(scheduledTransfer, nil)
+				store.EXPECT().
+					CancelScheduledTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ScheduledTransfer{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+			stubVerifiedEmail(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/scheduled-transfers/%d", scheduledTransfer.ID)
+
+			request, err := http.NewRequest("DELETE", url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, testCase.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListScheduledTransferExecutionsAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+	scheduledTransfer := createRandomScheduledTransfer(accounts)
+
+	executions := []db.ScheduledTransferExecution{
+		{
+			ID:                  1,
+			ScheduledTransferID: scheduledTransfer.ID,
+			TransferID:          sql.NullInt64{Int64: 10, Valid: true},
+			ScheduledFor:        scheduledTransfer.StartAt,
+			ExecutedAt:          scheduledTransfer.StartAt,
+		},
+		{
+			ID:                  2,
+			ScheduledTransferID: scheduledTransfer.ID,
+			ScheduledFor:        scheduledTransfer.StartAt.AddDate(0, 1, 0),
+			Error:               sql.NullString{String: "insufficient funds", Valid: true},
+			ExecutedAt:          scheduledTransfer.StartAt.AddDate(0, 1, 0),
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	stubAuthChecks(store)
+	store.EXPECT().
+		GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+		Times(1).
+		Return(scheduledTransfer, nil)
+	store.EXPECT().
+		ListScheduledTransferExecutions(gomock.Any(), gomock.Eq(db.ListScheduledTransferExecutionsParams{
+			ScheduledTransferID: scheduledTransfer.ID,
+			Limit:               40,
+			Offset:              0,
+		})).
+		Times(1).
+		Return(executions, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/scheduled-transfers/%d/executions?page=1", scheduledTransfer.ID)
+
+	request, err := http.NewRequest("GET", url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, scheduledTransfer.Owner, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "2", recorder.Header().Get("total"))
+
+	response, err := util.UnmarshallJsonBody[[]ScheduledTransferExecutionResponse](recorder.Body)
+	require.NoError(t, err)
+	require.Len(t, response, 2)
+	require.Equal(t, int64(10), *response[0].TransferID)
+	require.Nil(t, response[0].Error)
+	require.Nil(t, response[1].TransferID)
+	require.Equal(t, "insufficient funds", *response[1].Error)
+}
@@ -1,9 +1,15 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 
 	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/fx"
+	"github.com/Andrew-2609/simple-bank/mail"
 	"github.com/Andrew-2609/simple-bank/token"
 	"github.com/Andrew-2609/simple-bank/util"
 	"github.com/gin-gonic/gin"
@@ -12,27 +18,115 @@ import (
 )
 
 type Server struct {
-	config     util.Config
-	store      db.Store
-	tokenMaker token.Maker
-	router     *gin.Engine
+	config            util.Config
+	store             db.Store
+	tokenMaker        token.Maker
+	mailer            mail.EmailSender
+	rateProvider      fx.RateProvider
+	currencies        *util.CurrencyRegistry
+	passwordHasher    util.PasswordHasher
+	passwordPolicy    util.PasswordPolicy
+	dummyPasswordHash string
+	router            *gin.Engine
+	// background tracks the work handlers leave running after they respond
+	background sync.WaitGroup
 }
 
 func (server *Server) Start(address string) error {
 	return server.router.Run(address)
 }
 
+// LoadCurrencies replaces the default currencies of the server with the ones in the database
+func (server *Server) LoadCurrencies(ctx context.Context) error {
+	currencies, err := server.store.ListCurrencies(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	registryCurrencies := make([]util.Currency, len(currencies))
+
+	for i, currency := range currencies {
+		registryCurrencies[i] = toRegistryCurrency(currency)
+	}
+
+	server.currencies.Replace(registryCurrencies)
+
+	return nil
+}
+
+// ReloadCurrencies loads the currencies from the database every interval, until the context is done,
+// so currencies enabled or disabled through another instance of the server are picked up by this one
+func (server *Server) ReloadCurrencies(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := server.LoadCurrencies(ctx); err != nil {
+			log.Printf("could not reload currencies: %v", err)
+		}
+	}
+}
+
 func NewServer(config util.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	tokenMaker, err := token.NewMaker(config)
 
 	if err != nil {
 		return nil, fmt.Errorf("could not create token maker: %w", err)
 	}
 
-	server := &Server{config: config, store: store, tokenMaker: tokenMaker}
+	mailer, err := mail.NewSender(config)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not create email sender: %w", err)
+	}
+
+	rateProvider, err := fx.NewRateProvider(config)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not create exchange rate provider: %w", err)
+	}
+
+	passwordHasher, err := util.NewPasswordHasher(config)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not create password hasher: %w", err)
+	}
+
+	passwordPolicy, err := util.NewPasswordPolicy(config)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not create password policy: %w", err)
+	}
+
+	// checked when the user doesn't exist, so unknown usernames take as long to reject as wrong passwords
+	dummyPasswordHash, err := passwordHasher.Hash(util.RandomString(16))
+
+	if err != nil {
+		return nil, err
+	}
+
+	server := &Server{
+		config:            config,
+		store:             store,
+		tokenMaker:        tokenMaker,
+		mailer:            mailer,
+		rateProvider:      rateProvider,
+		currencies:        util.NewCurrencyRegistry(util.DefaultCurrencies),
+		passwordHasher:    passwordHasher,
+		passwordPolicy:    passwordPolicy,
+		dummyPasswordHash: dummyPasswordHash,
+	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("currency", validateCurrency)
+		v.RegisterValidation("scope", validateScope)
+		v.RegisterValidation("frequency", validateFrequency)
 	}
 
 	server.setupRoutes()
@@ -46,16 +140,80 @@ func (server *Server) setupRoutes() {
 
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.login)
+	router.POST("/users/login/mfa", server.verifyMFA)
+	router.GET("/users/verify_email", server.verifyEmail)
+	router.POST("/users/password/forgot", server.forgotPassword)
+	router.POST("/users/password/reset", server.resetPassword)
+	router.POST("/tokens/renew_access", server.renewAccessToken)
+	router.GET("/.well-known/paseto-public-key", server.getTokenPublicKey)
+
+	authRoutes := router.Group("/", authMiddleware(server.tokenMaker, server.store))
+
+	authRoutes.GET("/currencies", server.listCurrencies)
+
+	sessionRoutes := authRoutes.Group("/", accessTokenMiddleware())
+
+	sessionRoutes.POST("/users/logout", server.logout)
+	sessionRoutes.POST("/users/logout-all", server.logoutAll)
+
+	usersReadRoutes := authRoutes.Group("/", scopeMiddleware(token.ScopeUsersRead))
+
+	usersReadRoutes.GET("/users/me", server.getCurrentUser)
+	usersReadRoutes.GET("/api-keys", server.listAPIKeys)
+
+	usersWriteRoutes := authRoutes.Group("/", scopeMiddleware(token.ScopeUsersWrite))
+
+	usersWriteRoutes.PATCH("/users/me", server.updateCurrentUser)
+	usersWriteRoutes.POST("/users/me/verify_email", server.resendVerifyEmail)
+	usersWriteRoutes.PUT("/users/me/password", server.changePassword)
+	usersWriteRoutes.POST("/users/me/totp", server.enrollTOTP)
+	usersWriteRoutes.POST("/users/me/totp/confirm", server.confirmTOTP)
+	usersWriteRoutes.POST("/api-keys", server.createAPIKey)
+	usersWriteRoutes.DELETE("/api-keys/:id", server.revokeAPIKey)
+
+	accountsReadRoutes := authRoutes.Group("/", scopeMiddleware(token.ScopeAccountsRead))
+
+	accountsReadRoutes.GET("/accounts", server.listAccounts)
+	accountsReadRoutes.GET("/accounts/:id", server.getAccount)
+	accountsReadRoutes.GET("/accounts/:id/transfers", server.listAccountTransfers)
+	accountsReadRoutes.GET("/transfers/:id", server.getTransfer)
+	accountsReadRoutes.GET("/scheduled-transfers", server.listScheduledTransfers)
+	accountsReadRoutes.GET("/scheduled-transfers/:id", server.getScheduledTransfer)
+	accountsReadRoutes.GET("/scheduled-transfers/:id/executions", server.listScheduledTransferExecutions)
+
+	accountsWriteRoutes := authRoutes.Group("/", scopeMiddleware(token.ScopeAccountsWrite))
+
+	accountsWriteRoutes.POST("/accounts", verifiedEmailMiddleware(server.store), server.createAccount)
+	accountsWriteRoutes.PUT("/accounts/:id", server.updateAccount)
+	accountsWriteRoutes.DELETE("/accounts/:id", server.deleteAccount)
+
+	transfersCreateRoutes := authRoutes.Group("/", scopeMiddleware(token.ScopeTransfersCreate), verifiedEmailMiddleware(server.store))
+
+	transfersCreateRoutes.POST("/transfers", server.createTransfer)
+	transfersCreateRoutes.POST("/transfers/batch", server.createBatchTransfer)
+	transfersCreateRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+	transfersCreateRoutes.POST("/transfers/:id/capture", server.captureTransfer)
+	transfersCreateRoutes.POST("/transfers/:id/void", server.voidTransfer)
+	transfersCreateRoutes.POST("/scheduled-transfers", server.createScheduledTransfer)
+	transfersCreateRoutes.PATCH("/scheduled-transfers/:id", server.updateScheduledTransfer)
+	transfersCreateRoutes.DELETE("/scheduled-transfers/:id", server.cancelScheduledTransfer)
+
+	adminRoutes := authRoutes.Group("/", roleMiddleware(util.AdminRole))
+
+	adminUsersReadRoutes := adminRoutes.Group("/", scopeMiddleware(token.ScopeUsersRead))
+
+	adminUsersReadRoutes.GET("/users/:username", server.getUser)
+
+	adminUsersWriteRoutes := adminRoutes.Group("/", scopeMiddleware(token.ScopeUsersWrite))
 
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	adminUsersWriteRoutes.PUT("/users/:username/role", server.updateUserRole)
 
-	authRoutes.POST("/accounts", server.createAccount)
-	authRoutes.GET("/accounts", server.listAccounts)
-	authRoutes.GET("/accounts/:id", server.getAccount)
-	authRoutes.PUT("/accounts/:id", server.updateAccount)
-	authRoutes.DELETE("/accounts/:id", server.deleteAccount)
+	adminAccountsWriteRoutes := adminRoutes.Group("/", scopeMiddleware(token.ScopeAccountsWrite))
 
-	authRoutes.POST("/transfers", server.createTransfer)
+	adminAccountsWriteRoutes.POST("/accounts/:id/freeze", server.freezeAccount)
+	adminAccountsWriteRoutes.POST("/accounts/:id/unfreeze", server.unfreezeAccount)
+	adminAccountsWriteRoutes.POST("/currencies/:code/enable", server.enableCurrency)
+	adminAccountsWriteRoutes.POST("/currencies/:code/disable", server.disableCurrency)
 
 	server.router = router
 }
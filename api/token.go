@@ -0,0 +1,140 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RenewAccessTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type RenewAccessTokenResponse struct {
+	AccessToken          string    `json:"access_token"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+}
+
+func (server *Server) renewAccessToken(ctx *gin.Context) {
+	var req RenewAccessTokenRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if refreshPayload.Purpose != token.PurposeRefresh {
+		err := errors.New("token is not a refresh token")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	session, err := server.store.GetSession(ctx, refreshPayload.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if session.IsBlocked {
+		err := errors.New("session is blocked")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if session.Username != refreshPayload.Username {
+		err := errors.New("session doesn't belong to the token user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if session.RefreshToken != req.RefreshToken {
+		err := errors.New("session refresh token mismatch")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		err := errors.New("session has expired")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if err := checkPasswordChange(ctx, server.store, refreshPayload); err != nil {
+		return
+	}
+
+	accessToken, accessPayload, err := server.createToken(refreshPayload.Username, refreshPayload.Role, refreshPayload.Scopes, session.ID, "", server.config.AccessTokenDuration)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RenewAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+	})
+}
+
+type TokenPublicKeyResponse struct {
+	Version   string `json:"version"`
+	Purpose   string `json:"purpose"`
+	PublicKey string `json:"public_key"`
+}
+
+func (server *Server) getTokenPublicKey(ctx *gin.Context) {
+	provider, ok := server.tokenMaker.(token.PublicKeyProvider)
+
+	if !ok {
+		err := errors.New("tokens are not signed with a public key")
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, TokenPublicKeyResponse{
+		Version:   "v2",
+		Purpose:   "public",
+		PublicKey: hex.EncodeToString(provider.PublicKey()),
+	})
+}
+
+// createToken builds a new payload for the given user, scopes, session and purpose and signs it with the server's token maker.
+// Access tokens have no purpose. Refresh tokens are created with a nil session ID, as their own ID becomes the ID of the session.
+func (server *Server) createToken(username string, role string, scopes []string, sessionID uuid.UUID, purpose string, duration time.Duration) (string, *token.Payload, error) {
+	payload, err := token.NewPayload(username, role, duration)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	payload.Scopes = scopes
+	payload.SessionID = sessionID
+	payload.Purpose = purpose
+
+	signedToken, err := server.tokenMaker.CreateToken(payload)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return signedToken, payload, nil
+}
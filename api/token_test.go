@@ -0,0 +1,325 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	mockdb "github.com/Andrew-2609/simple-bank/db/mock"
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/token"
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRefreshToken(t *testing.T, tokenMaker token.Maker, username string, duration time.Duration) (string, *token.Payload) {
+	payload, err := token.NewPayload(username, util.DepositorRole, duration)
+	require.NoError(t, err)
+
+	payload.Purpose = token.PurposeRefresh
+
+	refreshToken, err := tokenMaker.CreateToken(payload)
+	require.NoError(t, err)
+
+	return refreshToken, payload
+}
+
+func createSessionFor(refreshToken string, payload *token.Payload) db.Session {
+	return db.Session{
+		ID:           payload.ID,
+		Username:     payload.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    "Go-http-client/1.1",
+		ClientIp:     "192.0.2.1",
+		IsBlocked:    false,
+		ExpiresAt:    payload.ExpiredAt,
+	}
+}
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user, _ := createRandomUser()
+
+	testCases := []struct {
+		name          string
+		tokenDuration time.Duration
+		buildStubs    func(store *mockdb.MockStore, refreshToken string, payload *token.Payload)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:          "OK",
+			tokenDuration: time.Hour,
+			buildStubs: func(store *mockdb.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(createSessionFor(refreshToken, payload), nil)
+				store.EXPECT().
+					GetUserPasswordChangedAt(gomock.Any(), gomock.Eq(payload.Username)).
+					Times(1).
+					Return(time.Time{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				response, err := util.UnmarshallJsonBody[RenewAccessTokenResponse](recorder.Body)
+				require.NoError(t, err)
+
+				require.Regexp(t, regexp.MustCompile(`^v2\.local\..+$`), response.AccessToken)
+				require.WithinDuration(t, time.Now().Add(time.Minute), response.AccessTokenExpiresAt, time.Second)
+			},
+		},
+		{
+			name:          "Refresh Token Issued Before Password Change",
+			tokenDuration: time.Hour,
+			buildStubs: func(store *mockdb.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(createSessionFor(refreshToken, payload), nil)
+				store.EXPECT().
+					GetUserPasswordChangedAt(gomock.Any(), gomock.Eq(payload.Username)).
+					Times(1).
+					Return(time.Now().Add(time.Second), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "token was issued before the last password change"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:          "Expired Refresh Token",
+			tokenDuration: -time.Minute,
+			buildStubs: func(store *mockdb.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "token has expired"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:          "Session Not Found",
+			tokenDuration: time.Hour,
+			buildStubs: func(store *mockdb.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrNoRows.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:          "Internal Server Error",
+			tokenDuration: time.Hour,
+			buildStubs: func(store *mockdb.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:          "Blocked Session",
+			tokenDuration: time.Hour,
+			buildStubs: func(store *mockdb.MockStore, refreshToken string, payload *token.Payload) {
+				session := createSessionFor(refreshToken, payload)
+				session.IsBlocked = true
+
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "session is blocked"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:          "Mismatched Session User",
+			tokenDuration: time.Hour,
+			buildStubs: func(store *mockdb.MockStore, refreshToken string, payload *token.Payload) {
+				session := createSessionFor(refreshToken, payload)
+				session.Username = "someoneelse"
+
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "session doesn't belong to the token user"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:          "Mismatched Session Token",
+			tokenDuration: time.Hour,
+			buildStubs: func(store *mockdb.MockStore, refreshToken string, payload *token.Payload) {
+				session := createSessionFor(refreshToken, payload)
+				session.RefreshToken = "another-refresh-token"
+
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "session refresh token mismatch"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:          "Expired Session",
+			tokenDuration: time.Hour,
+			buildStubs: func(store *mockdb.MockStore, refreshToken string, payload *token.Payload) {
+				session := createSessionFor(refreshToken, payload)
+				session.ExpiresAt = time.Now().Add(-time.Minute)
+
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "session has expired"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// start test server
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			// build stubs
+			refreshToken, payload := createRefreshToken(t, server.tokenMaker, user.Username, testCase.tokenDuration)
+			testCase.buildStubs(store, refreshToken, payload)
+
+			var buf bytes.Buffer
+
+			err := json.NewEncoder(&buf).Encode(RenewAccessTokenRequest{RefreshToken: refreshToken})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest("POST", "/tokens/renew_access", &buf)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+
+			// check response
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRenewAccessTokenWithAccessToken(t *testing.T) {
+	user, _ := createRandomUser()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	accessToken, _, err := server.createToken(user.Username, user.Role, token.AllScopes, uuid.New(), "", time.Minute)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	err = json.NewEncoder(&buf).Encode(RenewAccessTokenRequest{RefreshToken: accessToken})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest("POST", "/tokens/renew_access", &buf)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	require.Exactly(t, map[string]interface{}{"error": "token is not a refresh token"}, UnmarshallAny(t, recorder.Body))
+}
+
+func TestGetTokenPublicKeyAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		tokenType     string
+		privateKey    string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			tokenType:  token.PasetoPublicType,
+			privateKey: "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				response, err := util.UnmarshallJsonBody[TokenPublicKeyResponse](recorder.Body)
+				require.NoError(t, err)
+
+				require.Equal(t, "v2", response.Version)
+				require.Equal(t, "public", response.Purpose)
+				require.Equal(t, "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a", response.PublicKey)
+			},
+		},
+		{
+			name:       "Symmetric Tokens",
+			tokenType:  token.PasetoLocalType,
+			privateKey: "",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		testCase := testCases[i]
+
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			config := util.Config{
+				TokenType:                 testCase.tokenType,
+				TokenSymmetricKey:         util.RandomString(32),
+				TokenAsymmetricPrivateKey: testCase.privateKey,
+				AccessTokenDuration:       time.Minute,
+				RefreshTokenDuration:      time.Hour,
+			}
+
+			server, err := NewServer(config, store)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/.well-known/paseto-public-key", nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
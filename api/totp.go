@@ -0,0 +1,292 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/token"
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	totpIssuer         = "Simple Bank"
+	recoveryCodesCount = 10
+)
+
+type MFAChallengeResponse struct {
+	MFARequired       bool      `json:"mfa_required"`
+	MFAToken          string    `json:"mfa_token"`
+	MFATokenExpiresAt time.Time `json:"mfa_token_expires_at"`
+}
+
+// createMFAChallenge responds with a short-lived token proving the password of the user was checked,
+// which must be exchanged together with a second factor for the access and refresh tokens.
+func (server *Server) createMFAChallenge(ctx *gin.Context, loggedUser db.User, scopes []string) {
+	payload, err := token.NewPayload(loggedUser.Username, loggedUser.Role, server.config.MFAChallengeDuration)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	payload.Scopes = scopes
+	payload.Purpose = token.PurposeMFAChallenge
+
+	mfaToken, err := server.tokenMaker.CreateToken(payload)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, MFAChallengeResponse{
+		MFARequired:       true,
+		MFAToken:          mfaToken,
+		MFATokenExpiresAt: payload.ExpiredAt,
+	})
+}
+
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// verifyMFA exchanges an MFA challenge token and a TOTP or recovery code for the access and refresh tokens.
+// The challenge is claimed before the code is checked, so it can only be redeemed by a single request, even a failed one.
+func (server *Server) verifyMFA(ctx *gin.Context) {
+	var req VerifyMFARequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	mfaPayload, err := server.tokenMaker.VerifyToken(req.MFAToken)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if mfaPayload.Purpose != token.PurposeMFAChallenge {
+		err := errors.New("token is not an mfa challenge token")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if err := checkPasswordChange(ctx, server.store, mfaPayload); err != nil {
+		return
+	}
+
+	// wrong codes count as failed logins, so the challenge can't be brute forced
+	if err := server.checkLoginThrottle(ctx, mfaPayload.Username); err != nil {
+		return
+	}
+
+	// concurrent requests with the same challenge can't both get past this point
+	_, err = server.store.ClaimToken(ctx, db.ClaimTokenParams{
+		ID:        mfaPayload.ID,
+		Username:  mfaPayload.Username,
+		ExpiresAt: mfaPayload.ExpiredAt,
+	})
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.New("mfa token has already been used")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	totpSecret, err := server.store.GetTOTPSecret(ctx, mfaPayload.Username)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.New("two-factor authentication is not enabled")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if step, ok := util.MatchTOTPCode(totpSecret.Secret, req.Code, time.Now()); ok {
+		// a code is only accepted once, so an observed code can't be replayed against another challenge
+		_, err := server.store.UseTOTPStep(ctx, db.UseTOTPStepParams{
+			Username: mfaPayload.Username,
+			Step:     step,
+		})
+
+		if err != nil {
+			if err == sql.ErrNoRows {
+				server.rejectMFA(ctx, mfaPayload.Username, errors.New("mfa code has already been used"))
+				return
+			}
+
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	} else {
+		_, err := server.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+			Username:   mfaPayload.Username,
+			HashedCode: util.HashRecoveryCode(req.Code),
+		})
+
+		if err != nil {
+			if err == sql.ErrNoRows {
+				server.rejectMFA(ctx, mfaPayload.Username, errors.New("invalid mfa code"))
+				return
+			}
+
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	foundUser, err := server.store.GetUser(ctx, mfaPayload.Username)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.createLoginSession(ctx, foundUser, mfaPayload.Scopes)
+}
+
+// rejectMFA records a wrong mfa code as a failed login and responds with the given error.
+func (server *Server) rejectMFA(ctx *gin.Context, username string, err error) {
+	if err := server.recordLoginFailure(ctx, username); err != nil {
+		return
+	}
+
+	ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+}
+
+type EnrollTOTPResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// enrollTOTP generates a new TOTP secret for the authenticated user.
+// It isn't enforced on login until it is confirmed with a first code.
+func (server *Server) enrollTOTP(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	totpSecret, err := server.store.GetTOTPSecret(ctx, authPayload.Username)
+
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err == nil && totpSecret.ConfirmedAt.Valid {
+		err := errors.New("two-factor authentication is already enabled")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	totpSecret, err = server.store.UpsertTOTPSecret(ctx, db.UpsertTOTPSecretParams{
+		Username: authPayload.Username,
+		Secret:   secret,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, EnrollTOTPResponse{
+		Secret:     totpSecret.Secret,
+		OTPAuthURI: util.TOTPURI(totpIssuer, totpSecret.Username, totpSecret.Secret),
+	})
+}
+
+type ConfirmTOTPRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type ConfirmTOTPResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// confirmTOTP enables two-factor authentication once the user proves their authenticator works,
+// responding with recovery codes that are only shown this once.
+func (server *Server) confirmTOTP(ctx *gin.Context) {
+	var req ConfirmTOTPRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	totpSecret, err := server.store.GetTOTPSecret(ctx, authPayload.Username)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if totpSecret.ConfirmedAt.Valid {
+		err := errors.New("two-factor authentication is already enabled")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	if !util.ValidateTOTPCode(totpSecret.Secret, req.Code, time.Now()) {
+		err := errors.New("invalid totp code")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	recoveryCodes, err := util.GenerateRecoveryCodes(recoveryCodesCount)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	hashedRecoveryCodes := make([]string, len(recoveryCodes))
+
+	for i, code := range recoveryCodes {
+		hashedRecoveryCodes[i] = util.HashRecoveryCode(code)
+	}
+
+	_, err = server.store.ConfirmTOTPTx(ctx, db.ConfirmTOTPTxParams{
+		Username:            authPayload.Username,
+		HashedRecoveryCodes: hashedRecoveryCodes,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ConfirmTOTPResponse{RecoveryCodes: recoveryCodes})
+}
@@ -0,0 +1,605 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/Andrew-2609/simple-bank/db/mock"
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/token"
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createMFAToken(t *testing.T, tokenMaker token.Maker, username string, purpose string) (string, *token.Payload) {
+	payload, err := token.NewPayload(username, util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	payload.Scopes = []string{token.ScopeAccountsRead}
+	payload.Purpose = purpose
+
+	mfaToken, err := tokenMaker.CreateToken(payload)
+	require.NoError(t, err)
+
+	return mfaToken, payload
+}
+
+func createConfirmedTOTPSecret(t *testing.T, username string) db.TotpSecret {
+	secret, err := util.GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	return db.TotpSecret{
+		Username:    username,
+		Secret:      secret,
+		ConfirmedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		CreatedAt:   time.Now(),
+	}
+}
+
+func TestVerifyMFAAPI(t *testing.T) {
+	user, _ := createRandomUser()
+	totpSecret := createConfirmedTOTPSecret(t, user.Username)
+
+	currentCode := func(t *testing.T) string {
+		code, err := util.TOTPCode(totpSecret.Secret, time.Now())
+		require.NoError(t, err)
+		return code
+	}
+
+	testCases := []struct {
+		name          string
+		purpose       string
+		code          func(t *testing.T) string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK With TOTP Code",
+			purpose: token.PurposeMFAChallenge,
+			code:    currentCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ClaimToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ClaimTokenParams) (uuid.UUID, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.NotZero(t, arg.ID)
+						return arg.ID, nil
+					})
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(totpSecret, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UseTOTPStepParams) (db.TotpSecret, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.InDelta(t, util.TOTPStep(time.Now()), arg.Step, 1)
+						return totpSecret, nil
+					})
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateLoginAttempt(gomock.Any(), gomock.Eq(db.CreateLoginAttemptParams{
+						Username:  user.Username,
+						ClientIp:  "",
+						Succeeded: true,
+					})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						return db.Session{ID: arg.ID, Username: arg.Username}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				response, err := util.UnmarshallJsonBody[LoginResponse](recorder.Body)
+				require.NoError(t, err)
+
+				require.NotEmpty(t, response.AccessToken)
+				require.NotEmpty(t, response.RefreshToken)
+				require.Equal(t, []string{token.ScopeAccountsRead}, response.Scopes)
+			},
+		},
+		{
+			name:    "OK With Recovery Code",
+			purpose: token.PurposeMFAChallenge,
+			code: func(t *testing.T) string {
+				return "abcde-12345"
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ClaimToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(uuid.New(), nil)
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(totpSecret, nil)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Eq(db.UseRecoveryCodeParams{
+						Username:   user.Username,
+						HashedCode: util.HashRecoveryCode("abcde-12345"),
+					})).
+					Times(1).
+					Return(db.RecoveryCode{}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "Invalid Code",
+			purpose: token.PurposeMFAChallenge,
+			code: func(t *testing.T) string {
+				return "000000"
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ClaimToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(uuid.New(), nil)
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpSecret{Username: user.Username, Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", ConfirmedAt: totpSecret.ConfirmedAt}, nil)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecoveryCode{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateLoginAttempt(gomock.Any(), gomock.Eq(db.CreateLoginAttemptParams{
+						Username:  user.Username,
+						ClientIp:  "",
+						Succeeded: false,
+					})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "invalid mfa code"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:    "Lockout Triggered",
+			purpose: token.PurposeMFAChallenge,
+			code: func(t *testing.T) string {
+				return "000000"
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ClaimToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(uuid.New(), nil)
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpSecret{Username: user.Username, Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", ConfirmedAt: totpSecret.ConfirmedAt}, nil)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecoveryCode{}, sql.ErrNoRows)
+				store.EXPECT().
+					CountFailedLoginAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(3), nil)
+				store.EXPECT().
+					CountLoginLockouts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					CreateLoginLockout(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateLoginLockoutParams) (db.LoginLockout, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, int32(3), arg.FailedAttempts)
+						return db.LoginLockout{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "invalid mfa code"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:    "Locked Out",
+			purpose: token.PurposeMFAChallenge,
+			code:    currentCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ClaimToken(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					GetActiveLoginLockout(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.LoginLockout{Username: user.Username, LockedUntil: time.Now().Add(time.Minute)}, nil)
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name:    "TOTP Code Already Used",
+			purpose: token.PurposeMFAChallenge,
+			code:    currentCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ClaimToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(uuid.New(), nil)
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(totpSecret, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TotpSecret{}, sql.ErrNoRows)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "mfa code has already been used"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:    "Access Token Instead Of MFA Token",
+			purpose: "",
+			code:    currentCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "token is not an mfa challenge token"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:    "MFA Token Already Used",
+			purpose: token.PurposeMFAChallenge,
+			code:    currentCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ClaimToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(uuid.Nil, sql.ErrNoRows)
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "mfa token has already been used"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:    "MFA Token Issued Before Password Change",
+			purpose: token.PurposeMFAChallenge,
+			code:    currentCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserPasswordChangedAt(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(time.Now().Add(time.Second), nil)
+				store.EXPECT().
+					ClaimToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "token was issued before the last password change"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:    "TOTP Not Enabled",
+			purpose: token.PurposeMFAChallenge,
+			code:    currentCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ClaimToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(uuid.New(), nil)
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpSecret{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "two-factor authentication is not enabled"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubLoginThrottle(store)
+			store.EXPECT().
+				GetUserPasswordChangedAt(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				Return(time.Time{}, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			mfaToken, _ := createMFAToken(t, server.tokenMaker, user.Username, testCase.purpose)
+
+			body, err := json.Marshal(VerifyMFARequest{MFAToken: mfaToken, Code: testCase.code(t)})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login/mfa", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestEnrollTOTPAPI(t *testing.T) {
+	user, _ := createRandomUser()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Created",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpSecret{}, sql.ErrNoRows)
+				store.EXPECT().
+					UpsertTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpsertTOTPSecretParams) (db.TotpSecret, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Len(t, arg.Secret, 32)
+						return db.TotpSecret{Username: arg.Username, Secret: arg.Secret}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				response, err := util.UnmarshallJsonBody[EnrollTOTPResponse](recorder.Body)
+				require.NoError(t, err)
+
+				require.Len(t, response.Secret, 32)
+				require.Equal(t, util.TOTPURI(totpIssuer, user.Username, response.Secret), response.OTPAuthURI)
+			},
+		},
+		{
+			name: "Already Enabled",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(createConfirmedTOTPSecret(t, user.Username), nil)
+				store.EXPECT().
+					UpsertTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "two-factor authentication is already enabled"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Internal Server Error",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpSecret{}, sql.ErrNoRows)
+				store.EXPECT().
+					UpsertTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TotpSecret{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/totp", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestConfirmTOTPAPI(t *testing.T) {
+	user, _ := createRandomUser()
+
+	pendingSecret := createConfirmedTOTPSecret(t, user.Username)
+	pendingSecret.ConfirmedAt = sql.NullTime{}
+
+	validCode, err := util.TOTPCode(pendingSecret.Secret, time.Now())
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		code          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			code: validCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pendingSecret, nil)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ConfirmTOTPTxParams) (db.TotpSecret, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Len(t, arg.HashedRecoveryCodes, recoveryCodesCount)
+						return createConfirmedTOTPSecret(t, user.Username), nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				response, err := util.UnmarshallJsonBody[ConfirmTOTPResponse](recorder.Body)
+				require.NoError(t, err)
+
+				require.Len(t, response.RecoveryCodes, recoveryCodesCount)
+			},
+		},
+		{
+			name: "Invalid Code",
+			code: "000000",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpSecret{Username: user.Username, Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"}, nil)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "invalid totp code"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Not Enrolled",
+			code: validCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpSecret{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "Already Enabled",
+			code: validCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(createConfirmedTOTPSecret(t, user.Username), nil)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "Bad Request",
+			code: "abc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(ConfirmTOTPRequest{Code: testCase.code})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/totp/confirm", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/fx"
 	"github.com/Andrew-2609/simple-bank/token"
+	"github.com/Andrew-2609/simple-bank/util"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,44 +19,191 @@ type CreateTransferRequest struct {
 	FromAccountID int64  `json:"fromAccountId" binding:"required,min=1"`
 	ToAccountID   int64  `json:"toAccountId" binding:"required,min=1"`
 	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	Currency      string `json:"currency" binding:"required"`
+}
+
+// transferModeAuthorize creates a pending transfer that holds the money until it is captured or voided,
+// instead of posting it right away
+const transferModeAuthorize = "authorize"
+
+type createTransferQuery struct {
+	Mode string `form:"mode" binding:"omitempty,oneof=post authorize"`
+}
+
+// authorizeTransferRequest is what is hashed for the idempotency key of authorized transfers,
+// so the key of a posted transfer can't be replayed to authorize the same one
+type authorizeTransferRequest struct {
+	CreateTransferRequest
+	Mode string `json:"mode"`
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
 	var req CreateTransferRequest
+	var query createTransferQuery
+
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
 	if err := ctx.BindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	fromAccount, isValid := server.validateAccountTransfer(ctx, req.FromAccountID, req.Currency)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	if !isValid {
+	var hashedRequest any = req
+
+	if query.Mode == transferModeAuthorize {
+		hashedRequest = authorizeTransferRequest{CreateTransferRequest: req, Mode: query.Mode}
+	}
+
+	idempotencyKey, err := getTransferIdempotencyKey(ctx, authPayload.Username, hashedRequest)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	if authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload); fromAccount.Owner != authPayload.Username {
-		err := errors.New("from account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if idempotencyKey != nil && server.replayTransfer(ctx, idempotencyKey) {
 		return
 	}
 
-	_, isValid = server.validateAccountTransfer(ctx, req.ToAccountID, req.Currency)
+	arg, status, err := server.prepareTransfer(ctx, authPayload.Username, req)
 
-	if !isValid {
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+
+	arg.IdempotencyKey = idempotencyKey
+
+	var result db.TransferTxResult
+
+	if query.Mode == transferModeAuthorize {
+		result, err = server.store.AuthorizeTransferTx(ctx, db.AuthorizeTransferTxParams{
+			TransferTxParams: arg,
+			HoldExpiresAt:    time.Now().Add(server.config.TransferHoldDuration),
+		})
+	} else {
+		result, err = server.store.TransferTx(ctx, arg)
+	}
+
+	if err != nil {
+		if isIdempotencyKeyConflict(err) && server.replayTransfer(ctx, idempotencyKey) {
+			return
+		}
+
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			err := fmt.Errorf("Account %d has insufficient funds", req.FromAccountID)
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
+	ctx.JSON(http.StatusCreated, result)
+}
+
+// prepareTransfer checks that a transfer of the given user can be made and converts it between currencies if needed.
+// When it can't, the error comes along with the status to respond with
+func (server *Server) prepareTransfer(ctx *gin.Context, username string, req CreateTransferRequest) (db.TransferTxParams, int, error) {
 	arg := db.TransferTxParams{
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
 		Amount:        req.Amount,
 	}
 
-	result, err := server.store.TransferTx(ctx, arg)
+	if err := server.checkCurrency(req.Currency); err != nil {
+		return arg, http.StatusBadRequest, err
+	}
+
+	fromAccount, status, err := server.getTransferAccount(ctx, req.FromAccountID)
 
 	if err != nil {
+		return arg, status, err
+	}
+
+	if fromAccount.Currency != req.Currency {
+		err := fmt.Errorf("Account %d currency mismatch: %s should be %s", req.FromAccountID, req.Currency, fromAccount.Currency)
+		return arg, http.StatusUnprocessableEntity, err
+	}
+
+	if fromAccount.Owner != username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		return arg, http.StatusUnauthorized, err
+	}
+
+	toAccount, status, err := server.getTransferAccount(ctx, req.ToAccountID)
+
+	if err != nil {
+		return arg, status, err
+	}
+
+	if toAccount.Currency != fromAccount.Currency {
+		status, err = server.convertTransfer(&arg, fromAccount, toAccount)
+	}
+
+	return arg, status, err
+}
+
+type createBatchTransferRequest struct {
+	Transfers []CreateTransferRequest `json:"transfers" binding:"required,min=1,max=1000,dive"`
+}
+
+// createBatchTransfer makes all the transfers or none of them, checking every one before making any.
+// Errors tell which transfer caused them by its position in the batch
+func (server *Server) createBatchTransfer(ctx *gin.Context) {
+	var req createBatchTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.BatchTransferTxParams{
+		Transfers: make([]db.TransferTxParams, len(req.Transfers)),
+	}
+
+	for i, transferReq := range req.Transfers {
+		transferArg, status, err := server.prepareTransfer(ctx, authPayload.Username, transferReq)
+
+		if err != nil {
+			err := fmt.Errorf("transfers[%d]: %w", i, err)
+			ctx.JSON(status, errorResponse(err))
+			return
+		}
+
+		arg.Transfers[i] = transferArg
+	}
+
+	result, err := server.store.BatchTransferTx(ctx, arg)
+
+	if err != nil {
+		var batchErr *db.BatchTransferError
+
+		if errors.As(err, &batchErr) && errors.Is(batchErr.Err, db.ErrInsufficientFunds) {
+			err := fmt.Errorf("transfers[%d]: Account %d has insufficient funds", batchErr.Index, req.Transfers[batchErr.Index].FromAccountID)
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		if errors.As(err, &batchErr) && errors.Is(batchErr.Err, sql.ErrNoRows) {
+			err := fmt.Errorf("transfers[%d]: %w", batchErr.Index, batchErr.Err)
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -60,24 +211,370 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, result)
 }
 
-func (server *Server) validateAccountTransfer(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+func (server *Server) validateAccountTransfer(ctx *gin.Context, accountID int64) (db.Account, bool) {
+	account, status, err := server.getTransferAccount(ctx, accountID)
+
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
+
+// getTransferAccount gets an account that money is going to be moved from or to, which can't be frozen
+func (server *Server) getTransferAccount(ctx *gin.Context, accountID int64) (db.Account, int, error) {
 	account, err := server.store.GetAccount(ctx, accountID)
 
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return account, http.StatusNotFound, err
+		}
+
+		return account, http.StatusInternalServerError, err
+	}
+
+	if account.IsFrozen {
+		return account, http.StatusUnprocessableEntity, fmt.Errorf("Account %d is frozen", accountID)
+	}
+
+	return account, http.StatusOK, nil
+}
+
+// convertTransfer sets how much the to account is credited, in its own currency, and the exchange rate applied to get it
+func (server *Server) convertTransfer(arg *db.TransferTxParams, fromAccount db.Account, toAccount db.Account) (int, error) {
+	rate, err := server.rateProvider.Rate(fromAccount.Currency, toAccount.Currency)
+
+	if err != nil {
+		if errors.Is(err, fx.ErrRateNotFound) {
+			return http.StatusUnprocessableEntity, err
+		}
+
+		return http.StatusInternalServerError, err
+	}
+
+	fromCurrency, isFromCurrencyFound := server.currencies.Get(fromAccount.Currency)
+	toCurrency, isToCurrencyFound := server.currencies.Get(toAccount.Currency)
+
+	if !isFromCurrencyFound || !isToCurrencyFound {
+		err := fmt.Errorf("could not find the currencies %s and %s", fromAccount.Currency, toAccount.Currency)
+		return http.StatusInternalServerError, err
+	}
+
+	creditedAmount, err := rate.Convert(arg.Amount, fromCurrency.Exponent, toCurrency.Exponent)
+
+	if err != nil {
+		if errors.Is(err, fx.ErrConversionOverflow) {
+			err := fmt.Errorf("Amount %s is too large to be converted to %s", fromCurrency.FormatAmount(arg.Amount), toCurrency.Code)
+			return http.StatusUnprocessableEntity, err
+		}
+
+		return http.StatusInternalServerError, err
+	}
+
+	if creditedAmount <= 0 {
+		err := fmt.Errorf("Amount %s is too small to be converted to %s", fromCurrency.FormatAmount(arg.Amount), toCurrency.Code)
+		return http.StatusUnprocessableEntity, err
+	}
+
+	arg.CreditedAmount = creditedAmount
+	arg.ExchangeRate = rate.Value
+	arg.ExchangeRateUpdatedAt = rate.UpdatedAt
+
+	return http.StatusOK, nil
+}
+
+type reverseTransferRequest struct {
+	params struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+	body struct {
+		// Amount is taken back from the to account, in its currency; the whole transfer is reversed when it's omitted
+		Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+	}
+}
+
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var req reverseTransferRequest
+
+	if err := ctx.ShouldBindUri(&req.params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the body is optional, as a full reversal doesn't need anything else
+	if err := ctx.ShouldBindJSON(&req.body); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	originalTransfer, err := server.store.GetTransfer(ctx, req.params.ID)
+
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return account, false
+			return
 		}
 
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return account, false
+		return
 	}
 
-	if account.Currency != currency {
-		err := fmt.Errorf("Account %d currency mismatch: %s should be %s", accountID, currency, account.Currency)
-		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
-		return account, false
+	toAccount, isValid := server.validateAccountTransfer(ctx, originalTransfer.ToAccountID)
+
+	if !isValid {
+		return
 	}
 
-	return account, true
+	if authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload); !hasAccountAccess(authPayload, toAccount, util.BankerRole, util.AdminRole) {
+		err := errors.New("transfer wasn't received by the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if _, isValid := server.validateAccountTransfer(ctx, originalTransfer.FromAccountID); !isValid {
+		return
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID: originalTransfer.ID,
+		Amount:     req.body.Amount,
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrTransferNotPosted), errors.Is(err, db.ErrReversalOfReversal), errors.Is(err, db.ErrReversalExceedsTransfer), errors.Is(err, db.ErrReversalTooSmall):
+			err := fmt.Errorf("Transfer %d can't be reversed: %w", originalTransfer.ID, err)
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrInsufficientFunds):
+			err := fmt.Errorf("Account %d has insufficient funds", originalTransfer.ToAccountID)
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, result)
+}
+
+type settleTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) captureTransfer(ctx *gin.Context) {
+	server.settleTransfer(ctx, true)
+}
+
+func (server *Server) voidTransfer(ctx *gin.Context) {
+	server.settleTransfer(ctx, false)
+}
+
+// settleTransfer captures or voids a pending transfer. As with transfers and reversals, it is up to the owner of the account
+// giving up the held money, so the from account captures it and the to account voids it, besides bankers and admins.
+// Voiding only releases the hold, so it is allowed on frozen accounts, which would otherwise keep the money held until it expires
+func (server *Server) settleTransfer(ctx *gin.Context, capture bool) {
+	var req settleTransferRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pendingTransfer, err := server.store.GetTransfer(ctx, req.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	accounts := make([]db.Account, 2)
+
+	for i, accountID := range []int64{pendingTransfer.FromAccountID, pendingTransfer.ToAccountID} {
+		accounts[i], err = server.store.GetAccount(ctx, accountID)
+
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.JSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	action, settlingAccount := "captured", accounts[0]
+
+	if !capture {
+		action, settlingAccount = "voided", accounts[1]
+	}
+
+	if authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload); !hasAccountAccess(authPayload, settlingAccount, util.BankerRole, util.AdminRole) {
+		err := fmt.Errorf("transfer can only be %s by the owner of account %d", action, settlingAccount.ID)
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	var result db.TransferTxResult
+
+	if capture {
+		for _, account := range accounts {
+			if account.IsFrozen {
+				err := fmt.Errorf("Account %d is frozen", account.ID)
+				ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+				return
+			}
+		}
+
+		result, err = server.store.CaptureTransferTx(ctx, db.CaptureTransferTxParams{TransferID: pendingTransfer.ID})
+	} else {
+		result, err = server.store.VoidTransferTx(ctx, db.VoidTransferTxParams{TransferID: pendingTransfer.ID})
+	}
+
+	if err != nil {
+		if errors.Is(err, db.ErrTransferNotPending) || errors.Is(err, db.ErrHoldExpired) {
+			err := fmt.Errorf("Transfer %d can't be %s: %w", pendingTransfer.ID, action, err)
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type getTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var req getTransferRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	foundTransfer, err := server.store.GetTransfer(ctx, req.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	hasAccess := false
+
+	for _, accountID := range []int64{foundTransfer.FromAccountID, foundTransfer.ToAccountID} {
+		account, err := server.store.GetAccount(ctx, accountID)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if hasAccountAccess(authPayload, account, util.BankerRole, util.AdminRole) {
+			hasAccess = true
+			break
+		}
+	}
+
+	if !hasAccess {
+		err := errors.New("transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, foundTransfer)
+}
+
+type listAccountTransfersRequest struct {
+	params struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+	query struct {
+		Page     int32 `form:"page" binding:"required,min=1"`
+		Quantity int32 `form:"quantity" binding:"max=200"`
+		// Direction is "in" for transfers received by the account and "out" for the ones it sent
+		Direction string `form:"direction" binding:"omitempty,oneof=in out"`
+		// Counterparty is the ID of the account on the other side of the transfers
+		Counterparty int64     `form:"counterparty" binding:"omitempty,min=1"`
+		StartDate    time.Time `form:"startDate"`
+		EndDate      time.Time `form:"endDate" binding:"omitempty,gtfield=StartDate"`
+		// MinAmount and MaxAmount are in the currency of the account, so received transfers are filtered by what they credited
+		MinAmount int64 `form:"minAmount" binding:"omitempty,min=1"`
+		MaxAmount int64 `form:"maxAmount" binding:"omitempty,gtefield=MinAmount"`
+	}
+}
+
+func (server *Server) listAccountTransfers(ctx *gin.Context) {
+	var req listAccountTransfersRequest
+
+	if err := ctx.ShouldBindUri(&req.params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.BindQuery(&req.query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.query.Quantity == 0 {
+		req.query.Quantity = 40
+	}
+
+	account, err := server.store.GetAccount(ctx, req.params.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload); !hasAccountAccess(authPayload, account, util.BankerRole, util.AdminRole) {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	transfers, err := server.store.ListTransfers(ctx, db.ListTransfersParams{
+		AccountID:             account.ID,
+		Direction:             sql.NullString{String: req.query.Direction, Valid: len(req.query.Direction) > 0},
+		CounterpartyAccountID: sql.NullInt64{Int64: req.query.Counterparty, Valid: req.query.Counterparty > 0},
+		StartDate:             sql.NullTime{Time: req.query.StartDate, Valid: !req.query.StartDate.IsZero()},
+		EndDate:               sql.NullTime{Time: req.query.EndDate, Valid: !req.query.EndDate.IsZero()},
+		MinAmount:             sql.NullInt64{Int64: req.query.MinAmount, Valid: req.query.MinAmount > 0},
+		MaxAmount:             sql.NullInt64{Int64: req.query.MaxAmount, Valid: req.query.MaxAmount > 0},
+		Limit:                 req.query.Quantity,
+		Offset:                (req.query.Page - 1) * req.query.Quantity,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Header("total", fmt.Sprint(len(transfers)))
+	ctx.JSON(http.StatusOK, transfers)
 }
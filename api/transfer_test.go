@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -12,9 +13,13 @@ import (
 
 	mockdb "github.com/Andrew-2609/simple-bank/db/mock"
 	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/fx"
+	mockfx "github.com/Andrew-2609/simple-bank/fx/mock"
 	"github.com/Andrew-2609/simple-bank/token"
 	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
@@ -113,6 +118,55 @@ func TestCreateTransferAPI(t *testing.T) {
 				require.Equal(t, expectedResult, unmarshallTransfer(t, recorder.Body))
 			},
 		},
+		{
+			name: "Forbidden - Missing Scope",
+			arg:  validArg,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithScopes(t, request, tokenMaker, authorizationTypeBearer, accounts[0].Owner, util.DepositorRole, []string{token.ScopeAccountsRead}, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "token is missing the transfers:create scope"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Created In Disabled Currency",
+			arg: CreateTransferRequest{
+				FromAccountID: accounts[0].ID,
+				ToAccountID:   accounts[1].ID,
+				Amount:        amount,
+				Currency:      util.GBP,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, accounts[0].Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				// accounts opened before their currency was disabled can still transfer money
+				gbpAccounts := accounts
+				gbpAccounts[0].Currency = util.GBP
+				gbpAccounts[1].Currency = util.GBP
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(gbpAccounts[0], nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(1).
+					Return(gbpAccounts[1], nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(expectedArg)).
+					Times(1).
+					Return(expectedResult, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
 		{
 			name: "Bad Request",
 			arg: CreateTransferRequest{
@@ -131,7 +185,32 @@ func TestCreateTransferAPI(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
-				require.Exactly(t, map[string]interface{}{"error": "Key: 'CreateTransferRequest.Currency' Error:Field validation for 'Currency' failed on the 'currency' tag"}, UnmarshallAny(t, recorder.Body))
+				require.Exactly(t, map[string]interface{}{"error": "currency AUD is not supported"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Frozen ToAccount",
+			arg:  validArg,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, accounts[0].Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				frozenAccount := accounts[1]
+				frozenAccount.IsFrozen = true
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(1).
+					Return(frozenAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("Account %d is frozen", accounts[1].ID)}, UnmarshallAny(t, recorder.Body))
 			},
 		},
 		{
@@ -251,6 +330,56 @@ func TestCreateTransferAPI(t *testing.T) {
 				require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
 			},
 		},
+		{
+			name: "Insufficient Funds",
+			arg:  validArg,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, accounts[0].Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(1).
+					Return(accounts[1], nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(expectedArg)).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("Account %d has insufficient funds", accounts[0].ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Credited Account Deleted",
+			arg:  validArg,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, accounts[0].Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(1).
+					Return(accounts[1], nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(expectedArg)).
+					Times(1).
+					Return(db.TransferTxResult{}, fmt.Errorf("account %d: %w", accounts[1].ID, sql.ErrNoRows))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("account %d: %s", accounts[1].ID, sql.ErrNoRows)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
 		{
 			name: "Internal Server Error",
 			arg:  validArg,
@@ -285,6 +414,8 @@ func TestCreateTransferAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			testCase.buildStubs(store)
+			stubAuthChecks(store)
+			stubVerifiedEmail(store)
 
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -308,3 +439,1788 @@ func TestCreateTransferAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateTransferIdempotencyAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+	var amount int64 = 5000
+
+	validArg := CreateTransferRequest{
+		FromAccountID: accounts[0].ID,
+		ToAccountID:   accounts[1].ID,
+		Amount:        amount,
+		Currency:      "BRL",
+	}
+
+	idempotencyKey := util.RandomString(32)
+
+	requestHash, err := util.HashRequest(validArg)
+	require.NoError(t, err)
+
+	expectedArg := db.TransferTxParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID:   accounts[1].ID,
+		Amount:        amount,
+		IdempotencyKey: &db.TransferIdempotencyKey{
+			Username:    accounts[0].Owner,
+			Key:         idempotencyKey,
+			RequestHash: requestHash,
+		},
+	}
+
+	expectedResult := db.TransferTxResult{
+		Transfer: db.Transfer{
+			ID:            1,
+			FromAccountID: accounts[0].ID,
+			ToAccountID:   accounts[1].ID,
+			Amount:        amount,
+		},
+		FromAccount: accounts[0],
+		ToAccount:   accounts[1],
+		FromEntry: db.Entry{
+			ID:        1,
+			AccountID: accounts[0].ID,
+			Amount:    -amount,
+		},
+		ToEntry: db.Entry{
+			ID:        2,
+			AccountID: accounts[1].ID,
+			Amount:    amount,
+		},
+	}
+
+	response, err := json.Marshal(expectedResult)
+	require.NoError(t, err)
+
+	storedKey := db.IdempotencyKey{
+		Username:    accounts[0].Owner,
+		Key:         idempotencyKey,
+		RequestHash: requestHash,
+		Response:    response,
+	}
+
+	getKeyArg := db.GetIdempotencyKeyParams{Username: accounts[0].Owner, Key: idempotencyKey}
+
+	testCases := []struct {
+		name          string
+		key           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "First Request",
+			key:  idempotencyKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(getKeyArg)).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(1).
+					Return(accounts[1], nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(expectedArg)).
+					Times(1).
+					Return(expectedResult, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Empty(t, recorder.Header().Get(idempotentReplayedHeader))
+				require.Exactly(t, expectedResult, unmarshallTransfer(t, recorder.Body))
+			},
+		},
+		{
+			name: "Replayed",
+			key:  idempotencyKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(getKeyArg)).
+					Times(1).
+					Return(storedKey, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+				require.Exactly(t, expectedResult, unmarshallTransfer(t, recorder.Body))
+			},
+		},
+		{
+			name: "Reused For A Different Request",
+			key:  idempotencyKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				otherKey := storedKey
+				otherKey.RequestHash = util.RandomString(64)
+
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(getKeyArg)).
+					Times(1).
+					Return(otherKey, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "idempotency key was already used for a different request"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Concurrent Request",
+			key:  idempotencyKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(getKeyArg)).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(2).
+					DoAndReturn(func(_ any, id int64) (db.Account, error) {
+						if id == accounts[0].ID {
+							return accounts[0], nil
+						}
+
+						return accounts[1], nil
+					})
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(expectedArg)).
+					Times(1).
+					Return(db.TransferTxResult{}, &pq.Error{Code: pq.ErrorCode("23505"), Constraint: "idempotency_keys_pkey"})
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(getKeyArg)).
+					Times(1).
+					Return(storedKey, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+				require.Exactly(t, expectedResult, unmarshallTransfer(t, recorder.Body))
+			},
+		},
+		{
+			name: "Key Too Long",
+			key:  util.RandomString(256),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "Idempotency-Key header must have at most 255 characters"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Internal Server Error",
+			key:  idempotencyKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+			stubVerifiedEmail(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var buf bytes.Buffer
+
+			err := json.NewEncoder(&buf).Encode(validArg)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest("POST", "/transfers", &buf)
+			require.NoError(t, err)
+
+			request.Header.Set(idempotencyKeyHeader, testCase.key)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, accounts[0].Owner, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateCrossCurrencyTransferAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+	accounts[1].Currency = util.USD
+
+	var amount int64 = 5000
+
+	validArg := CreateTransferRequest{
+		FromAccountID: accounts[0].ID,
+		ToAccountID:   accounts[1].ID,
+		Amount:        amount,
+		Currency:      util.BRL,
+	}
+
+	rate := fx.Rate{From: util.BRL, To: util.USD, Value: 0.2, UpdatedAt: time.Now().Add(-time.Minute)}
+
+	expectedArg := db.TransferTxParams{
+		FromAccountID:         accounts[0].ID,
+		ToAccountID:           accounts[1].ID,
+		Amount:                amount,
+		CreditedAmount:        1000,
+		ExchangeRate:          rate.Value,
+		ExchangeRateUpdatedAt: rate.UpdatedAt,
+	}
+
+	stubAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+			Times(1).
+			Return(accounts[0], nil)
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+			Times(1).
+			Return(accounts[1], nil)
+	}
+
+	testCases := []struct {
+		name          string
+		arg           CreateTransferRequest
+		buildStubs    func(store *mockdb.MockStore, rateProvider *mockfx.MockRateProvider)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Created",
+			arg:  validArg,
+			buildStubs: func(store *mockdb.MockStore, rateProvider *mockfx.MockRateProvider) {
+				stubAccounts(store)
+				rateProvider.EXPECT().
+					Rate(gomock.Eq(util.BRL), gomock.Eq(util.USD)).
+					Times(1).
+					Return(rate, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(expectedArg)).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer: db.Transfer{
+							ID:                    1,
+							FromAccountID:         accounts[0].ID,
+							ToAccountID:           accounts[1].ID,
+							Amount:                amount,
+							CreditedAmount:        expectedArg.CreditedAmount,
+							ExchangeRate:          rate.Value,
+							ExchangeRateUpdatedAt: rate.UpdatedAt,
+						},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				transfer := unmarshallTransfer(t, recorder.Body).Transfer
+				require.Equal(t, amount, transfer.Amount)
+				require.Equal(t, expectedArg.CreditedAmount, transfer.CreditedAmount)
+				require.Equal(t, rate.Value, transfer.ExchangeRate)
+				require.WithinDuration(t, rate.UpdatedAt, transfer.ExchangeRateUpdatedAt, time.Second)
+			},
+		},
+		{
+			name: "Created - Different Minor Units",
+			arg:  validArg,
+			buildStubs: func(store *mockdb.MockStore, rateProvider *mockfx.MockRateProvider) {
+				jpyAccount := accounts[1]
+				jpyAccount.Currency = util.JPY
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(1).
+					Return(jpyAccount, nil)
+				rateProvider.EXPECT().
+					Rate(gomock.Eq(util.BRL), gomock.Eq(util.JPY)).
+					Times(1).
+					Return(fx.Rate{From: util.BRL, To: util.JPY, Value: 30, UpdatedAt: rate.UpdatedAt}, nil)
+
+				// 50.00 BRL are credited as 1500 JPY, which has no minor unit
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID:         accounts[0].ID,
+						ToAccountID:           accounts[1].ID,
+						Amount:                amount,
+						CreditedAmount:        1500,
+						ExchangeRate:          30,
+						ExchangeRateUpdatedAt: rate.UpdatedAt,
+					})).
+					Times(1).
+					Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "Rate Not Found",
+			arg:  validArg,
+			buildStubs: func(store *mockdb.MockStore, rateProvider *mockfx.MockRateProvider) {
+				stubAccounts(store)
+				rateProvider.EXPECT().
+					Rate(gomock.Eq(util.BRL), gomock.Eq(util.USD)).
+					Times(1).
+					Return(fx.Rate{}, fmt.Errorf("%w from BRL to USD", fx.ErrRateNotFound))
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "exchange rate not found from BRL to USD"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Amount Too Small",
+			arg: CreateTransferRequest{
+				FromAccountID: accounts[0].ID,
+				ToAccountID:   accounts[1].ID,
+				Amount:        2,
+				Currency:      util.BRL,
+			},
+			buildStubs: func(store *mockdb.MockStore, rateProvider *mockfx.MockRateProvider) {
+				stubAccounts(store)
+				rateProvider.EXPECT().
+					Rate(gomock.Eq(util.BRL), gomock.Eq(util.USD)).
+					Times(1).
+					Return(rate, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "Amount R$0.02 is too small to be converted to USD"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Amount Too Large",
+			arg: CreateTransferRequest{
+				FromAccountID: accounts[0].ID,
+				ToAccountID:   accounts[1].ID,
+				Amount:        1_000_000_000_000,
+				Currency:      util.BRL,
+			},
+			buildStubs: func(store *mockdb.MockStore, rateProvider *mockfx.MockRateProvider) {
+				stubAccounts(store)
+				largeRate := rate
+				largeRate.Value = 1e9
+				rateProvider.EXPECT().
+					Rate(gomock.Eq(util.BRL), gomock.Eq(util.USD)).
+					Times(1).
+					Return(largeRate, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "Amount R$10000000000.00 is too large to be converted to USD"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Rate Provider Error",
+			arg:  validArg,
+			buildStubs: func(store *mockdb.MockStore, rateProvider *mockfx.MockRateProvider) {
+				stubAccounts(store)
+				rateProvider.EXPECT().
+					Rate(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(fx.Rate{}, errors.New("rate provider is down"))
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "rate provider is down"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			rateProvider := mockfx.NewMockRateProvider(ctrl)
+			testCase.buildStubs(store, rateProvider)
+			stubAuthChecks(store)
+			stubVerifiedEmail(store)
+
+			server := newTestServer(t, store)
+			server.rateProvider = rateProvider
+			recorder := httptest.NewRecorder()
+
+			var buf bytes.Buffer
+
+			err := json.NewEncoder(&buf).Encode(testCase.arg)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest("POST", "/transfers", &buf)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, accounts[0].Owner, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReverseTransferAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+
+	originalTransfer := db.Transfer{
+		ID:             util.RandomInt(1, 1000),
+		FromAccountID:  accounts[0].ID,
+		ToAccountID:    accounts[1].ID,
+		Amount:         5000,
+		CreditedAmount: 5000,
+		ExchangeRate:   1,
+	}
+
+	frozenAccount := accounts[1]
+	frozenAccount.IsFrozen = true
+
+	stubAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetTransfer(gomock.Any(), gomock.Eq(originalTransfer.ID)).
+			Times(1).
+			Return(originalTransfer, nil)
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+			Times(1).
+			Return(accounts[1], nil)
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+			Times(1).
+			Return(accounts[0], nil)
+	}
+
+	testCases := []struct {
+		name          string
+		body          any
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Created - Full Reversal",
+			username: accounts[1].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Eq(db.ReverseTransferTxParams{TransferID: originalTransfer.ID})).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer: db.Transfer{
+							ID:                 originalTransfer.ID + 1,
+							FromAccountID:      accounts[1].ID,
+							ToAccountID:        accounts[0].ID,
+							Amount:             originalTransfer.Amount,
+							CreditedAmount:     originalTransfer.Amount,
+							ExchangeRate:       1,
+							ReversesTransferID: sql.NullInt64{Int64: originalTransfer.ID, Valid: true},
+						},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				transfer := unmarshallTransfer(t, recorder.Body).Transfer
+				require.Equal(t, accounts[1].ID, transfer.FromAccountID)
+				require.Equal(t, originalTransfer.ID, transfer.ReversesTransferID.Int64)
+			},
+		},
+		{
+			name:     "Created - Partial Reversal By Banker",
+			body:     gin.H{"amount": 1000},
+			username: "banker",
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Eq(db.ReverseTransferTxParams{TransferID: originalTransfer.ID, Amount: 1000})).
+					Times(1).
+					Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:     "Bad Request - Invalid Amount",
+			body:     gin.H{"amount": -1},
+			username: accounts[1].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "Not Found",
+			username: accounts[1].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(originalTransfer.ID)).
+					Times(1).
+					Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "Unauthorized - Sender",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(originalTransfer.ID)).
+					Times(1).
+					Return(originalTransfer, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(1).
+					Return(accounts[1], nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "transfer wasn't received by the authenticated user"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Unprocessable Entity - Frozen Account",
+			username: accounts[1].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(originalTransfer.ID)).
+					Times(1).
+					Return(originalTransfer, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(1).
+					Return(frozenAccount, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("Account %d is frozen", accounts[1].ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Unprocessable Entity - Exceeds Transfer",
+			body:     gin.H{"amount": 5001},
+			username: accounts[1].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrReversalExceedsTransfer)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("Transfer %d can't be reversed: reversal exceeds the amount left to reverse", originalTransfer.ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Unprocessable Entity - Insufficient Funds",
+			username: accounts[1].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("Account %d has insufficient funds", accounts[1].ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Internal Server Error",
+			username: accounts[1].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+			stubVerifiedEmail(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var buf bytes.Buffer
+
+			if testCase.body != nil {
+				err := json.NewEncoder(&buf).Encode(testCase.body)
+				require.NoError(t, err)
+			}
+
+			url := fmt.Sprintf("/transfers/%d/reverse", originalTransfer.ID)
+
+			request, err := http.NewRequest("POST", url, &buf)
+			require.NoError(t, err)
+
+			addAuthorizationWithRole(t, request, server.tokenMaker, authorizationTypeBearer, testCase.username, testCase.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuthorizeTransferAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+	var amount int64 = 5000
+
+	validArg := CreateTransferRequest{
+		FromAccountID: accounts[0].ID,
+		ToAccountID:   accounts[1].ID,
+		Amount:        amount,
+		Currency:      "BRL",
+	}
+
+	heldAccount := accounts[0]
+	heldAccount.AvailableBalance = heldAccount.Balance - amount
+
+	expectedResult := db.TransferTxResult{
+		Transfer: db.Transfer{
+			ID:             1,
+			FromAccountID:  accounts[0].ID,
+			ToAccountID:    accounts[1].ID,
+			Amount:         amount,
+			CreditedAmount: amount,
+			ExchangeRate:   1,
+			Status:         util.TransferStatusPending,
+		},
+		FromAccount: heldAccount,
+		ToAccount:   accounts[1],
+	}
+
+	stubAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+			Times(1).
+			Return(accounts[0], nil)
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+			Times(1).
+			Return(accounts[1], nil)
+	}
+
+	testCases := []struct {
+		name          string
+		mode          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Created",
+			mode: "authorize",
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					AuthorizeTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.AuthorizeTransferTxParams) (db.TransferTxResult, error) {
+						require.Equal(t, db.TransferTxParams{
+							FromAccountID: accounts[0].ID,
+							ToAccountID:   accounts[1].ID,
+							Amount:        amount,
+						}, arg.TransferTxParams)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.HoldExpiresAt, time.Second)
+
+						return expectedResult, nil
+					})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Equal(t, expectedResult, unmarshallTransfer(t, recorder.Body))
+			},
+		},
+		{
+			name: "Created - Post Mode",
+			mode: "post",
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, nil)
+				store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "Bad Request - Invalid Mode",
+			mode: "hold",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Insufficient Funds",
+			mode: "authorize",
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					AuthorizeTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("Account %d has insufficient funds", accounts[0].ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Internal Server Error",
+			mode: "authorize",
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					AuthorizeTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, sql.ErrTxDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+			stubVerifiedEmail(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers?mode=%s", testCase.mode)
+
+			var buf bytes.Buffer
+
+			err := json.NewEncoder(&buf).Encode(validArg)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest("POST", url, &buf)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, accounts[0].Owner, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCaptureTransferAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+
+	pendingTransfer := db.Transfer{
+		ID:             util.RandomInt(1, 1000),
+		FromAccountID:  accounts[0].ID,
+		ToAccountID:    accounts[1].ID,
+		Amount:         5000,
+		CreditedAmount: 5000,
+		ExchangeRate:   1,
+		Status:         util.TransferStatusPending,
+		HoldExpiresAt:  sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	postedTransfer := pendingTransfer
+	postedTransfer.Status = util.TransferStatusPosted
+	postedTransfer.HoldExpiresAt = sql.NullTime{}
+
+	frozenAccount := accounts[1]
+	frozenAccount.IsFrozen = true
+
+	stubAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).
+			Times(1).
+			Return(pendingTransfer, nil)
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+			Times(1).
+			Return(accounts[0], nil)
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+			Times(1).
+			Return(accounts[1], nil)
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK - Sender",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					CaptureTransferTx(gomock.Any(), gomock.Eq(db.CaptureTransferTxParams{TransferID: pendingTransfer.ID})).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: postedTransfer}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, util.TransferStatusPosted, unmarshallTransfer(t, recorder.Body).Transfer.Status)
+			},
+		},
+		{
+			name:     "Unauthorized - Receiver",
+			username: accounts[1].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("transfer can only be captured by the owner of account %d", accounts[0].ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "OK - Banker",
+			username: "banker",
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					CaptureTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: postedTransfer}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Not Found",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).
+					Times(1).
+					Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "Unauthorized - Other User",
+			username: "other",
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("transfer can only be captured by the owner of account %d", accounts[0].ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Unprocessable Entity - Frozen Account",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).
+					Times(1).
+					Return(pendingTransfer, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(1).
+					Return(frozenAccount, nil)
+				store.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("Account %d is frozen", accounts[1].ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Unprocessable Entity - Not Pending",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					CaptureTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrTransferNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("Transfer %d can't be captured: transfer isn't pending", pendingTransfer.ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Unprocessable Entity - Hold Expired",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					CaptureTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrHoldExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("Transfer %d can't be captured: hold expired", pendingTransfer.ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Internal Server Error",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					CaptureTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+			stubVerifiedEmail(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers/%d/capture", pendingTransfer.ID)
+
+			request, err := http.NewRequest("POST", url, nil)
+			require.NoError(t, err)
+
+			addAuthorizationWithRole(t, request, server.tokenMaker, authorizationTypeBearer, testCase.username, testCase.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVoidTransferAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+
+	pendingTransfer := db.Transfer{
+		ID:             util.RandomInt(1, 1000),
+		FromAccountID:  accounts[0].ID,
+		ToAccountID:    accounts[1].ID,
+		Amount:         5000,
+		CreditedAmount: 5000,
+		ExchangeRate:   1,
+		Status:         util.TransferStatusPending,
+		HoldExpiresAt:  sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	voidedTransfer := pendingTransfer
+	voidedTransfer.Status = util.TransferStatusVoided
+	voidedTransfer.HoldExpiresAt = sql.NullTime{}
+
+	frozenAccounts := []db.Account{accounts[0], accounts[1]}
+	frozenAccounts[0].IsFrozen = true
+	frozenAccounts[1].IsFrozen = true
+
+	stubAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).
+			Times(1).
+			Return(pendingTransfer, nil)
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+			Times(1).
+			Return(accounts[0], nil)
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+			Times(1).
+			Return(accounts[1], nil)
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: accounts[1].Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					VoidTransferTx(gomock.Any(), gomock.Eq(db.VoidTransferTxParams{TransferID: pendingTransfer.ID})).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: voidedTransfer, FromAccount: accounts[0]}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				result := unmarshallTransfer(t, recorder.Body)
+				require.Equal(t, util.TransferStatusVoided, result.Transfer.Status)
+				require.False(t, result.Transfer.HoldExpiresAt.Valid)
+			},
+		},
+		{
+			name:     "OK - Frozen Accounts",
+			username: accounts[1].Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).
+					Times(1).
+					Return(pendingTransfer, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(frozenAccounts[0], nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(1).
+					Return(frozenAccounts[1], nil)
+				store.EXPECT().
+					VoidTransferTx(gomock.Any(), gomock.Eq(db.VoidTransferTxParams{TransferID: pendingTransfer.ID})).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: voidedTransfer, FromAccount: frozenAccounts[0]}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, util.TransferStatusVoided, unmarshallTransfer(t, recorder.Body).Transfer.Status)
+			},
+		},
+		{
+			name:     "Unauthorized - Sender",
+			username: accounts[0].Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().VoidTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("transfer can only be voided by the owner of account %d", accounts[1].ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Unauthorized - Other User",
+			username: "other",
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().VoidTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "Unprocessable Entity - Not Pending",
+			username: accounts[1].Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					VoidTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrTransferNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("Transfer %d can't be voided: transfer isn't pending", pendingTransfer.ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+			stubVerifiedEmail(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers/%d/void", pendingTransfer.ID)
+
+			request, err := http.NewRequest("POST", url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, testCase.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateBatchTransferAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+
+	otherAccount := db.Account{
+		ID:       accounts[1].ID + 1000,
+		Owner:    accounts[1].Owner,
+		Currency: util.BRL,
+	}
+
+	frozenAccount := otherAccount
+	frozenAccount.IsFrozen = true
+
+	validArg := gin.H{
+		"transfers": []CreateTransferRequest{
+			{FromAccountID: accounts[0].ID, ToAccountID: accounts[1].ID, Amount: 100, Currency: util.BRL},
+			{FromAccountID: accounts[0].ID, ToAccountID: otherAccount.ID, Amount: 200, Currency: util.BRL},
+		},
+	}
+
+	expectedArg := db.BatchTransferTxParams{
+		Transfers: []db.TransferTxParams{
+			{FromAccountID: accounts[0].ID, ToAccountID: accounts[1].ID, Amount: 100},
+			{FromAccountID: accounts[0].ID, ToAccountID: otherAccount.ID, Amount: 200},
+		},
+	}
+
+	stubAccounts := func(store *mockdb.MockStore, toAccount db.Account) {
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+			Times(2).
+			Return(accounts[0], nil)
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+			Times(1).
+			Return(accounts[1], nil)
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).
+			Times(1).
+			Return(toAccount, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Created",
+			body: validArg,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store, otherAccount)
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Eq(expectedArg)).
+					Times(1).
+					Return(db.BatchTransferTxResult{
+						Transfers: []db.TransferTxResult{
+							{Transfer: db.Transfer{ID: 1, Amount: 100}},
+							{Transfer: db.Transfer{ID: 2, Amount: 200}},
+						},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				result, err := util.UnmarshallJsonBody[db.BatchTransferTxResult](recorder.Body)
+				require.NoError(t, err)
+				require.Len(t, result.Transfers, 2)
+				require.Equal(t, int64(1), result.Transfers[0].Transfer.ID)
+				require.Equal(t, int64(2), result.Transfers[1].Transfer.ID)
+			},
+		},
+		{
+			name: "Bad Request - Empty Batch",
+			body: gin.H{"transfers": []CreateTransferRequest{}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Bad Request - Invalid Transfer",
+			body: gin.H{
+				"transfers": []CreateTransferRequest{
+					{FromAccountID: accounts[0].ID, ToAccountID: accounts[1].ID, Amount: 100, Currency: util.BRL},
+					{FromAccountID: accounts[0].ID, ToAccountID: accounts[1].ID, Amount: 0, Currency: util.BRL},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Unauthorized - From Account Of Another User",
+			body: gin.H{
+				"transfers": []CreateTransferRequest{
+					{FromAccountID: accounts[0].ID, ToAccountID: accounts[1].ID, Amount: 100, Currency: util.BRL},
+					{FromAccountID: accounts[1].ID, ToAccountID: accounts[0].ID, Amount: 100, Currency: util.BRL},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(2).
+					Return(accounts[1], nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "transfers[1]: from account doesn't belong to the authenticated user"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Unprocessable Entity - Frozen Account",
+			body: validArg,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store, frozenAccount)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("transfers[1]: Account %d is frozen", otherAccount.ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Unprocessable Entity - Insufficient Funds",
+			body: validArg,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store, otherAccount)
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Eq(expectedArg)).
+					Times(1).
+					Return(db.BatchTransferTxResult{}, &db.BatchTransferError{Index: 1, Err: db.ErrInsufficientFunds})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": fmt.Sprintf("transfers[1]: Account %d has insufficient funds", accounts[0].ID)}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Internal Server Error",
+			body: validArg,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store, otherAccount)
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.BatchTransferTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+			stubVerifiedEmail(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var buf bytes.Buffer
+
+			err := json.NewEncoder(&buf).Encode(testCase.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest("POST", "/transfers/batch", &buf)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, accounts[0].Owner, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetTransferAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+
+	transfer := db.Transfer{
+		ID:             util.RandomInt(1, 1000),
+		FromAccountID:  accounts[0].ID,
+		ToAccountID:    accounts[1].ID,
+		Amount:         5000,
+		CreditedAmount: 5000,
+		ExchangeRate:   1,
+		Status:         util.TransferStatusPosted,
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK - Sender",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				foundTransfer, err := util.UnmarshallJsonBody[db.Transfer](recorder.Body)
+				require.NoError(t, err)
+				require.Equal(t, transfer, foundTransfer)
+			},
+		},
+		{
+			name:     "OK - Receiver",
+			username: accounts[1].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(1).
+					Return(accounts[1], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "OK - Banker",
+			username: "banker",
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Unauthorized - Other User",
+			username: "other",
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[1].ID)).
+					Times(1).
+					Return(accounts[1], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "transfer doesn't belong to the authenticated user"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Not Found",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "Internal Server Error",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers/%d", transfer.ID)
+
+			request, err := http.NewRequest("GET", url, nil)
+			require.NoError(t, err)
+
+			addAuthorizationWithRole(t, request, server.tokenMaker, authorizationTypeBearer, testCase.username, testCase.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAccountTransfersAPI(t *testing.T) {
+	accounts := createRandomAccounts()
+
+	transfers := []db.Transfer{
+		{ID: 1, FromAccountID: accounts[0].ID, ToAccountID: accounts[1].ID, Amount: 100, CreditedAmount: 100, ExchangeRate: 1},
+		{ID: 2, FromAccountID: accounts[1].ID, ToAccountID: accounts[0].ID, Amount: 200, CreditedAmount: 200, ExchangeRate: 1},
+	}
+
+	startDate := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 1, 0)
+
+	testCases := []struct {
+		name          string
+		query         string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			query:    "page=1",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Eq(db.ListTransfersParams{
+						AccountID: accounts[0].ID,
+						Limit:     40,
+						Offset:    0,
+					})).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "2", recorder.Header().Get("total"))
+
+				foundTransfers, err := util.UnmarshallJsonBody[[]db.Transfer](recorder.Body)
+				require.NoError(t, err)
+				require.Equal(t, transfers, foundTransfers)
+			},
+		},
+		{
+			name:     "OK - Filters",
+			query:    fmt.Sprintf("page=2&quantity=10&direction=in&counterparty=%d&startDate=%s&endDate=%s&minAmount=100&maxAmount=500", accounts[1].ID, startDate.Format(time.RFC3339), endDate.Format(time.RFC3339)),
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Eq(db.ListTransfersParams{
+						AccountID:             accounts[0].ID,
+						Direction:             sql.NullString{String: "in", Valid: true},
+						CounterpartyAccountID: sql.NullInt64{Int64: accounts[1].ID, Valid: true},
+						StartDate:             sql.NullTime{Time: startDate, Valid: true},
+						EndDate:               sql.NullTime{Time: endDate, Valid: true},
+						MinAmount:             sql.NullInt64{Int64: 100, Valid: true},
+						MaxAmount:             sql.NullInt64{Int64: 500, Valid: true},
+						Limit:                 10,
+						Offset:                10,
+					})).
+					Times(1).
+					Return(transfers[1:], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "1", recorder.Header().Get("total"))
+			},
+		},
+		{
+			name:     "OK - Banker",
+			query:    "page=1",
+			username: "banker",
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Bad Request - Invalid Direction",
+			query:    "page=1&direction=sideways",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "Bad Request - Amount Range",
+			query:    "page=1&minAmount=500&maxAmount=100",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "Bad Request - Date Range",
+			query:    fmt.Sprintf("page=1&startDate=%s&endDate=%s", endDate.Format(time.RFC3339), startDate.Format(time.RFC3339)),
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "Unauthorized - Other User",
+			query:    "page=1",
+			username: accounts[1].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "account doesn't belong to the authenticated user"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Not Found",
+			query:    "page=1",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "Internal Server Error",
+			query:    "page=1",
+			username: accounts[0].Owner,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accounts[0].ID)).
+					Times(1).
+					Return(accounts[0], nil)
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/transfers?%s", accounts[0].ID, testCase.query)
+
+			request, err := http.NewRequest("GET", url, nil)
+			require.NoError(t, err)
+
+			addAuthorizationWithRole(t, request, server.tokenMaker, authorizationTypeBearer, testCase.username, testCase.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
@@ -2,18 +2,21 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"time"
 
 	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/token"
 	"github.com/Andrew-2609/simple-bank/util"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
 type CreateUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum"`
-	Password string `json:"password" binding:"required,min=8"`
+	Password string `json:"password" binding:"required"`
 	Name     string `json:"name" binding:"required"`
 	LastName string `json:"last_name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
@@ -24,6 +27,8 @@ type UserResponse struct {
 	Name              string    `json:"name"`
 	LastName          string    `json:"last_name"`
 	Email             string    `json:"email"`
+	Role              string    `json:"role"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -34,11 +39,31 @@ func formatUserResponse(dbUser db.User) UserResponse {
 		Name:              dbUser.Name,
 		LastName:          dbUser.LastName,
 		Email:             dbUser.Email,
+		Role:              dbUser.Role,
+		IsEmailVerified:   dbUser.IsEmailVerified,
 		PasswordChangedAt: dbUser.PasswordChangedAt,
 		CreatedAt:         dbUser.CreatedAt,
 	}
 }
 
+// checkPasswordPolicy responds with every rule of the password policy the new password of the user fails to follow.
+func (server *Server) checkPasswordPolicy(ctx *gin.Context, password string, username string, email string) error {
+	err := server.passwordPolicy.Validate(password, username, email)
+
+	var policyErr *util.PasswordPolicyError
+
+	if errors.As(err, &policyErr) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "violations": policyErr.Violations})
+		return err
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+
+	return err
+}
+
 func (server *Server) createUser(ctx *gin.Context) {
 	var req CreateUserRequest
 
@@ -47,22 +72,40 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
-	hashedPassword, err := util.HashPassword(req.Password)
+	if err := server.checkPasswordPolicy(ctx, req.Password, req.Username, req.Email); err != nil {
+		return
+	}
+
+	hashedPassword, err := server.passwordHasher.Hash(req.Password)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	verificationCode, err := util.GenerateSecretCode()
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	arg := db.CreateUserParams{
-		Username:       req.Username,
-		HashedPassword: hashedPassword,
-		Name:           req.Name,
-		LastName:       req.LastName,
-		Email:          req.Email,
+	arg := db.CreateUserTxParams{
+		CreateUserParams: db.CreateUserParams{
+			Username:       req.Username,
+			HashedPassword: hashedPassword,
+			Name:           req.Name,
+			LastName:       req.LastName,
+			Email:          req.Email,
+		},
+		HashedVerificationCode: util.HashSecretCode(verificationCode),
+		VerificationExpiresAt:  time.Now().Add(server.config.EmailVerificationDuration),
+		AfterCreate: func(user db.User, verifyEmail db.VerifyEmail) error {
+			return server.sendVerifyEmail(user, verifyEmail, verificationCode)
+		},
 	}
 
-	newUser, err := server.store.CreateUser(ctx, arg)
+	result, err := server.store.CreateUserTx(ctx, arg)
 
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
@@ -77,17 +120,23 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, formatUserResponse(newUser))
+	ctx.JSON(http.StatusCreated, formatUserResponse(result.User))
 }
 
 type LoginRequest struct {
-	Username string `json:"username" binding:"required,alphanum"`
-	Password string `json:"password" binding:"required,min=8"`
+	Username string   `json:"username" binding:"required,alphanum"`
+	Password string   `json:"password" binding:"required,min=8"`
+	Scopes   []string `json:"scopes" binding:"omitempty,dive,scope"`
 }
 
 type LoginResponse struct {
-	AccessToken string       `json:"access_token"`
-	User        UserResponse `json:"user"`
+	SessionID             uuid.UUID    `json:"session_id"`
+	AccessToken           string       `json:"access_token"`
+	AccessTokenExpiresAt  time.Time    `json:"access_token_expires_at"`
+	RefreshToken          string       `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time    `json:"refresh_token_expires_at"`
+	Scopes                []string     `json:"scopes"`
+	User                  UserResponse `json:"user"`
 }
 
 func (server *Server) login(ctx *gin.Context) {
@@ -98,11 +147,16 @@ func (server *Server) login(ctx *gin.Context) {
 		return
 	}
 
+	if err := server.checkLoginThrottle(ctx, req.Username); err != nil {
+		return
+	}
+
 	foundUser, err := server.store.GetUser(ctx, req.Username)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			util.CheckPassword(server.dummyPasswordHash, req.Password)
+			server.rejectLogin(ctx, req.Username)
 			return
 		}
 
@@ -111,11 +165,86 @@ func (server *Server) login(ctx *gin.Context) {
 	}
 
 	if err = util.CheckPassword(foundUser.HashedPassword, req.Password); err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		server.rejectLogin(ctx, req.Username)
+		return
+	}
+
+	server.rehashPassword(ctx, foundUser, req.Password)
+
+	scopes := req.Scopes
+
+	if len(scopes) == 0 {
+		scopes = token.AllScopes
+	}
+
+	totpSecret, err := server.store.GetTOTPSecret(ctx, foundUser.Username)
+
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err == nil && totpSecret.ConfirmedAt.Valid {
+		server.createMFAChallenge(ctx, foundUser, scopes)
+		return
+	}
+
+	server.createLoginSession(ctx, foundUser, scopes)
+}
+
+// rehashPassword hashes the password again if it was hashed with an outdated algorithm or cost.
+// It doesn't change password_changed_at, so tokens of the user remain valid,
+// and failures are only logged, since the user has already been authenticated.
+func (server *Server) rehashPassword(ctx *gin.Context, user db.User, password string) {
+	if !server.passwordHasher.NeedsRehash(user.HashedPassword) {
+		return
+	}
+
+	hashedPassword, err := server.passwordHasher.Hash(password)
+
+	if err == nil {
+		err = server.store.RehashUserPassword(ctx, db.RehashUserPasswordParams{
+			HashedPassword:    hashedPassword,
+			Username:          user.Username,
+			OldHashedPassword: user.HashedPassword,
+		})
+	}
+
+	if err != nil {
+		ctx.Error(err)
+	}
+}
+
+// createLoginSession creates a session for the authenticated user and responds with its access and refresh tokens.
+// The login only counts as successful here, after the mfa challenge when the user has it enabled.
+func (server *Server) createLoginSession(ctx *gin.Context, loggedUser db.User, scopes []string) {
+	if err := server.recordLoginSuccess(ctx, loggedUser.Username); err != nil {
+		return
+	}
+
+	refreshToken, refreshPayload, err := server.createToken(loggedUser.Username, loggedUser.Role, scopes, uuid.Nil, token.PurposeRefresh, server.config.RefreshTokenDuration)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	accessToken, accessPayload, err := server.createToken(loggedUser.Username, loggedUser.Role, scopes, refreshPayload.ID, "", server.config.AccessTokenDuration)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	token, err := server.tokenMaker.CreateToken(req.Username, server.config.AccessTokenDuration)
+	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		Username:     refreshPayload.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    ctx.Request.UserAgent(),
+		ClientIp:     ctx.ClientIP(),
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+	})
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -123,7 +252,264 @@ func (server *Server) login(ctx *gin.Context) {
 	}
 
 	ctx.JSON(http.StatusOK, LoginResponse{
-		AccessToken: token,
-		User:        formatUserResponse(foundUser),
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+		Scopes:                scopes,
+		User:                  formatUserResponse(loggedUser),
+	})
+}
+
+func (server *Server) logout(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	err := server.store.RevokeSessionTx(ctx, db.RevokeSessionTxParams{
+		Username:       authPayload.Username,
+		TokenID:        authPayload.ID,
+		TokenExpiresAt: authPayload.ExpiredAt,
+		SessionID:      authPayload.SessionID,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+func (server *Server) logoutAll(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	err := server.store.RevokeAllSessionsTx(ctx, db.RevokeAllSessionsTxParams{
+		Username:       authPayload.Username,
+		TokenID:        authPayload.ID,
+		TokenExpiresAt: authPayload.ExpiredAt,
 	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required,min=8"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+func (server *Server) changePassword(ctx *gin.Context) {
+	var req ChangePasswordRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	foundUser, err := server.store.GetUser(ctx, authPayload.Username)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err = util.CheckPassword(foundUser.HashedPassword, req.OldPassword); err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if err := server.checkPasswordPolicy(ctx, req.NewPassword, foundUser.Username, foundUser.Email); err != nil {
+		return
+	}
+
+	hashedPassword, err := server.passwordHasher.Hash(req.NewPassword)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	updatedUser, err := server.store.ChangePasswordTx(ctx, db.UpdateUserPasswordParams{
+		Username:          foundUser.Username,
+		HashedPassword:    hashedPassword,
+		PasswordChangedAt: time.Now(),
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, formatUserResponse(updatedUser))
+}
+
+func (server *Server) getCurrentUser(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	foundUser, err := server.store.GetUser(ctx, authPayload.Username)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, formatUserResponse(foundUser))
+}
+
+type UpdateCurrentUserRequest struct {
+	Name     *string `json:"name" binding:"omitempty,min=1"`
+	LastName *string `json:"last_name" binding:"omitempty,min=1"`
+	Email    *string `json:"email" binding:"omitempty,email"`
+}
+
+func (server *Server) updateCurrentUser(ctx *gin.Context) {
+	var req UpdateCurrentUserRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Name == nil && req.LastName == nil && req.Email == nil {
+		err := errors.New("at least one of name, last_name and email must be provided")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	verificationCode, err := util.GenerateSecretCode()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.UpdateUserTxParams{
+		UpdateUserParams: db.UpdateUserParams{
+			Name:     toNullString(req.Name),
+			LastName: toNullString(req.LastName),
+			Email:    toNullString(req.Email),
+			Username: authPayload.Username,
+		},
+		HashedVerificationCode: util.HashSecretCode(verificationCode),
+		VerificationExpiresAt:  time.Now().Add(server.config.EmailVerificationDuration),
+		AfterEmailChange: func(user db.User, verifyEmail db.VerifyEmail) error {
+			return server.sendChangedEmailVerification(user, verifyEmail, verificationCode)
+		},
+	}
+
+	result, err := server.store.UpdateUserTx(ctx, arg)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, formatUserResponse(result.User))
+}
+
+// toNullString maps fields left out of a request to NULL, so their column is kept as it is
+func toNullString(value *string) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: *value, Valid: true}
+}
+
+type getUserRequest struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+func (server *Server) getUser(ctx *gin.Context) {
+	var req getUserRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	foundUser, err := server.store.GetUser(ctx, req.Username)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, formatUserResponse(foundUser))
+}
+
+type updateUserRoleRequest struct {
+	params struct {
+		Username string `uri:"username" binding:"required,alphanum"`
+	}
+	body struct {
+		Role string `json:"role" binding:"required,oneof=depositor banker admin"`
+	}
+}
+
+func (server *Server) updateUserRole(ctx *gin.Context) {
+	var req updateUserRoleRequest
+
+	if err := ctx.ShouldBindUri(&req.params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req.body); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	updatedUser, err := server.store.UpdateUserRoleTx(ctx, db.UpdateUserRoleParams{
+		Username: req.params.Username,
+		Role:     req.body.Role,
+	})
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, formatUserResponse(updatedUser))
 }
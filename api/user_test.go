@@ -2,30 +2,39 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	mockdb "github.com/Andrew-2609/simple-bank/db/mock"
 	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	mockmail "github.com/Andrew-2609/simple-bank/mail/mock"
+	"github.com/Andrew-2609/simple-bank/token"
 	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
-type eqCreateUserParamsMatcher struct {
+type eqCreateUserTxParamsMatcher struct {
 	arg         db.CreateUserParams
 	rawPassword string
 }
 
-func (eq eqCreateUserParamsMatcher) Matches(x interface{}) bool {
-	arg, ok := x.(db.CreateUserParams)
+func (eq eqCreateUserTxParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.CreateUserTxParams)
 
 	if !ok {
 		return false
@@ -35,23 +44,27 @@ func (eq eqCreateUserParamsMatcher) Matches(x interface{}) bool {
 		return false
 	}
 
+	if len(arg.HashedVerificationCode) == 0 || !arg.VerificationExpiresAt.After(time.Now()) || arg.AfterCreate == nil {
+		return false
+	}
+
 	eq.arg.HashedPassword = arg.HashedPassword
 
-	return reflect.DeepEqual(eq.arg, arg)
+	return reflect.DeepEqual(eq.arg, arg.CreateUserParams)
 }
 
-func (eq eqCreateUserParamsMatcher) String() string {
+func (eq eqCreateUserTxParamsMatcher) String() string {
 	return fmt.Sprintf("%v (%T)\nDon't mind the hashed password. What matters is the unhashed value, that must be \"%s\"", eq.arg, eq.arg, eq.rawPassword)
 }
 
-func EqCreateUserParams(arg db.CreateUserParams, rawPassword string) gomock.Matcher {
-	return eqCreateUserParamsMatcher{arg, rawPassword}
+func EqCreateUserTxParams(arg db.CreateUserParams, rawPassword string) gomock.Matcher {
+	return eqCreateUserTxParamsMatcher{arg, rawPassword}
 }
 
 func createRandomUser() (user db.User, password string) {
 	password = util.RandomString(8)
 
-	hashedPassword, _ := util.HashPassword(password)
+	hashedPassword, _ := testPasswordHasher.Hash(password)
 
 	user = db.User{
 		Username:       util.RandomOwner(),
@@ -59,6 +72,7 @@ func createRandomUser() (user db.User, password string) {
 		Name:           util.RandomString(5),
 		LastName:       util.RandomString(8),
 		Email:          util.RandomEmail(),
+		Role:           util.DepositorRole,
 	}
 
 	return
@@ -70,6 +84,12 @@ func unmarshallUser(t *testing.T, responseBody *bytes.Buffer) db.User {
 	return responseUser
 }
 
+func unmarshallUserResponse(t *testing.T, responseBody *bytes.Buffer) UserResponse {
+	responseUser, err := util.UnmarshallJsonBody[UserResponse](responseBody)
+	require.NoError(t, err)
+	return responseUser
+}
+
 func TestCreateUserAPI(t *testing.T) {
 	expectedUser, _ := createRandomUser()
 
@@ -81,18 +101,36 @@ func TestCreateUserAPI(t *testing.T) {
 		Email:    util.RandomEmail(),
 	}
 
-	hashedPassword, _ := util.HashPassword(validBody.Password)
+	hashedPassword, _ := testPasswordHasher.Hash(validBody.Password)
+
+	verifyEmail := db.VerifyEmail{
+		ID:        util.RandomInt(1, 1000),
+		Username:  validBody.Username,
+		Email:     validBody.Email,
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	// createUserTx runs AfterCreate like the real transaction does, failing if it fails
+	createUserTx := func(_ context.Context, arg db.CreateUserTxParams) (db.CreateUserTxResult, error) {
+		verifyEmail.HashedCode = arg.HashedVerificationCode
+
+		if err := arg.AfterCreate(expectedUser, verifyEmail); err != nil {
+			return db.CreateUserTxResult{}, err
+		}
+
+		return db.CreateUserTxResult{User: expectedUser, VerifyEmail: verifyEmail}, nil
+	}
 
 	testCases := []struct {
 		name          string
 		body          CreateUserRequest
-		buildStubs    func(store *mockdb.MockStore)
+		buildStubs    func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "Created",
 			body: validBody,
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
 				arg := db.CreateUserParams{
 					Username:       validBody.Username,
 					HashedPassword: hashedPassword,
@@ -102,9 +140,19 @@ func TestCreateUserAPI(t *testing.T) {
 				}
 
 				store.EXPECT().
-					CreateUser(gomock.Any(), EqCreateUserParams(arg, validBody.Password)).
+					CreateUserTx(gomock.Any(), EqCreateUserTxParams(arg, validBody.Password)).
+					Times(1).
+					DoAndReturn(createUserTx)
+				mailer.EXPECT().
+					SendEmail(gomock.Eq("Welcome to Simple Bank"), gomock.Any(), gomock.Eq([]string{validBody.Email})).
 					Times(1).
-					Return(expectedUser, nil)
+					DoAndReturn(func(_ string, content string, _ []string) error {
+						match := regexp.MustCompile(`http://localhost:8080/users/verify_email\?code=([0-9a-f]+)&id=(\d+)`).FindStringSubmatch(content)
+						require.Len(t, match, 3)
+						require.Equal(t, verifyEmail.HashedCode, util.HashSecretCode(match[1]))
+						require.Equal(t, fmt.Sprint(verifyEmail.ID), match[2])
+						return nil
+					})
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusCreated, recorder.Code)
@@ -113,6 +161,7 @@ func TestCreateUserAPI(t *testing.T) {
 					Name:              expectedUser.Name,
 					LastName:          expectedUser.LastName,
 					Email:             expectedUser.Email,
+					Role:              expectedUser.Role,
 					PasswordChangedAt: expectedUser.PasswordChangedAt,
 					CreatedAt:         expectedUser.CreatedAt,
 				}, unmarshallUser(t, recorder.Body))
@@ -121,8 +170,9 @@ func TestCreateUserAPI(t *testing.T) {
 		{
 			name: "Bad Request",
 			body: CreateUserRequest{},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
+				mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -130,47 +180,74 @@ func TestCreateUserAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "HashPassword Internal Error",
+			name: "Password Policy Violation",
 			body: CreateUserRequest{
 				Username: validBody.Username,
-				Password: util.RandomString(73),
+				Password: validBody.Username + util.RandomString(65),
 				Name:     validBody.Name,
 				LastName: validBody.LastName,
 				Email:    validBody.Email,
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
+				mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
-				require.Exactly(t, map[string]interface{}{"error": "Failed to hash password: bcrypt: password length exceeds 72 bytes"}, UnmarshallAny(t, recorder.Body))
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{
+					"error": "password must have at most 64 characters, must not contain the username",
+					"violations": []interface{}{
+						map[string]interface{}{"rule": util.PasswordMaxLengthRule, "message": "must have at most 64 characters"},
+						map[string]interface{}{"rule": util.PasswordContainsUsernameRule, "message": "must not contain the username"},
+					},
+				}, UnmarshallAny(t, recorder.Body))
 			},
 		},
 		{
 			name: "Unique Violation",
 			body: validBody,
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.User{}, &pq.Error{
+					Return(db.CreateUserTxResult{}, &pq.Error{
 						Code:    pq.ErrorCode("23505"),
 						Message: "duplicate key value violates unique constraint \"users_pkey\"",
 					})
+				mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
 				require.Exactly(t, map[string]interface{}{"error": "pq: duplicate key value violates unique constraint \"users_pkey\""}, UnmarshallAny(t, recorder.Body))
 			},
 		},
+		{
+			name: "Send Email Error",
+			body: validBody,
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(createUserTx)
+				mailer.EXPECT().
+					SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("could not send email"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "could not send email"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
 		{
 			name: "Internal Server Error",
 			body: validBody,
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.User{}, sql.ErrConnDone)
+					Return(db.CreateUserTxResult{}, sql.ErrConnDone)
+				mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -186,10 +263,12 @@ func TestCreateUserAPI(t *testing.T) {
 
 			// build stubs
 			store := mockdb.NewMockStore(ctrl)
-			testCase.buildStubs(store)
+			mailer := mockmail.NewMockEmailSender(ctrl)
+			testCase.buildStubs(store, mailer)
 
 			// start test server and send request
 			server := newTestServer(t, store)
+			server.mailer = mailer
 			recorder := httptest.NewRecorder()
 
 			url := fmt.Sprintf("/users")
@@ -210,11 +289,60 @@ func TestCreateUserAPI(t *testing.T) {
 	}
 }
 
+func TestCreateUserPasswordTooLongForBcrypt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
+
+	config := newTestConfig()
+	config.PasswordHashAlgorithm = util.BcryptAlgorithm
+	config.PasswordBcryptCost = bcrypt.MinCost
+
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+
+	// within the max length in characters, but too long for bcrypt to hash
+	body := CreateUserRequest{
+		Username: util.RandomOwner(),
+		Password: strings.Repeat("€", 30),
+		Name:     util.RandomString(5),
+		LastName: util.RandomString(8),
+		Email:    util.RandomEmail(),
+	}
+
+	var buf bytes.Buffer
+
+	err = json.NewEncoder(&buf).Encode(body)
+	require.NoError(t, err)
+
+	request, err := http.NewRequest("POST", "/users", &buf)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	require.Exactly(t, map[string]interface{}{
+		"error":      "password must have at most 72 bytes",
+		"violations": []interface{}{map[string]interface{}{"rule": util.PasswordMaxLengthRule, "message": "must have at most 72 bytes"}},
+	}, UnmarshallAny(t, recorder.Body))
+}
+
 func TestLoginAPI(t *testing.T) {
 	user, password := createRandomUser()
 
 	body := LoginRequest{Username: user.Username, Password: password}
 
+	bcryptHasher, err := util.NewPasswordHasher(util.Config{PasswordHashAlgorithm: util.BcryptAlgorithm, PasswordBcryptCost: bcrypt.MinCost})
+	require.NoError(t, err)
+
+	bcryptUser := user
+	bcryptUser.HashedPassword, err = bcryptHasher.Hash(password)
+	require.NoError(t, err)
+
 	testCases := []struct {
 		name          string
 		body          LoginRequest
@@ -228,6 +356,24 @@ func TestLoginAPI(t *testing.T) {
 				GetUser(gomock.Any(), gomock.Eq(user.Username)).
 				Times(1).
 				Return(user, nil)
+			store.EXPECT().
+				GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(db.TotpSecret{}, sql.ErrNoRows)
+			store.EXPECT().
+				CreateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+					return db.Session{
+						ID:           arg.ID,
+						Username:     arg.Username,
+						RefreshToken: arg.RefreshToken,
+						UserAgent:    arg.UserAgent,
+						ClientIp:     arg.ClientIp,
+						IsBlocked:    arg.IsBlocked,
+						ExpiresAt:    arg.ExpiresAt,
+					}, nil
+				})
 		},
 		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 			require.Equal(t, http.StatusOK, recorder.Code)
@@ -237,15 +383,136 @@ func TestLoginAPI(t *testing.T) {
 
 			tokenRegex := regexp.MustCompile(`^v2\.local\..+$`)
 			require.Regexp(t, tokenRegex, loginResponse.AccessToken)
+			require.Regexp(t, tokenRegex, loginResponse.RefreshToken)
+			require.NotEqual(t, loginResponse.AccessToken, loginResponse.RefreshToken)
+			require.NotZero(t, loginResponse.SessionID)
+			require.True(t, loginResponse.RefreshTokenExpiresAt.After(loginResponse.AccessTokenExpiresAt))
+			require.Equal(t, token.AllScopes, loginResponse.Scopes)
 			require.Exactly(t, UserResponse{
 				Username:          user.Username,
 				Name:              user.Name,
 				LastName:          user.LastName,
 				Email:             user.Email,
+				Role:              user.Role,
 				PasswordChangedAt: user.PasswordChangedAt,
 				CreatedAt:         user.CreatedAt,
 			}, loginResponse.User)
 		},
+	}, {
+		name: "Login With Scopes",
+		body: LoginRequest{Username: user.Username, Password: password, Scopes: []string{token.ScopeAccountsRead}},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(db.TotpSecret{}, sql.ErrNoRows)
+			store.EXPECT().
+				CreateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.Session{ID: uuid.New()}, nil)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			loginResponse, err := util.UnmarshallJsonBody[LoginResponse](recorder.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, []string{token.ScopeAccountsRead}, loginResponse.Scopes)
+		},
+	}, {
+		name: "MFA Required",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(db.TotpSecret{
+					Username:    user.Username,
+					Secret:      "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+					ConfirmedAt: sql.NullTime{Time: time.Now(), Valid: true},
+				}, nil)
+			// the login only succeeds once the mfa challenge is verified, so recording a success here fails the request
+			store.EXPECT().
+				CreateLoginAttempt(gomock.Any(), gomock.Eq(db.CreateLoginAttemptParams{
+					Username:  user.Username,
+					ClientIp:  "",
+					Succeeded: true,
+				})).
+				AnyTimes().
+				Return(sql.ErrConnDone)
+			store.EXPECT().
+				CreateSession(gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			response, err := util.UnmarshallJsonBody[MFAChallengeResponse](recorder.Body)
+			require.NoError(t, err)
+
+			require.True(t, response.MFARequired)
+			require.Regexp(t, regexp.MustCompile(`^v2\.local\..+$`), response.MFAToken)
+			require.WithinDuration(t, time.Now().Add(time.Minute), response.MFATokenExpiresAt, time.Second)
+		},
+	}, {
+		name: "Get TOTP Secret Internal Server Error",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(db.TotpSecret{}, sql.ErrConnDone)
+			store.EXPECT().
+				CreateSession(gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusInternalServerError, recorder.Code)
+		},
+	}, {
+		name: "Unsupported Scope",
+		body: LoginRequest{Username: user.Username, Password: password, Scopes: []string{"accounts:delete"}},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+		},
+	}, {
+		name: "Create Session Internal Server Error",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(db.TotpSecret{}, sql.ErrNoRows)
+			store.EXPECT().
+				CreateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.Session{}, sql.ErrConnDone)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+		},
 	}, {
 		name: "Bad Request",
 		body: LoginRequest{},
@@ -258,6 +525,61 @@ func TestLoginAPI(t *testing.T) {
 			require.Equal(t, http.StatusBadRequest, recorder.Code)
 			require.Exactly(t, map[string]interface{}{"error": "Key: 'LoginRequest.Username' Error:Field validation for 'Username' failed on the 'required' tag\nKey: 'LoginRequest.Password' Error:Field validation for 'Password' failed on the 'required' tag"}, UnmarshallAny(t, recorder.Body))
 		},
+	}, {
+		name: "Rehash Outdated Password",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(bcryptUser, nil)
+			store.EXPECT().
+				RehashUserPassword(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.RehashUserPasswordParams) error {
+					require.Equal(t, user.Username, arg.Username)
+					require.Equal(t, bcryptUser.HashedPassword, arg.OldHashedPassword)
+					require.False(t, testPasswordHasher.NeedsRehash(arg.HashedPassword))
+					require.NoError(t, util.CheckPassword(arg.HashedPassword, password))
+					return nil
+				})
+			store.EXPECT().
+				GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(db.TotpSecret{}, sql.ErrNoRows)
+			store.EXPECT().
+				CreateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.Session{}, nil)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusOK, recorder.Code)
+		},
+	}, {
+		name: "Rehash Error",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(bcryptUser, nil)
+			store.EXPECT().
+				RehashUserPassword(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(sql.ErrConnDone)
+			store.EXPECT().
+				GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(db.TotpSecret{}, sql.ErrNoRows)
+			store.EXPECT().
+				CreateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.Session{}, nil)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			// the user is already authenticated, so the login still succeeds
+			require.Equal(t, http.StatusOK, recorder.Code)
+		},
 	}, {
 		name: "User Not Found",
 		body: body,
@@ -266,10 +588,18 @@ func TestLoginAPI(t *testing.T) {
 				GetUser(gomock.Any(), gomock.Eq(user.Username)).
 				Times(1).
 				Return(db.User{}, sql.ErrNoRows)
+			store.EXPECT().
+				CreateLoginAttempt(gomock.Any(), gomock.Eq(db.CreateLoginAttemptParams{
+					Username:  user.Username,
+					ClientIp:  "",
+					Succeeded: false,
+				})).
+				Times(1).
+				Return(nil)
 		},
 		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-			require.Equal(t, http.StatusNotFound, recorder.Code)
-			require.Exactly(t, map[string]interface{}{"error": sql.ErrNoRows.Error()}, UnmarshallAny(t, recorder.Body))
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "invalid username or password"}, UnmarshallAny(t, recorder.Body))
 		},
 	}, {
 		name: "Internal Server Error",
@@ -295,7 +625,85 @@ func TestLoginAPI(t *testing.T) {
 		},
 		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 			require.Equal(t, http.StatusUnauthorized, recorder.Code)
-			require.Exactly(t, map[string]interface{}{"error": "crypto/bcrypt: hashedPassword is not the hash of the given password"}, UnmarshallAny(t, recorder.Body))
+			require.Exactly(t, map[string]interface{}{"error": "invalid username or password"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Lockout Triggered",
+		body: LoginRequest{Username: user.Username, Password: util.RandomString(8)},
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				CountFailedLoginAttempts(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(int64(3), nil)
+			store.EXPECT().
+				CountLoginLockouts(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(int64(1), nil)
+			store.EXPECT().
+				CreateLoginLockout(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CreateLoginLockoutParams) (db.LoginLockout, error) {
+					require.Equal(t, user.Username, arg.Username)
+					require.Equal(t, int32(3), arg.FailedAttempts)
+					// the second lockout lasts twice as long as the first one
+					require.WithinDuration(t, time.Now().Add(2*time.Minute), arg.LockedUntil, time.Second)
+					return db.LoginLockout{}, nil
+				})
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "invalid username or password"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Locked Out",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetActiveLoginLockout(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(db.LoginLockout{Username: user.Username, LockedUntil: time.Now().Add(time.Minute)}, nil)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+		},
+	}, {
+		name: "Too Many Failed Attempts From Client",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				CountFailedLoginAttemptsByIP(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(int64(10), nil)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "too many failed login attempts from this client"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Lockout Check Internal Server Error",
+		body: body,
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				GetActiveLoginLockout(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.LoginLockout{}, sql.ErrConnDone)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusInternalServerError, recorder.Code)
 		},
 	}}
 
@@ -307,6 +715,7 @@ func TestLoginAPI(t *testing.T) {
 			// build stubs
 			store := mockdb.NewMockStore(ctrl)
 			testCase.buildStubs(store)
+			stubLoginThrottle(store)
 
 			// start test server and send request
 			server := newTestServer(t, store)
@@ -329,3 +738,1277 @@ func TestLoginAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestLoginRefreshTokenIsNotAnAccessToken(t *testing.T) {
+	user, password := createRandomUser()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(db.TotpSecret{}, sql.ErrNoRows)
+	store.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.Session{ID: uuid.New()}, nil)
+	store.EXPECT().
+		IsTokenRevoked(gomock.Any(), gomock.Any()).
+		Times(0)
+	stubLoginThrottle(store)
+
+	server := newTestServer(t, store)
+
+	var buf bytes.Buffer
+
+	err := json.NewEncoder(&buf).Encode(LoginRequest{Username: user.Username, Password: password})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest("POST", "/users/login", &buf)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	loginResponse, err := util.UnmarshallJsonBody[LoginResponse](recorder.Body)
+	require.NoError(t, err)
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(loginResponse.RefreshToken)
+	require.NoError(t, err)
+	require.Equal(t, token.PurposeRefresh, refreshPayload.Purpose)
+
+	// the refresh token must not be accepted as a bearer access token
+	request, err = http.NewRequest("GET", "/accounts?page=1&quantity=5", nil)
+	require.NoError(t, err)
+
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, loginResponse.RefreshToken))
+
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	require.Exactly(t, map[string]interface{}{"error": "refresh token can't be used as an access token"}, UnmarshallAny(t, recorder.Body))
+}
+
+func TestLogoutAPI(t *testing.T) {
+	user, _ := createRandomUser()
+
+	testCases := []struct {
+		name          string
+		path          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Logout No Content",
+			path: "/users/logout",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RevokeSessionTxParams) error {
+						require.Equal(t, user.Username, arg.Username)
+						require.NotZero(t, arg.TokenID)
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "Logout Internal Server Error",
+			path: "/users/logout",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Logout All No Content",
+			path: "/users/logout-all",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeAllSessionsTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RevokeAllSessionsTxParams) error {
+						require.Equal(t, user.Username, arg.Username)
+						require.NotZero(t, arg.TokenID)
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "Logout All Internal Server Error",
+			path: "/users/logout-all",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeAllSessionsTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Logout With API Key",
+			path: "/users/logout",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				request.Header.Set(apiKeyHeaderKey, testAPIKey)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHashedKey(gomock.Any(), gomock.Eq(util.HashAPIKey(testAPIKey))).
+					Times(1).
+					Return(db.ApiKey{Username: user.Username, Scopes: token.AllScopes}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().RevokeSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "api keys can't be used to access this resource, revoke the key instead"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Logout All With API Key",
+			path: "/users/logout-all",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("ApiKey %s", testAPIKey))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKeyByHashedKey(gomock.Any(), gomock.Eq(util.HashAPIKey(testAPIKey))).
+					Times(1).
+					Return(db.ApiKey{Username: user.Username, Scopes: token.AllScopes}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().RevokeAllSessionsTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "No Authorization",
+			path:      "/users/logout",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RevokeSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "authorization header was not provided"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// build stubs
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			// start test server and send request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest("POST", testCase.path, nil)
+			require.NoError(t, err)
+
+			// setup authorization middleware
+			testCase.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			// check response
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+type eqUpdateUserPasswordParamsMatcher struct {
+	username    string
+	rawPassword string
+}
+
+func (eq eqUpdateUserPasswordParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.UpdateUserPasswordParams)
+
+	if !ok {
+		return false
+	}
+
+	if err := util.CheckPassword(arg.HashedPassword, eq.rawPassword); err != nil {
+		return false
+	}
+
+	return arg.Username == eq.username && !arg.PasswordChangedAt.IsZero()
+}
+
+func (eq eqUpdateUserPasswordParamsMatcher) String() string {
+	return fmt.Sprintf("update password of %s to the hash of \"%s\"", eq.username, eq.rawPassword)
+}
+
+func EqUpdateUserPasswordParams(username string, rawPassword string) gomock.Matcher {
+	return eqUpdateUserPasswordParamsMatcher{username, rawPassword}
+}
+
+func TestChangePasswordAPI(t *testing.T) {
+	user, password := createRandomUser()
+
+	newPassword := util.RandomString(10)
+
+	validBody := ChangePasswordRequest{OldPassword: password, NewPassword: newPassword}
+
+	testCases := []struct {
+		name          string
+		body          ChangePasswordRequest
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: validBody,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), EqUpdateUserPasswordParams(user.Username, newPassword)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Exactly(t, formatUserResponse(user), unmarshallUserResponse(t, recorder.Body))
+			},
+		},
+		{
+			name: "Bad Request",
+			body: ChangePasswordRequest{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "Key: 'ChangePasswordRequest.OldPassword' Error:Field validation for 'OldPassword' failed on the 'required' tag\nKey: 'ChangePasswordRequest.NewPassword' Error:Field validation for 'NewPassword' failed on the 'required' tag"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Wrong Old Password",
+			body: ChangePasswordRequest{OldPassword: util.RandomString(9), NewPassword: newPassword},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "password doesn't match the hashed password"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Password Policy Violation",
+			body: ChangePasswordRequest{OldPassword: password, NewPassword: "short"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{
+					"error":      "password must have at least 8 characters",
+					"violations": []interface{}{map[string]interface{}{"rule": util.PasswordMinLengthRule, "message": "must have at least 8 characters"}},
+				}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "User Not Found",
+			body: validBody,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrNoRows.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Update Internal Server Error",
+			body: validBody,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:      "No Authorization",
+			body:      validBody,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "authorization header was not provided"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// build stubs
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			// start test server and send request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var buf bytes.Buffer
+
+			err := json.NewEncoder(&buf).Encode(testCase.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest("PUT", "/users/me/password", &buf)
+			require.NoError(t, err)
+
+			// setup authorization middleware
+			testCase.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			// check response
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetUserAPI(t *testing.T) {
+	user, _ := createRandomUser()
+
+	testCases := []struct {
+		name          string
+		username      string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Exactly(t, formatUserResponse(user), unmarshallUserResponse(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Depositor Forbidden",
+			username: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "role depositor is not allowed to access this resource"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:     "Not Found",
+			username: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrNoRows.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// build stubs
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			// start test server and send request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest("GET", fmt.Sprintf("/users/%s", testCase.username), nil)
+			require.NoError(t, err)
+
+			// setup authorization middleware
+			testCase.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			// check response
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetCurrentUserAPI(t *testing.T) {
+	user, _ := createRandomUser()
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Exactly(t, formatUserResponse(user), unmarshallUserResponse(t, recorder.Body))
+			},
+		},
+		{
+			name: "Not Found",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrNoRows.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Internal Server Error",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name:      "No Authorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// build stubs
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			// start test server and send request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest("GET", "/users/me", nil)
+			require.NoError(t, err)
+
+			// setup authorization middleware
+			testCase.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			// check response
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateCurrentUserAPI(t *testing.T) {
+	user, _ := createRandomUser()
+
+	newName := util.RandomOwner()
+	newEmail := util.RandomEmail()
+
+	renamedUser := user
+	renamedUser.Name = newName
+
+	reverifyingUser := user
+	reverifyingUser.Email = newEmail
+	reverifyingUser.IsEmailVerified = false
+
+	verifyEmail := db.VerifyEmail{
+		ID:        util.RandomInt(1, 1000),
+		Username:  user.Username,
+		Email:     newEmail,
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	// updateUserTx runs AfterEmailChange like the real transaction does when the email changes, failing if it fails
+	updateUserTx := func(updatedUser db.User) func(context.Context, db.UpdateUserTxParams) (db.UpdateUserTxResult, error) {
+		return func(_ context.Context, arg db.UpdateUserTxParams) (db.UpdateUserTxResult, error) {
+			if updatedUser.Email == user.Email {
+				return db.UpdateUserTxResult{User: updatedUser}, nil
+			}
+
+			verifyEmail.HashedCode = arg.HashedVerificationCode
+
+			if err := arg.AfterEmailChange(updatedUser, verifyEmail); err != nil {
+				return db.UpdateUserTxResult{}, err
+			}
+
+			return db.UpdateUserTxResult{User: updatedUser, VerifyEmail: verifyEmail}, nil
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": newName},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UpdateUserTxParams) (db.UpdateUserTxResult, error) {
+						require.Equal(t, db.UpdateUserParams{
+							Name:     sql.NullString{String: newName, Valid: true},
+							Username: user.Username,
+						}, arg.UpdateUserParams)
+
+						return updateUserTx(renamedUser)(ctx, arg)
+					})
+				mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Exactly(t, formatUserResponse(renamedUser), unmarshallUserResponse(t, recorder.Body))
+			},
+		},
+		{
+			name: "Email Changed",
+			body: gin.H{"email": newEmail},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UpdateUserTxParams) (db.UpdateUserTxResult, error) {
+						require.Equal(t, db.UpdateUserParams{
+							Email:    sql.NullString{String: newEmail, Valid: true},
+							Username: user.Username,
+						}, arg.UpdateUserParams)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.VerificationExpiresAt, time.Second)
+
+						return updateUserTx(reverifyingUser)(ctx, arg)
+					})
+				mailer.EXPECT().
+					SendEmail(gomock.Eq("Verify your new email address"), gomock.Any(), gomock.Eq([]string{newEmail})).
+					Times(1).
+					DoAndReturn(func(_ string, content string, _ []string) error {
+						match := regexp.MustCompile(`http://localhost:8080/users/verify_email\?code=([0-9a-f]+)&id=(\d+)`).FindStringSubmatch(content)
+						require.Len(t, match, 3)
+						require.Equal(t, verifyEmail.HashedCode, util.HashSecretCode(match[1]))
+						require.Equal(t, fmt.Sprint(verifyEmail.ID), match[2])
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				responseUser := unmarshallUserResponse(t, recorder.Body)
+				require.Equal(t, newEmail, responseUser.Email)
+				require.False(t, responseUser.IsEmailVerified)
+			},
+		},
+		{
+			name: "Send Email Error",
+			body: gin.H{"email": newEmail},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(updateUserTx(reverifyingUser))
+				mailer.EXPECT().
+					SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("could not send email"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "could not send email"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "No Fields",
+			body: gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+				mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "at least one of name, last_name and email must be provided"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Bad Request",
+			body: gin.H{"name": "", "email": "not-an-email"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+				mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "Key: 'UpdateCurrentUserRequest.Name' Error:Field validation for 'Name' failed on the 'min' tag\nKey: 'UpdateCurrentUserRequest.Email' Error:Field validation for 'Email' failed on the 'email' tag"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Email Already In Use",
+			body: gin.H{"email": newEmail},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateUserTxResult{}, &pq.Error{
+						Code:    pq.ErrorCode("23505"),
+						Message: "duplicate key value violates unique constraint \"users_email_key\"",
+					})
+				mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "pq: duplicate key value violates unique constraint \"users_email_key\""}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Not Found",
+			body: gin.H{"name": newName},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateUserTxResult{}, sql.ErrNoRows)
+				mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "Internal Server Error",
+			body: gin.H{"name": newName},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateUserTxResult{}, sql.ErrConnDone)
+				mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Missing Users Write Scope",
+			body: gin.H{"name": newName},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithScopes(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, []string{token.ScopeUsersRead}, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+				mailer.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// build stubs
+			store := mockdb.NewMockStore(ctrl)
+			mailer := mockmail.NewMockEmailSender(ctrl)
+			testCase.buildStubs(store, mailer)
+			stubAuthChecks(store)
+
+			// start test server and send request
+			server := newTestServer(t, store)
+			server.mailer = mailer
+			recorder := httptest.NewRecorder()
+
+			var buf bytes.Buffer
+
+			err := json.NewEncoder(&buf).Encode(testCase.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest("PATCH", "/users/me", &buf)
+			require.NoError(t, err)
+
+			// setup authorization middleware
+			testCase.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			// check response
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateUserRoleAPI(t *testing.T) {
+	user, _ := createRandomUser()
+
+	promotedUser := user
+	promotedUser.Role = util.BankerRole
+
+	testCases := []struct {
+		name          string
+		role          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: util.BankerRole,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserRoleTx(gomock.Any(), gomock.Eq(db.UpdateUserRoleParams{Username: user.Username, Role: util.BankerRole})).
+					Times(1).
+					Return(promotedUser, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Exactly(t, formatUserResponse(promotedUser), unmarshallUserResponse(t, recorder.Body))
+			},
+		},
+		{
+			name: "Bad Request",
+			role: "manager",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserRoleTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Banker Forbidden",
+			role: util.AdminRole,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserRoleTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": "role banker is not allowed to access this resource"}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+		{
+			name: "Not Found",
+			role: util.BankerRole,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationWithRole(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserRoleTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Exactly(t, map[string]interface{}{"error": sql.ErrNoRows.Error()}, UnmarshallAny(t, recorder.Body))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// build stubs
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubAuthChecks(store)
+
+			// start test server and send request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var buf bytes.Buffer
+
+			err := json.NewEncoder(&buf).Encode(gin.H{"role": testCase.role})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest("PUT", fmt.Sprintf("/users/%s/role", user.Username), &buf)
+			require.NoError(t, err)
+
+			// setup authorization middleware
+			testCase.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			// check response
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVerifyEmailAPI(t *testing.T) {
+	user, _ := createRandomUser()
+	user.IsEmailVerified = true
+
+	verifyEmailID := util.RandomInt(1, 1000)
+	code := util.RandomString(64)
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{{
+		name:  "OK",
+		query: fmt.Sprintf("id=%d&code=%s", verifyEmailID, code),
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				VerifyEmailTx(gomock.Any(), gomock.Eq(db.VerifyEmailTxParams{
+					ID:         verifyEmailID,
+					HashedCode: util.HashSecretCode(code),
+				})).
+				Times(1).
+				Return(db.VerifyEmailTxResult{User: user}, nil)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			response := unmarshallUserResponse(t, recorder.Body)
+			require.Equal(t, user.Username, response.Username)
+			require.True(t, response.IsEmailVerified)
+		},
+	}, {
+		name:  "Bad Request",
+		query: fmt.Sprintf("id=%d", verifyEmailID),
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Any()).Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+		},
+	}, {
+		name:  "Invalid Or Expired Code",
+		query: fmt.Sprintf("id=%d&code=%s", verifyEmailID, code),
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				VerifyEmailTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.VerifyEmailTxResult{}, sql.ErrNoRows)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "invalid or expired verification code"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name:  "Internal Server Error",
+		query: fmt.Sprintf("id=%d&code=%s", verifyEmailID, code),
+		buildStubs: func(store *mockdb.MockStore) {
+			store.EXPECT().
+				VerifyEmailTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.VerifyEmailTxResult{}, sql.ErrConnDone)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+		},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest("GET", "/users/verify_email?"+testCase.query, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestResendVerifyEmailAPI(t *testing.T) {
+	user, _ := createRandomUser()
+
+	verifiedUser := user
+	verifiedUser.IsEmailVerified = true
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{{
+		name: "Accepted",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+		},
+		buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+			var verifyEmail db.VerifyEmail
+
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				CreateVerifyEmail(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CreateVerifyEmailParams) (db.VerifyEmail, error) {
+					require.Equal(t, user.Username, arg.Username)
+					require.Equal(t, user.Email, arg.Email)
+					require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiredAt, time.Second)
+
+					verifyEmail = db.VerifyEmail{ID: 7, Username: arg.Username, Email: arg.Email, HashedCode: arg.HashedCode, ExpiredAt: arg.ExpiredAt}
+					return verifyEmail, nil
+				})
+			mailer.EXPECT().
+				SendEmail(gomock.Eq("Verify your email address"), gomock.Any(), gomock.Eq([]string{user.Email})).
+				Times(1).
+				DoAndReturn(func(_ string, content string, _ []string) error {
+					match := regexp.MustCompile(`http://localhost:8080/users/verify_email\?code=([0-9a-f]+)&id=(\d+)`).FindStringSubmatch(content)
+					require.Len(t, match, 3)
+					require.Equal(t, verifyEmail.HashedCode, util.HashSecretCode(match[1]))
+					require.Equal(t, fmt.Sprint(verifyEmail.ID), match[2])
+					return nil
+				})
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusAccepted, recorder.Code)
+		},
+	}, {
+		name: "Already Verified",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+		},
+		buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(verifiedUser, nil)
+			store.EXPECT().
+				CreateVerifyEmail(gomock.Any(), gomock.Any()).
+				Times(0)
+			mailer.EXPECT().
+				SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "email address has already been verified"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "User Not Found",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+		},
+		buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(db.User{}, sql.ErrNoRows)
+			store.EXPECT().
+				CreateVerifyEmail(gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusNotFound, recorder.Code)
+		},
+	}, {
+		name: "Create Verify Email Internal Server Error",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+		},
+		buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				CreateVerifyEmail(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.VerifyEmail{}, sql.ErrConnDone)
+			mailer.EXPECT().
+				SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": sql.ErrConnDone.Error()}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name: "Send Email Internal Server Error",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+		},
+		buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				CreateVerifyEmail(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.VerifyEmail{ID: 1, Email: user.Email}, nil)
+			mailer.EXPECT().
+				SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(errors.New("could not send email"))
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			require.Exactly(t, map[string]interface{}{"error": "could not send email"}, UnmarshallAny(t, recorder.Body))
+		},
+	}, {
+		name:      "No Authorization",
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+		buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockEmailSender) {
+			store.EXPECT().
+				CreateVerifyEmail(gomock.Any(), gomock.Any()).
+				Times(0)
+		},
+		checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			mailer := mockmail.NewMockEmailSender(ctrl)
+			testCase.buildStubs(store, mailer)
+			stubAuthChecks(store)
+
+			server := newTestServer(t, store)
+			server.mailer = mailer
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest("POST", "/users/me/verify_email", nil)
+			require.NoError(t, err)
+
+			testCase.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVerifyEmailEscapesName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := createRandomUser()
+	user.Name = `<a href="https://phishing.example">Claim your bonus</a>`
+
+	verifyEmail := db.VerifyEmail{ID: 1, Email: user.Email, ExpiredAt: time.Now().Add(time.Hour)}
+
+	mailer := mockmail.NewMockEmailSender(ctrl)
+	mailer.EXPECT().
+		SendEmail(gomock.Any(), gomock.Any(), gomock.Eq([]string{user.Email})).
+		Times(2).
+		DoAndReturn(func(_ string, content string, _ []string) error {
+			require.NotContains(t, content, user.Name)
+			require.Contains(t, content, "Hello &lt;a href=&#34;https://phishing.example&#34;&gt;Claim your bonus&lt;/a&gt;,")
+			return nil
+		})
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	server.mailer = mailer
+
+	require.NoError(t, server.sendVerifyEmail(user, verifyEmail, "code"))
+	require.NoError(t, server.sendChangedEmailVerification(user, verifyEmail, "code"))
+}
@@ -1,13 +1,22 @@
 package api
 
 import (
+	"github.com/Andrew-2609/simple-bank/token"
 	"github.com/Andrew-2609/simple-bank/util"
 	"github.com/go-playground/validator/v10"
 )
 
-var validateCurrency validator.Func = func(fl validator.FieldLevel) bool {
-	if currency, ok := fl.Field().Interface().(string); ok {
-		return util.IsSupportedCurrency(currency)
+var validateScope validator.Func = func(fl validator.FieldLevel) bool {
+	if scope, ok := fl.Field().Interface().(string); ok {
+		return token.IsSupportedScope(scope)
+	}
+
+	return false
+}
+
+var validateFrequency validator.Func = func(fl validator.FieldLevel) bool {
+	if frequency, ok := fl.Field().Interface().(string); ok {
+		return util.IsSupportedFrequency(frequency)
 	}
 
 	return false
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/token"
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/gin-gonic/gin"
+)
+
+// verifyEmailURL is the link users follow to verify the address the verification code was created for
+func (server *Server) verifyEmailURL(verifyEmail db.VerifyEmail, code string) string {
+	query := url.Values{}
+	query.Set("id", fmt.Sprint(verifyEmail.ID))
+	query.Set("code", code)
+
+	return fmt.Sprintf("%s/users/verify_email?%s", strings.TrimSuffix(server.config.AppBaseURL, "/"), query.Encode())
+}
+
+// sendVerifyEmail emails a newly registered user a link to verify their address
+func (server *Server) sendVerifyEmail(user db.User, verifyEmail db.VerifyEmail, code string) error {
+	verifyURL := server.verifyEmailURL(verifyEmail, code)
+
+	subject := "Welcome to Simple Bank"
+	content := fmt.Sprintf(`Hello %s,<br/>
+Thank you for registering with us!<br/>
+Please <a href="%s">click here</a> to verify your email address.<br/>
+The link expires at %s.<br/>`, html.EscapeString(user.Name), verifyURL, verifyEmail.ExpiredAt.Format("2006-01-02 15:04 MST"))
+
+	return server.mailer.SendEmail(subject, content, []string{verifyEmail.Email})
+}
+
+// sendChangedEmailVerification emails the user a link to verify the address they changed their email to
+func (server *Server) sendChangedEmailVerification(user db.User, verifyEmail db.VerifyEmail, code string) error {
+	verifyURL := server.verifyEmailURL(verifyEmail, code)
+
+	subject := "Verify your new email address"
+	content := fmt.Sprintf(`Hello %s,<br/>
+The email address of your Simple Bank account was changed to this one.<br/>
+Please <a href="%s">click here</a> to verify it.<br/>
+The link expires at %s.<br/>`, html.EscapeString(user.Name), verifyURL, verifyEmail.ExpiredAt.Format("2006-01-02 15:04 MST"))
+
+	return server.mailer.SendEmail(subject, content, []string{verifyEmail.Email})
+}
+
+// sendNewVerifyEmail emails the user a new link to verify their address, after they asked for it again
+func (server *Server) sendNewVerifyEmail(user db.User, verifyEmail db.VerifyEmail, code string) error {
+	verifyURL := server.verifyEmailURL(verifyEmail, code)
+
+	subject := "Verify your email address"
+	content := fmt.Sprintf(`Hello %s,<br/>
+Here is a new link to verify the email address of your Simple Bank account.<br/>
+Please <a href="%s">click here</a> to verify it.<br/>
+The link expires at %s.<br/>`, html.EscapeString(user.Name), verifyURL, verifyEmail.ExpiredAt.Format("2006-01-02 15:04 MST"))
+
+	return server.mailer.SendEmail(subject, content, []string{verifyEmail.Email})
+}
+
+type verifyEmailRequest struct {
+	ID   int64  `form:"id" binding:"required,min=1"`
+	Code string `form:"code" binding:"required"`
+}
+
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.VerifyEmailTx(ctx, db.VerifyEmailTxParams{
+		ID:         req.ID,
+		HashedCode: util.HashSecretCode(req.Code),
+	})
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.New("invalid or expired verification code")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, formatUserResponse(result.User))
+}
+
+// resendVerifyEmail creates a new verification code for the email of the authenticated user and emails it to them,
+// for when the previous one expired or got lost. Previous codes stay valid until they expire
+func (server *Server) resendVerifyEmail(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if user.IsEmailVerified {
+		err := errors.New("email address has already been verified")
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	verificationCode, err := util.GenerateSecretCode()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	verifyEmail, err := server.store.CreateVerifyEmail(ctx, db.CreateVerifyEmailParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		HashedCode: util.HashSecretCode(verificationCode),
+		ExpiredAt:  time.Now().Add(server.config.EmailVerificationDuration),
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := server.sendNewVerifyEmail(user, verifyEmail, verificationCode); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, nil)
+}
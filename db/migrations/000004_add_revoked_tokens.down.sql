@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "sessions_username_idx";
+
+DROP TABLE IF EXISTS "revoked_tokens";
\ No newline at end of file
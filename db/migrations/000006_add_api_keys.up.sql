@@ -0,0 +1,18 @@
+CREATE TABLE "api_keys" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "key_prefix" varchar NOT NULL,
+  "hashed_key" varchar UNIQUE NOT NULL,
+  "scopes" varchar[] NOT NULL,
+  "expires_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "api_keys" ("username");
+
+COMMENT ON COLUMN "api_keys"."hashed_key" IS 'SHA-256 of the key, which is only shown once';
+
+COMMENT ON COLUMN "api_keys"."expires_at" IS 'keys without expiration last until revoked';
+
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
\ No newline at end of file
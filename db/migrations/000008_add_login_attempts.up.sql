@@ -0,0 +1,26 @@
+CREATE TABLE "login_attempts" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "succeeded" boolean NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "login_lockouts" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "failed_attempts" integer NOT NULL,
+  "locked_until" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "login_attempts" ("username", "created_at");
+
+CREATE INDEX ON "login_attempts" ("client_ip", "created_at");
+
+CREATE INDEX ON "login_lockouts" ("username", "locked_until");
+
+COMMENT ON COLUMN "login_attempts"."username" IS 'not a foreign key, as attempts for unknown users are tracked too';
+
+COMMENT ON COLUMN "login_lockouts"."client_ip" IS 'client of the attempt that triggered the lockout';
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- users created before email verification existed are considered verified
+ALTER TABLE "users" ADD COLUMN "is_email_verified" boolean NOT NULL DEFAULT true;
+
+ALTER TABLE "users" ALTER COLUMN "is_email_verified" SET DEFAULT false;
+
+CREATE TABLE "verify_emails" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "email" varchar NOT NULL,
+  "hashed_code" varchar NOT NULL,
+  "is_used" boolean NOT NULL DEFAULT false,
+  "expired_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "verify_emails" ("username");
+
+COMMENT ON COLUMN "verify_emails"."email" IS 'the address the code was sent to, only verified if it is still the email of the user';
+
+ALTER TABLE "verify_emails" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "exchange_rate_updated_at";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "exchange_rate";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "credited_amount";
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
\ No newline at end of file
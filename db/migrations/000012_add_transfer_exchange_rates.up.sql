@@ -0,0 +1,22 @@
+-- transfers made before exchange rates existed were between accounts with the same currency
+ALTER TABLE "transfers" ADD COLUMN "credited_amount" bigint;
+
+UPDATE "transfers" SET "credited_amount" = "amount";
+
+ALTER TABLE "transfers" ALTER COLUMN "credited_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" double precision NOT NULL DEFAULT 1;
+
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate_updated_at" timestamptz;
+
+UPDATE "transfers" SET "exchange_rate_updated_at" = "created_at";
+
+ALTER TABLE "transfers" ALTER COLUMN "exchange_rate_updated_at" SET NOT NULL;
+
+ALTER TABLE "transfers" ALTER COLUMN "exchange_rate_updated_at" SET DEFAULT (now());
+
+COMMENT ON COLUMN "transfers"."amount" IS 'debited from the from account, in its currency; must be positive';
+
+COMMENT ON COLUMN "transfers"."credited_amount" IS 'credited to the to account, in its currency';
+
+COMMENT ON COLUMN "transfers"."exchange_rate" IS 'applied to the amount to get the credited amount';
\ No newline at end of file
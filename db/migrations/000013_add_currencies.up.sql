@@ -0,0 +1,24 @@
+CREATE TABLE "currencies" (
+  "code" varchar PRIMARY KEY,
+  "numeric_code" integer UNIQUE NOT NULL,
+  "exponent" integer NOT NULL,
+  "symbol" varchar NOT NULL,
+  "is_enabled" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "currencies"."code" IS 'ISO 4217 alphabetic code';
+
+COMMENT ON COLUMN "currencies"."exponent" IS 'number of digits of the minor unit amounts are stored in';
+
+INSERT INTO
+  "currencies" ("code", "numeric_code", "exponent", "symbol", "is_enabled")
+VALUES
+  ('BHD', 48, 3, 'BD', false),
+  ('BRL', 986, 2, 'R$', true),
+  ('EUR', 978, 2, '€', true),
+  ('GBP', 826, 2, '£', false),
+  ('JPY', 392, 0, '¥', false),
+  ('USD', 840, 2, '$', true);
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
\ No newline at end of file
@@ -0,0 +1,51 @@
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "frequency" varchar NOT NULL DEFAULT 'once',
+  "frequency_interval" integer NOT NULL DEFAULT 1,
+  "start_at" timestamptz NOT NULL,
+  "end_at" timestamptz,
+  "next_run_at" timestamptz,
+  "cancelled_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "scheduled_transfer_executions" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "transfer_id" bigint,
+  "scheduled_for" timestamptz NOT NULL,
+  "error" varchar,
+  "executed_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("next_run_at");
+
+CREATE INDEX ON "scheduled_transfer_executions" ("scheduled_transfer_id");
+
+COMMENT ON COLUMN "scheduled_transfers"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "scheduled_transfers"."frequency" IS 'once, daily, weekly or monthly';
+
+COMMENT ON COLUMN "scheduled_transfers"."frequency_interval" IS 'how many days, weeks or months apart runs are';
+
+COMMENT ON COLUMN "scheduled_transfers"."next_run_at" IS 'NULL once the schedule has no more runs or is cancelled';
+
+COMMENT ON COLUMN "scheduled_transfer_executions"."transfer_id" IS 'NULL when the execution failed';
+
+COMMENT ON COLUMN "scheduled_transfer_executions"."error" IS 'why the execution failed, if it did';
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfer_executions" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_executions" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "reverses_transfer_id";
\ No newline at end of file
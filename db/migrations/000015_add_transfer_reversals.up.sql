@@ -0,0 +1,7 @@
+ALTER TABLE "transfers" ADD COLUMN "reverses_transfer_id" bigint;
+
+CREATE INDEX ON "transfers" ("reverses_transfer_id");
+
+COMMENT ON COLUMN "transfers"."reverses_transfer_id" IS 'the transfer this one gives money back from, if it is a reversal';
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reverses_transfer_id") REFERENCES "transfers" ("id");
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "hold_expires_at";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "status";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "available_balance";
+
+COMMENT ON COLUMN "accounts"."balance" IS NULL;
\ No newline at end of file
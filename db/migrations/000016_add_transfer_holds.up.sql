@@ -0,0 +1,19 @@
+ALTER TABLE "accounts" ADD COLUMN "available_balance" bigint;
+
+UPDATE "accounts" SET "available_balance" = "balance";
+
+ALTER TABLE "accounts" ALTER COLUMN "available_balance" SET NOT NULL;
+
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'posted';
+
+ALTER TABLE "transfers" ADD COLUMN "hold_expires_at" timestamptz;
+
+CREATE INDEX ON "transfers" ("status", "hold_expires_at");
+
+COMMENT ON COLUMN "accounts"."balance" IS 'posted balance, which pending transfers are not debited from yet';
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'balance minus the amount held by pending transfers';
+
+COMMENT ON COLUMN "transfers"."status" IS 'pending, posted or voided';
+
+COMMENT ON COLUMN "transfers"."hold_expires_at" IS 'when a pending transfer is voided if not captured';
\ No newline at end of file
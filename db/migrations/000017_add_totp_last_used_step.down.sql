@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "totp_secrets" DROP COLUMN IF EXISTS "last_used_step";
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE "totp_secrets" ADD COLUMN "last_used_step" bigint;
+
+COMMENT ON COLUMN "totp_secrets"."last_used_step" IS 'time step of the last code used, so a code can''t be replayed within its window';
\ No newline at end of file
@@ -1,8 +1,8 @@
 -- name: CreateAccount :one
 INSERT INTO
-  accounts (owner, balance, currency)
+  accounts (owner, balance, available_balance, currency)
 VALUES
-  ($1, $2, $3) RETURNING *;
+  ($1, $2, $2, $3) RETURNING *;
 
 -- name: GetAccount :one
 SELECT * FROM accounts
@@ -21,14 +21,32 @@ LIMIT $2 OFFSET $3;
 
 -- name: UpdateAccount :one
 UPDATE accounts
-SET balance = $2
+SET available_balance = available_balance + $2 - balance, balance = $2
 WHERE id = $1 RETURNING *;
 
 -- name: AddAccountBalance :one
 UPDATE accounts
-SET balance = balance + sqlc.arg(amount)
-WHERE id = sqlc.arg(id) RETURNING *;
+SET balance = balance + sqlc.arg(amount), available_balance = available_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id) AND (sqlc.arg(amount) >= 0 OR available_balance + sqlc.arg(amount) >= 0)
+RETURNING *;
+
+-- name: AddAccountAvailableBalance :one
+UPDATE accounts
+SET available_balance = available_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id) AND (sqlc.arg(amount) >= 0 OR available_balance + sqlc.arg(amount) >= 0)
+RETURNING *;
+
+-- name: CaptureAccountHold :one
+UPDATE accounts
+SET balance = balance - sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
 
 -- name: DeleteAccount :exec
 DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: UpdateAccountFrozen :one
+UPDATE accounts
+SET is_frozen = $2
+WHERE id = $1 RETURNING *;
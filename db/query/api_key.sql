@@ -0,0 +1,26 @@
+-- name: CreateAPIKey :one
+INSERT INTO
+  api_keys (id, username, name, key_prefix, hashed_key, scopes, expires_at)
+VALUES
+  ($1, $2, $3, $4, $5, $6, $7) RETURNING *;
+
+-- name: GetAPIKey :one
+SELECT * FROM api_keys
+WHERE id = $1;
+
+-- name: GetAPIKeyByHashedKey :one
+SELECT * FROM api_keys
+WHERE hashed_key = $1;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+WHERE username = $1
+ORDER BY created_at;
+
+-- name: DeleteAPIKey :exec
+DELETE FROM api_keys
+WHERE id = $1;
+
+-- name: DeleteUserAPIKeys :exec
+DELETE FROM api_keys
+WHERE username = $1;
@@ -0,0 +1,12 @@
+-- name: GetCurrency :one
+SELECT * FROM currencies
+WHERE code = $1;
+
+-- name: ListCurrencies :many
+SELECT * FROM currencies
+ORDER BY code;
+
+-- name: UpdateCurrencyEnabled :one
+UPDATE currencies
+SET is_enabled = $2
+WHERE code = $1 RETURNING *;
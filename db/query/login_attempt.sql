@@ -0,0 +1,17 @@
+-- name: CreateLoginAttempt :exec
+INSERT INTO
+  login_attempts (username, client_ip, succeeded)
+VALUES
+  ($1, $2, $3);
+
+-- name: CountFailedLoginAttempts :one
+SELECT count(*) FROM login_attempts
+WHERE username = sqlc.arg(username) AND NOT succeeded AND created_at > sqlc.arg(since)
+AND created_at > (
+  SELECT COALESCE(max(created_at), '-infinity') FROM login_attempts
+  WHERE username = sqlc.arg(username) AND succeeded
+);
+
+-- name: CountFailedLoginAttemptsByIP :one
+SELECT count(*) FROM login_attempts
+WHERE client_ip = sqlc.arg(client_ip) AND NOT succeeded AND created_at > sqlc.arg(since);
@@ -0,0 +1,15 @@
+-- name: CreateLoginLockout :one
+INSERT INTO
+  login_lockouts (username, client_ip, failed_attempts, locked_until)
+VALUES
+  ($1, $2, $3, $4) RETURNING *;
+
+-- name: GetActiveLoginLockout :one
+SELECT * FROM login_lockouts
+WHERE username = $1 AND locked_until > now()
+ORDER BY locked_until DESC
+LIMIT 1;
+
+-- name: CountLoginLockouts :one
+SELECT count(*) FROM login_lockouts
+WHERE username = sqlc.arg(username) AND created_at > sqlc.arg(since);
@@ -0,0 +1,16 @@
+-- name: CreatePasswordReset :one
+INSERT INTO
+  password_resets (username, hashed_code, expired_at)
+VALUES
+  ($1, $2, $3) RETURNING *;
+
+-- name: GetPasswordResetUser :one
+SELECT users.* FROM password_resets
+JOIN users ON users.username = password_resets.username
+WHERE password_resets.hashed_code = $1 AND NOT password_resets.is_used AND password_resets.expired_at > now();
+
+-- name: UsePasswordReset :one
+UPDATE password_resets
+SET is_used = true
+WHERE hashed_code = $1 AND NOT is_used AND expired_at > now()
+RETURNING *;
@@ -0,0 +1,27 @@
+-- name: ClaimToken :one
+INSERT INTO
+  revoked_tokens (id, username, expires_at)
+VALUES
+  ($1, $2, $3) ON CONFLICT (id) DO NOTHING
+RETURNING id;
+
+-- name: RevokeToken :exec
+INSERT INTO
+  revoked_tokens (id, username, expires_at)
+VALUES
+  ($1, $2, $3) ON CONFLICT (id) DO NOTHING;
+
+-- name: RevokeUserSessions :exec
+INSERT INTO
+  revoked_tokens (id, username, expires_at)
+SELECT
+  id, username, expires_at
+FROM sessions
+WHERE username = $1 AND expires_at > now()
+ON CONFLICT (id) DO NOTHING;
+
+-- name: IsTokenRevoked :one
+SELECT EXISTS (
+  SELECT 1 FROM revoked_tokens
+  WHERE id = sqlc.arg(id) OR id = sqlc.arg(session_id)
+);
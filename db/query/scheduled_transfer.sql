@@ -0,0 +1,40 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO
+  scheduled_transfers (owner, from_account_id, to_account_id, amount, frequency, frequency_interval, start_at, end_at, next_run_at)
+VALUES
+  ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+
+-- name: ClaimDueScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE next_run_at <= sqlc.arg(now)::timestamptz
+ORDER BY next_run_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  amount = COALESCE(sqlc.narg(amount), amount),
+  end_at = COALESCE(sqlc.narg(end_at), end_at),
+  next_run_at = CASE WHEN sqlc.narg(end_at)::timestamptz IS NOT NULL AND next_run_at > sqlc.narg(end_at) THEN NULL ELSE next_run_at END
+WHERE id = sqlc.arg(id) AND cancelled_at IS NULL RETURNING *;
+
+-- name: UpdateScheduledTransferNextRun :exec
+UPDATE scheduled_transfers
+SET next_run_at = $2
+WHERE id = $1;
+
+-- name: CancelScheduledTransfer :one
+UPDATE scheduled_transfers
+SET next_run_at = NULL, cancelled_at = now()
+WHERE id = $1 AND cancelled_at IS NULL RETURNING *;
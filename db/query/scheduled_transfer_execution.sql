@@ -0,0 +1,11 @@
+-- name: CreateScheduledTransferExecution :one
+INSERT INTO
+  scheduled_transfer_executions (scheduled_transfer_id, transfer_id, scheduled_for, error)
+VALUES
+  ($1, $2, $3, $4) RETURNING *;
+
+-- name: ListScheduledTransferExecutions :many
+SELECT * FROM scheduled_transfer_executions
+WHERE scheduled_transfer_id = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
@@ -0,0 +1,39 @@
+-- name: UpsertTOTPSecret :one
+INSERT INTO
+  totp_secrets (username, secret)
+VALUES
+  ($1, $2)
+ON CONFLICT (username) DO UPDATE
+SET secret = EXCLUDED.secret, confirmed_at = NULL, last_used_step = NULL, created_at = now()
+RETURNING *;
+
+-- name: GetTOTPSecret :one
+SELECT * FROM totp_secrets
+WHERE username = $1;
+
+-- name: ConfirmTOTPSecret :one
+UPDATE totp_secrets
+SET confirmed_at = now()
+WHERE username = $1 RETURNING *;
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO
+  recovery_codes (username, hashed_code)
+VALUES
+  ($1, $2);
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE username = $1;
+
+-- name: UseRecoveryCode :one
+UPDATE recovery_codes
+SET used_at = now()
+WHERE username = $1 AND hashed_code = $2 AND used_at IS NULL
+RETURNING *;
+
+-- name: UseTOTPStep :one
+UPDATE totp_secrets
+SET last_used_step = sqlc.arg(step)
+WHERE username = sqlc.arg(username) AND (last_used_step IS NULL OR last_used_step < sqlc.arg(step))
+RETURNING *;
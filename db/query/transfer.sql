@@ -1,22 +1,60 @@
 -- name: CreateTransfer :one
 INSERT INTO
-  transfers (from_account_id, to_account_id, amount)
+  transfers (from_account_id, to_account_id, amount, credited_amount, exchange_rate, exchange_rate_updated_at, reverses_transfer_id, status, hold_expires_at)
 VALUES
-  ($1, $2, $3) RETURNING *;
+  ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetReversedAmount :one
+SELECT
+  COALESCE(SUM(amount), 0)::bigint AS reversed_amount,
+  COALESCE(SUM(credited_amount), 0)::bigint AS refunded_amount
+FROM transfers
+WHERE reverses_transfer_id = sqlc.arg(transfer_id)::bigint;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
+WHERE
+  (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+  AND (sqlc.narg(direction)::varchar IS NULL
+    OR (sqlc.narg(direction) = 'out' AND from_account_id = sqlc.arg(account_id))
+    OR (sqlc.narg(direction) = 'in' AND to_account_id = sqlc.arg(account_id)))
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL
+    OR (from_account_id = sqlc.arg(account_id) AND to_account_id = sqlc.narg(counterparty_account_id))
+    OR (to_account_id = sqlc.arg(account_id) AND from_account_id = sqlc.narg(counterparty_account_id)))
+  AND (sqlc.narg(start_date)::timestamptz IS NULL OR created_at >= sqlc.narg(start_date))
+  AND (sqlc.narg(end_date)::timestamptz IS NULL OR created_at < sqlc.narg(end_date))
+  AND (sqlc.narg(min_amount)::bigint IS NULL
+    OR CASE WHEN from_account_id = sqlc.arg(account_id) THEN amount ELSE credited_amount END >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL
+    OR CASE WHEN from_account_id = sqlc.arg(account_id) THEN amount ELSE credited_amount END <= sqlc.narg(max_amount))
 ORDER BY id
-LIMIT $1 OFFSET $2;
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: UpdateTransfer :one
 UPDATE transfers
 SET amount = $2
 WHERE id = $1 RETURNING *;
 
+-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = $2, hold_expires_at = NULL
+WHERE id = $1 RETURNING *;
+
+-- name: ClaimExpiredTransferHold :one
+SELECT * FROM transfers
+WHERE status = 'pending' AND hold_expires_at <= sqlc.arg(now)::timestamptz
+ORDER BY hold_expires_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
 -- name: DeleteTransfer :exec
 DELETE FROM transfers WHERE id = $1;
@@ -6,4 +6,41 @@ VALUES
 
 -- name: GetUser :one
 SELECT * FROM users
-WHERE username = $1;
\ No newline at end of file
+WHERE username = $1;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1;
+
+-- name: GetUserPasswordChangedAt :one
+SELECT password_changed_at FROM users
+WHERE username = $1;
+
+-- name: UpdateUser :one
+UPDATE users
+SET
+  name = COALESCE(sqlc.narg(name), name),
+  last_name = COALESCE(sqlc.narg(last_name), last_name),
+  email = COALESCE(sqlc.narg(email), email),
+  is_email_verified = is_email_verified AND COALESCE(sqlc.narg(email), email) = email
+WHERE username = sqlc.arg(username) RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password = $2, password_changed_at = $3
+WHERE username = $1 RETURNING *;
+
+-- name: RehashUserPassword :exec
+UPDATE users
+SET hashed_password = sqlc.arg(hashed_password)
+WHERE username = sqlc.arg(username) AND hashed_password = sqlc.arg(old_hashed_password);
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE username = $1 RETURNING *;
+
+-- name: VerifyUserEmail :one
+UPDATE users
+SET is_email_verified = true
+WHERE username = $1 AND email = $2 RETURNING *;
@@ -0,0 +1,11 @@
+-- name: CreateVerifyEmail :one
+INSERT INTO
+  verify_emails (username, email, hashed_code, expired_at)
+VALUES
+  ($1, $2, $3, $4) RETURNING *;
+
+-- name: UseVerifyEmail :one
+UPDATE verify_emails
+SET is_used = true
+WHERE id = $1 AND hashed_code = $2 AND NOT is_used AND expired_at > now()
+RETURNING *;
@@ -9,10 +9,38 @@ import (
 	"context"
 )
 
+const addAccountAvailableBalance = `-- name: AddAccountAvailableBalance :one
+UPDATE accounts
+SET available_balance = available_balance + $1
+WHERE id = $2 AND ($1 >= 0 OR available_balance + $1 >= 0)
+RETURNING id, owner, balance, currency, created_at, is_frozen, available_balance
+`
+
+type AddAccountAvailableBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountAvailableBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.IsFrozen,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
-SET balance = balance + $1
-WHERE id = $2 RETURNING id, owner, balance, currency, created_at
+SET balance = balance + $1, available_balance = available_balance + $1
+WHERE id = $2 AND ($1 >= 0 OR available_balance + $1 >= 0)
+RETURNING id, owner, balance, currency, created_at, is_frozen, available_balance
 `
 
 type AddAccountBalanceParams struct {
@@ -29,15 +57,44 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.IsFrozen,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const captureAccountHold = `-- name: CaptureAccountHold :one
+UPDATE accounts
+SET balance = balance - $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, is_frozen, available_balance
+`
+
+type CaptureAccountHoldParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) CaptureAccountHold(ctx context.Context, arg CaptureAccountHoldParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, captureAccountHold, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.IsFrozen,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO
-  accounts (owner, balance, currency)
+  accounts (owner, balance, available_balance, currency)
 VALUES
-  ($1, $2, $3) RETURNING id, owner, balance, currency, created_at
+  ($1, $2, $2, $3) RETURNING id, owner, balance, currency, created_at, is_frozen, available_balance
 `
 
 type CreateAccountParams struct {
@@ -55,6 +112,8 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.IsFrozen,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
@@ -70,7 +129,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, is_frozen, available_balance FROM accounts
 WHERE id = $1
 `
 
@@ -83,12 +142,14 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.IsFrozen,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, is_frozen, available_balance FROM accounts
 WHERE id = $1
 FOR NO KEY UPDATE
 `
@@ -102,12 +163,14 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.IsFrozen,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const listAccountsByOwner = `-- name: ListAccountsByOwner :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, is_frozen, available_balance FROM accounts
 WHERE owner = $1
 ORDER BY id
 LIMIT $2 OFFSET $3
@@ -134,6 +197,8 @@ func (q *Queries) ListAccountsByOwner(ctx context.Context, arg ListAccountsByOwn
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.IsFrozen,
+			&i.AvailableBalance,
 		); err != nil {
 			return nil, err
 		}
@@ -150,8 +215,8 @@ func (q *Queries) ListAccountsByOwner(ctx context.Context, arg ListAccountsByOwn
 
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
-SET balance = $2
-WHERE id = $1 RETURNING id, owner, balance, currency, created_at
+SET available_balance = available_balance + $2 - balance, balance = $2
+WHERE id = $1 RETURNING id, owner, balance, currency, created_at, is_frozen, available_balance
 `
 
 type UpdateAccountParams struct {
@@ -168,6 +233,34 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.IsFrozen,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const updateAccountFrozen = `-- name: UpdateAccountFrozen :one
+UPDATE accounts
+SET is_frozen = $2
+WHERE id = $1 RETURNING id, owner, balance, currency, created_at, is_frozen, available_balance
+`
+
+type UpdateAccountFrozenParams struct {
+	ID       int64 `json:"id"`
+	IsFrozen bool  `json:"is_frozen"`
+}
+
+func (q *Queries) UpdateAccountFrozen(ctx context.Context, arg UpdateAccountFrozenParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountFrozen, arg.ID, arg.IsFrozen)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.IsFrozen,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
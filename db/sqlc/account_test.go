@@ -10,11 +10,15 @@ import (
 )
 
 func createRandomAccount(t *testing.T) (account Account) {
+	return createRandomAccountWithBalance(t, util.RandomAmount())
+}
+
+func createRandomAccountWithBalance(t *testing.T, balance int64) (account Account) {
 	user := createRandomUser(t)
 
 	arg := CreateAccountParams{
 		Owner:    user.Username,
-		Balance:  util.RandomAmount(),
+		Balance:  balance,
 		Currency: util.RandomCurrency(),
 	}
 
@@ -26,7 +30,9 @@ func createRandomAccount(t *testing.T) (account Account) {
 	require.NotZero(t, account.ID)
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
+	require.Equal(t, arg.Balance, account.AvailableBalance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.False(t, account.IsFrozen)
 	require.NotZero(t, account.CreatedAt)
 
 	return
@@ -47,6 +53,31 @@ func TestGetAccount(t *testing.T) {
 	require.Exactly(t, account, foundAccount)
 }
 
+func TestAddAccountBalance(t *testing.T) {
+	account := createRandomAccountWithBalance(t, 10)
+
+	updatedAccount, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		Amount: -10,
+		ID:     account.ID,
+	})
+	require.NoError(t, err)
+	require.Zero(t, updatedAccount.Balance)
+
+	// money can't be taken out of accounts whose balance doesn't cover it
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		Amount: -1,
+		ID:     account.ID,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	updatedAccount, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		Amount: 5,
+		ID:     account.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), updatedAccount.Balance)
+}
+
 func TestGetAccountForUpdate(t *testing.T) {
 	account := createRandomAccount(t)
 
@@ -117,3 +148,17 @@ func TestDeleteAccount(t *testing.T) {
 	require.EqualError(t, err, sql.ErrNoRows.Error())
 	require.Empty(t, foundAccount)
 }
+
+func TestUpdateAccountFrozen(t *testing.T) {
+	account := createRandomAccount(t)
+
+	frozenAccount, err := testQueries.UpdateAccountFrozen(context.Background(), UpdateAccountFrozenParams{
+		ID:       account.ID,
+		IsFrozen: true,
+	})
+
+	require.NoError(t, err)
+
+	account.IsFrozen = true
+	require.Exactly(t, account, frozenAccount)
+}
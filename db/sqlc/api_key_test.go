@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAPIKey(t *testing.T, user User) (apiKey ApiKey) {
+	key, err := util.GenerateAPIKey()
+	require.NoError(t, err)
+
+	arg := CreateAPIKeyParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		Name:      util.RandomString(8),
+		KeyPrefix: key[:12],
+		HashedKey: util.HashAPIKey(key),
+		Scopes:    []string{"accounts:read", "transfers:create"},
+		ExpiresAt: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	apiKey, err = testQueries.CreateAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, apiKey)
+
+	require.Equal(t, arg.ID, apiKey.ID)
+	require.Equal(t, arg.Username, apiKey.Username)
+	require.Equal(t, arg.Name, apiKey.Name)
+	require.Equal(t, arg.KeyPrefix, apiKey.KeyPrefix)
+	require.Equal(t, arg.HashedKey, apiKey.HashedKey)
+	require.Equal(t, arg.Scopes, apiKey.Scopes)
+	require.True(t, apiKey.ExpiresAt.Valid)
+	require.WithinDuration(t, arg.ExpiresAt.Time, apiKey.ExpiresAt.Time, time.Second)
+	require.NotZero(t, apiKey.CreatedAt)
+
+	return
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	createRandomAPIKey(t, createRandomUser(t))
+}
+
+func TestGetAPIKey(t *testing.T) {
+	apiKey := createRandomAPIKey(t, createRandomUser(t))
+
+	foundAPIKey, err := testQueries.GetAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+	require.Exactly(t, apiKey, foundAPIKey)
+
+	foundAPIKey, err = testQueries.GetAPIKeyByHashedKey(context.Background(), apiKey.HashedKey)
+	require.NoError(t, err)
+	require.Exactly(t, apiKey, foundAPIKey)
+}
+
+func TestListAPIKeys(t *testing.T) {
+	user := createRandomUser(t)
+
+	for i := 0; i < 3; i++ {
+		createRandomAPIKey(t, user)
+	}
+
+	apiKeys, err := testQueries.ListAPIKeys(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, apiKeys, 3)
+
+	for _, apiKey := range apiKeys {
+		require.Equal(t, user.Username, apiKey.Username)
+	}
+}
+
+func TestDeleteAPIKey(t *testing.T) {
+	apiKey := createRandomAPIKey(t, createRandomUser(t))
+
+	err := testQueries.DeleteAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+
+	foundAPIKey, err := testQueries.GetAPIKey(context.Background(), apiKey.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+	require.Empty(t, foundAPIKey)
+}
+
+func TestDeleteUserAPIKeys(t *testing.T) {
+	user := createRandomUser(t)
+	apiKeys := []ApiKey{createRandomAPIKey(t, user), createRandomAPIKey(t, user)}
+	anotherAPIKey := createRandomAPIKey(t, createRandomUser(t))
+
+	err := testQueries.DeleteUserAPIKeys(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	for _, apiKey := range apiKeys {
+		_, err = testQueries.GetAPIKey(context.Background(), apiKey.ID)
+		require.EqualError(t, err, sql.ErrNoRows.Error())
+	}
+
+	// keys of other users are kept
+	_, err = testQueries.GetAPIKey(context.Background(), anotherAPIKey.ID)
+	require.NoError(t, err)
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCurrencies(t *testing.T) {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+
+	codes := make([]string, len(currencies))
+
+	for i, currency := range currencies {
+		codes[i] = currency.Code
+	}
+
+	require.IsIncreasing(t, codes)
+
+	for _, defaultCurrency := range util.DefaultCurrencies {
+		require.Contains(t, codes, defaultCurrency.Code)
+	}
+}
+
+func TestGetCurrency(t *testing.T) {
+	currency, err := testQueries.GetCurrency(context.Background(), util.JPY)
+	require.NoError(t, err)
+	require.Equal(t, util.JPY, currency.Code)
+	require.Equal(t, int32(392), currency.NumericCode)
+	require.Equal(t, int32(0), currency.Exponent)
+	require.Equal(t, "¥", currency.Symbol)
+
+	_, err = testQueries.GetCurrency(context.Background(), "XYZ")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUpdateCurrencyEnabled(t *testing.T) {
+	currency, err := testQueries.GetCurrency(context.Background(), util.GBP)
+	require.NoError(t, err)
+
+	// restore the seeded state, since other tests rely on it
+	defer func() {
+		_, err := testQueries.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{
+			Code:      currency.Code,
+			IsEnabled: currency.IsEnabled,
+		})
+		require.NoError(t, err)
+	}()
+
+	updatedCurrency, err := testQueries.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{
+		Code:      currency.Code,
+		IsEnabled: !currency.IsEnabled,
+	})
+	require.NoError(t, err)
+
+	currency.IsEnabled = !currency.IsEnabled
+	require.Exactly(t, currency, updatedCurrency)
+
+	_, err = testQueries.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{
+		Code:      "XYZ",
+		IsEnabled: true,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
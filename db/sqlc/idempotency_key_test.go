@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomIdempotencyKey(t *testing.T, user User) IdempotencyKey {
+	arg := CreateIdempotencyKeyParams{
+		Username:    user.Username,
+		Key:         util.RandomString(32),
+		RequestHash: util.HashSecretCode(util.RandomString(32)),
+		Response:    []byte(`{"amount": 10}`),
+	}
+
+	idempotencyKey, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.Username, idempotencyKey.Username)
+	require.Equal(t, arg.Key, idempotencyKey.Key)
+	require.Equal(t, arg.RequestHash, idempotencyKey.RequestHash)
+	require.JSONEq(t, string(arg.Response), string(idempotencyKey.Response))
+	require.NotZero(t, idempotencyKey.CreatedAt)
+
+	return idempotencyKey
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	createRandomIdempotencyKey(t, createRandomUser(t))
+}
+
+func TestGetIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	idempotencyKey := createRandomIdempotencyKey(t, user)
+
+	foundKey, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: user.Username,
+		Key:      idempotencyKey.Key,
+	})
+	require.NoError(t, err)
+	require.Exactly(t, idempotencyKey, foundKey)
+
+	// keys belong to the user who sent them
+	_, err = testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: createRandomUser(t).Username,
+		Key:      idempotencyKey.Key,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
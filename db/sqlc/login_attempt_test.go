@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func recordLoginAttempt(t *testing.T, username, clientIP string, succeeded bool) {
+	err := testQueries.CreateLoginAttempt(context.Background(), CreateLoginAttemptParams{
+		Username:  username,
+		ClientIp:  clientIP,
+		Succeeded: succeeded,
+	})
+	require.NoError(t, err)
+}
+
+func TestCountFailedLoginAttempts(t *testing.T) {
+	user := createRandomUser(t)
+	clientIP := util.RandomString(12)
+	since := time.Now().Add(-time.Minute)
+
+	for i := 0; i < 3; i++ {
+		recordLoginAttempt(t, user.Username, clientIP, false)
+	}
+
+	count, err := testQueries.CountFailedLoginAttempts(context.Background(), CountFailedLoginAttemptsParams{
+		Username: user.Username,
+		Since:    since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+
+	// a successful login resets the count for the username
+	recordLoginAttempt(t, user.Username, clientIP, true)
+	recordLoginAttempt(t, user.Username, clientIP, false)
+
+	count, err = testQueries.CountFailedLoginAttempts(context.Background(), CountFailedLoginAttemptsParams{
+		Username: user.Username,
+		Since:    since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	// but not for the client IP
+	count, err = testQueries.CountFailedLoginAttemptsByIP(context.Background(), CountFailedLoginAttemptsByIPParams{
+		ClientIp: clientIP,
+		Since:    since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), count)
+}
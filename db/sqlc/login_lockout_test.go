@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomLoginLockout(t *testing.T, user User, lockedUntil time.Time) (lockout LoginLockout) {
+	arg := CreateLoginLockoutParams{
+		Username:       user.Username,
+		ClientIp:       util.RandomString(12),
+		FailedAttempts: 5,
+		LockedUntil:    lockedUntil,
+	}
+
+	lockout, err := testQueries.CreateLoginLockout(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, lockout)
+
+	require.NotZero(t, lockout.ID)
+	require.Equal(t, arg.Username, lockout.Username)
+	require.Equal(t, arg.ClientIp, lockout.ClientIp)
+	require.Equal(t, arg.FailedAttempts, lockout.FailedAttempts)
+	require.WithinDuration(t, arg.LockedUntil, lockout.LockedUntil, time.Second)
+	require.NotZero(t, lockout.CreatedAt)
+
+	return
+}
+
+func TestCreateLoginLockout(t *testing.T) {
+	createRandomLoginLockout(t, createRandomUser(t), time.Now().Add(time.Minute))
+}
+
+func TestGetActiveLoginLockout(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testQueries.GetActiveLoginLockout(context.Background(), user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	createRandomLoginLockout(t, user, time.Now().Add(-time.Minute))
+
+	_, err = testQueries.GetActiveLoginLockout(context.Background(), user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	lockout := createRandomLoginLockout(t, user, time.Now().Add(time.Minute))
+
+	foundLockout, err := testQueries.GetActiveLoginLockout(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Exactly(t, lockout, foundLockout)
+}
+
+func TestCountLoginLockouts(t *testing.T) {
+	user := createRandomUser(t)
+	since := time.Now().Add(-time.Minute)
+
+	for i := 0; i < 2; i++ {
+		createRandomLoginLockout(t, user, time.Now().Add(time.Minute))
+	}
+
+	count, err := testQueries.CountLoginLockouts(context.Background(), CountLoginLockoutsParams{
+		Username: user.Username,
+		Since:    since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+}
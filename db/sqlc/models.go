@@ -5,15 +5,47 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
-	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	// posted balance, which pending transfers are not debited from yet
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	IsFrozen  bool      `json:"is_frozen"`
+	// balance minus the amount held by pending transfers
+	AvailableBalance int64 `json:"available_balance"`
+}
+
+type ApiKey struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	KeyPrefix string    `json:"key_prefix"`
+	// SHA-256 of the key, which is only shown once
+	HashedKey string   `json:"hashed_key"`
+	Scopes    []string `json:"scopes"`
+	// keys without expiration last until revoked
+	ExpiresAt sql.NullTime `json:"expires_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type Currency struct {
+	// ISO 4217 alphabetic code
+	Code        string `json:"code"`
+	NumericCode int32  `json:"numeric_code"`
+	// number of digits of the minor unit amounts are stored in
+	Exponent  int32     `json:"exponent"`
+	Symbol    string    `json:"symbol"`
+	IsEnabled bool      `json:"is_enabled"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -24,13 +56,128 @@ type Entry struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type IdempotencyKey struct {
+	Username string `json:"username"`
+	Key      string `json:"key"`
+	// keys can only be reused for requests with the same hash
+	RequestHash string          `json:"request_hash"`
+	Response    json.RawMessage `json:"response"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type LoginAttempt struct {
+	ID int64 `json:"id"`
+	// not a foreign key, as attempts for unknown users are tracked too
+	Username  string    `json:"username"`
+	ClientIp  string    `json:"client_ip"`
+	Succeeded bool      `json:"succeeded"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LoginLockout struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// client of the attempt that triggered the lockout
+	ClientIp       string    `json:"client_ip"`
+	FailedAttempts int32     `json:"failed_attempts"`
+	LockedUntil    time.Time `json:"locked_until"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type PasswordReset struct {
+	ID         int64     `json:"id"`
+	Username   string    `json:"username"`
+	HashedCode string    `json:"hashed_code"`
+	IsUsed     bool      `json:"is_used"`
+	ExpiredAt  time.Time `json:"expired_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID         int64        `json:"id"`
+	Username   string       `json:"username"`
+	HashedCode string       `json:"hashed_code"`
+	UsedAt     sql.NullTime `json:"used_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type RevokedToken struct {
+	// either an access token ID or a session ID
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	// must be positive
+	Amount int64 `json:"amount"`
+	// once, daily, weekly or monthly
+	Frequency string `json:"frequency"`
+	// how many days, weeks or months apart runs are
+	FrequencyInterval int32        `json:"frequency_interval"`
+	StartAt           time.Time    `json:"start_at"`
+	EndAt             sql.NullTime `json:"end_at"`
+	// NULL once the schedule has no more runs or is cancelled
+	NextRunAt   sql.NullTime `json:"next_run_at"`
+	CancelledAt sql.NullTime `json:"cancelled_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+type ScheduledTransferExecution struct {
+	ID                  int64 `json:"id"`
+	ScheduledTransferID int64 `json:"scheduled_transfer_id"`
+	// NULL when the execution failed
+	TransferID   sql.NullInt64 `json:"transfer_id"`
+	ScheduledFor time.Time     `json:"scheduled_for"`
+	// why the execution failed, if it did
+	Error      sql.NullString `json:"error"`
+	ExecutedAt time.Time      `json:"executed_at"`
+}
+
+type Session struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	RefreshToken string    `json:"refresh_token"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+	IsBlocked    bool      `json:"is_blocked"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type TotpSecret struct {
+	Username string `json:"username"`
+	Secret   string `json:"secret"`
+	// two-factor authentication is only enforced once the secret is confirmed with a first code
+	ConfirmedAt sql.NullTime `json:"confirmed_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+	// time step of the last code used, so a code can't be replayed within its window
+	LastUsedStep sql.NullInt64 `json:"last_used_step"`
+}
+
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
-	// must be positive
+	// debited from the from account, in its currency; must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// credited to the to account, in its currency
+	CreditedAmount int64 `json:"credited_amount"`
+	// applied to the amount to get the credited amount
+	ExchangeRate          float64   `json:"exchange_rate"`
+	ExchangeRateUpdatedAt time.Time `json:"exchange_rate_updated_at"`
+	// the transfer this one gives money back from, if it is a reversal
+	ReversesTransferID sql.NullInt64 `json:"reverses_transfer_id"`
+	// pending, posted or voided
+	Status string `json:"status"`
+	// when a pending transfer is voided if not captured
+	HoldExpiresAt sql.NullTime `json:"hold_expires_at"`
 }
 
 type User struct {
@@ -41,4 +188,18 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	// depositor, banker or admin
+	Role            string `json:"role"`
+	IsEmailVerified bool   `json:"is_email_verified"`
+}
+
+type VerifyEmail struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// the address the code was sent to, only verified if it is still the email of the user
+	Email      string    `json:"email"`
+	HashedCode string    `json:"hashed_code"`
+	IsUsed     bool      `json:"is_used"`
+	ExpiredAt  time.Time `json:"expired_at"`
+	CreatedAt  time.Time `json:"created_at"`
 }
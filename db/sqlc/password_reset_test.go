@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPasswordReset(t *testing.T, user User, expiredAt time.Time) (passwordReset PasswordReset, code string) {
+	code, err := util.GenerateSecretCode()
+	require.NoError(t, err)
+
+	arg := CreatePasswordResetParams{
+		Username:   user.Username,
+		HashedCode: util.HashSecretCode(code),
+		ExpiredAt:  expiredAt,
+	}
+
+	passwordReset, err = testQueries.CreatePasswordReset(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, passwordReset)
+
+	require.NotZero(t, passwordReset.ID)
+	require.Equal(t, arg.Username, passwordReset.Username)
+	require.Equal(t, arg.HashedCode, passwordReset.HashedCode)
+	require.False(t, passwordReset.IsUsed)
+	require.WithinDuration(t, arg.ExpiredAt, passwordReset.ExpiredAt, time.Second)
+	require.NotZero(t, passwordReset.CreatedAt)
+
+	return
+}
+
+func TestCreatePasswordReset(t *testing.T) {
+	createRandomPasswordReset(t, createRandomUser(t), time.Now().Add(time.Hour))
+}
+
+func TestGetPasswordResetUser(t *testing.T) {
+	user := createRandomUser(t)
+	_, code := createRandomPasswordReset(t, user, time.Now().Add(time.Hour))
+
+	foundUser, err := testQueries.GetPasswordResetUser(context.Background(), util.HashSecretCode(code))
+	require.NoError(t, err)
+	require.Exactly(t, user, foundUser)
+
+	_, err = testQueries.UsePasswordReset(context.Background(), util.HashSecretCode(code))
+	require.NoError(t, err)
+
+	// used codes don't point to a user anymore
+	_, err = testQueries.GetPasswordResetUser(context.Background(), util.HashSecretCode(code))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUsePasswordReset(t *testing.T) {
+	_, code := createRandomPasswordReset(t, createRandomUser(t), time.Now().Add(time.Hour))
+
+	usedPasswordReset, err := testQueries.UsePasswordReset(context.Background(), util.HashSecretCode(code))
+	require.NoError(t, err)
+	require.True(t, usedPasswordReset.IsUsed)
+
+	// codes can only be used once
+	_, err = testQueries.UsePasswordReset(context.Background(), util.HashSecretCode(code))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUseExpiredPasswordReset(t *testing.T) {
+	_, code := createRandomPasswordReset(t, createRandomUser(t), time.Now().Add(-time.Minute))
+
+	_, err := testQueries.UsePasswordReset(context.Background(), util.HashSecretCode(code))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -6,28 +6,91 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	BlockSession(ctx context.Context, id uuid.UUID) (Session, error)
+	BlockUserSessions(ctx context.Context, username string) error
+	CancelScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	CaptureAccountHold(ctx context.Context, arg CaptureAccountHoldParams) (Account, error)
+	ClaimDueScheduledTransfer(ctx context.Context, now time.Time) (ScheduledTransfer, error)
+	ClaimExpiredTransferHold(ctx context.Context, now time.Time) (Transfer, error)
+	ClaimToken(ctx context.Context, arg ClaimTokenParams) (uuid.UUID, error)
+	ConfirmTOTPSecret(ctx context.Context, username string) (TotpSecret, error)
+	CountFailedLoginAttempts(ctx context.Context, arg CountFailedLoginAttemptsParams) (int64, error)
+	CountFailedLoginAttemptsByIP(ctx context.Context, arg CountFailedLoginAttemptsByIPParams) (int64, error)
+	CountLoginLockouts(ctx context.Context, arg CountLoginLockoutsParams) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateLoginAttempt(ctx context.Context, arg CreateLoginAttemptParams) error
+	CreateLoginLockout(ctx context.Context, arg CreateLoginLockoutParams) (LoginLockout, error)
+	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferExecution(ctx context.Context, arg CreateScheduledTransferExecutionParams) (ScheduledTransferExecution, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
+	DeleteAPIKey(ctx context.Context, id uuid.UUID) error
 	DeleteAccount(ctx context.Context, id int64) error
 	DeleteEntry(ctx context.Context, id int64) error
+	DeleteRecoveryCodes(ctx context.Context, username string) error
 	DeleteTransfer(ctx context.Context, id int64) error
+	DeleteUserAPIKeys(ctx context.Context, username string) error
+	GetAPIKey(ctx context.Context, id uuid.UUID) (ApiKey, error)
+	GetAPIKeyByHashedKey(ctx context.Context, hashedKey string) (ApiKey, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetActiveLoginLockout(ctx context.Context, username string) (LoginLockout, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetPasswordResetUser(ctx context.Context, hashedCode string) (User, error)
+	GetReversedAmount(ctx context.Context, transferID int64) (GetReversedAmountRow, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetTOTPSecret(ctx context.Context, username string) (TotpSecret, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserPasswordChangedAt(ctx context.Context, username string) (time.Time, error)
+	IsTokenRevoked(ctx context.Context, arg IsTokenRevokedParams) (bool, error)
+	ListAPIKeys(ctx context.Context, username string) ([]ApiKey, error)
 	ListAccountsByOwner(ctx context.Context, arg ListAccountsByOwnerParams) ([]Account, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListScheduledTransferExecutions(ctx context.Context, arg ListScheduledTransferExecutionsParams) ([]ScheduledTransferExecution, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) error
+	RevokeToken(ctx context.Context, arg RevokeTokenParams) error
+	RevokeUserSessions(ctx context.Context, username string) error
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountFrozen(ctx context.Context, arg UpdateAccountFrozenParams) (Account, error)
+	UpdateCurrencyEnabled(ctx context.Context, arg UpdateCurrencyEnabledParams) (Currency, error)
 	UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateScheduledTransferNextRun(ctx context.Context, arg UpdateScheduledTransferNextRunParams) error
 	UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error)
+	UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error)
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpsertTOTPSecret(ctx context.Context, arg UpsertTOTPSecretParams) (TotpSecret, error)
+	UsePasswordReset(ctx context.Context, hashedCode string) (PasswordReset, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error)
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (TotpSecret, error)
+	UseVerifyEmail(ctx context.Context, arg UseVerifyEmailParams) (VerifyEmail, error)
+	VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
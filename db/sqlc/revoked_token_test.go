@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeToken(t *testing.T) {
+	user := createRandomUser(t)
+	tokenID := uuid.New()
+
+	isRevoked, err := testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{ID: tokenID})
+	require.NoError(t, err)
+	require.False(t, isRevoked)
+
+	arg := RevokeTokenParams{
+		ID:        tokenID,
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	err = testQueries.RevokeToken(context.Background(), arg)
+	require.NoError(t, err)
+
+	// revoking the same token twice must not fail
+	err = testQueries.RevokeToken(context.Background(), arg)
+	require.NoError(t, err)
+
+	isRevoked, err = testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{ID: tokenID})
+	require.NoError(t, err)
+	require.True(t, isRevoked)
+}
+
+func TestClaimToken(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := ClaimTokenParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	claimedID, err := testQueries.ClaimToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, claimedID)
+
+	isRevoked, err := testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{ID: arg.ID})
+	require.NoError(t, err)
+	require.True(t, isRevoked)
+
+	// a token can only be claimed once
+	_, err = testQueries.ClaimToken(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestIsTokenRevokedBySession(t *testing.T) {
+	session := createRandomSession(t)
+
+	err := testQueries.RevokeToken(context.Background(), RevokeTokenParams{
+		ID:        session.ID,
+		Username:  session.Username,
+		ExpiresAt: session.ExpiresAt,
+	})
+	require.NoError(t, err)
+
+	isRevoked, err := testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:        uuid.New(),
+		SessionID: session.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, isRevoked)
+}
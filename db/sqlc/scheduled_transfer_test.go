@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, fromAccount Account, toAccount Account, frequency string, startAt time.Time) (scheduledTransfer ScheduledTransfer) {
+	arg := CreateScheduledTransferParams{
+		Owner:             fromAccount.Owner,
+		FromAccountID:     fromAccount.ID,
+		ToAccountID:       toAccount.ID,
+		Amount:            util.RandomInt(1, 10),
+		Frequency:         frequency,
+		FrequencyInterval: 1,
+		StartAt:           startAt,
+		NextRunAt:         sql.NullTime{Time: startAt, Valid: true},
+	}
+
+	scheduledTransfer, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, scheduledTransfer.ID)
+
+	require.Equal(t, arg.Owner, scheduledTransfer.Owner)
+	require.Equal(t, arg.FromAccountID, scheduledTransfer.FromAccountID)
+	require.Equal(t, arg.ToAccountID, scheduledTransfer.ToAccountID)
+	require.Equal(t, arg.Amount, scheduledTransfer.Amount)
+	require.Equal(t, arg.Frequency, scheduledTransfer.Frequency)
+	require.Equal(t, arg.FrequencyInterval, scheduledTransfer.FrequencyInterval)
+	require.WithinDuration(t, arg.StartAt, scheduledTransfer.StartAt, time.Second)
+	require.False(t, scheduledTransfer.EndAt.Valid)
+	require.WithinDuration(t, arg.NextRunAt.Time, scheduledTransfer.NextRunAt.Time, time.Second)
+	require.False(t, scheduledTransfer.CancelledAt.Valid)
+	require.NotZero(t, scheduledTransfer.CreatedAt)
+
+	return
+}
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	createRandomScheduledTransfer(t, createRandomAccount(t), createRandomAccount(t), util.FrequencyOnce, time.Now().Add(time.Hour))
+}
+
+func TestGetScheduledTransfer(t *testing.T) {
+	scheduledTransfer := createRandomScheduledTransfer(t, createRandomAccount(t), createRandomAccount(t), util.FrequencyOnce, time.Now().Add(time.Hour))
+
+	foundScheduledTransfer, err := testQueries.GetScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Exactly(t, scheduledTransfer, foundScheduledTransfer)
+}
+
+func TestListScheduledTransfers(t *testing.T) {
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccount(t)
+
+	for i := 0; i < 3; i++ {
+		createRandomScheduledTransfer(t, fromAccount, toAccount, util.FrequencyDaily, time.Now().Add(time.Hour))
+	}
+
+	scheduledTransfers, err := testQueries.ListScheduledTransfers(context.Background(), ListScheduledTransfersParams{
+		Owner:  fromAccount.Owner,
+		Limit:  2,
+		Offset: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, scheduledTransfers, 2)
+
+	for _, scheduledTransfer := range scheduledTransfers {
+		require.Equal(t, fromAccount.Owner, scheduledTransfer.Owner)
+	}
+}
+
+func TestUpdateScheduledTransfer(t *testing.T) {
+	scheduledTransfer := createRandomScheduledTransfer(t, createRandomAccount(t), createRandomAccount(t), util.FrequencyWeekly, time.Now().Add(time.Hour))
+
+	arg := UpdateScheduledTransferParams{
+		ID:     scheduledTransfer.ID,
+		Amount: sql.NullInt64{Int64: scheduledTransfer.Amount + 1, Valid: true},
+		EndAt:  sql.NullTime{Time: scheduledTransfer.StartAt.AddDate(0, 1, 0), Valid: true},
+	}
+
+	updatedScheduledTransfer, err := testQueries.UpdateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Amount.Int64, updatedScheduledTransfer.Amount)
+	require.WithinDuration(t, arg.EndAt.Time, updatedScheduledTransfer.EndAt.Time, time.Second)
+	require.Equal(t, scheduledTransfer.NextRunAt, updatedScheduledTransfer.NextRunAt)
+
+	// fields left as NULL are kept
+	updatedScheduledTransfer, err = testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     scheduledTransfer.ID,
+		Amount: sql.NullInt64{Int64: scheduledTransfer.Amount + 2, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, scheduledTransfer.Amount+2, updatedScheduledTransfer.Amount)
+	require.WithinDuration(t, arg.EndAt.Time, updatedScheduledTransfer.EndAt.Time, time.Second)
+
+	// the next run is dropped when the schedule ends before it
+	updatedScheduledTransfer, err = testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:    scheduledTransfer.ID,
+		EndAt: sql.NullTime{Time: scheduledTransfer.StartAt, Valid: true},
+	})
+	require.NoError(t, err)
+	require.True(t, updatedScheduledTransfer.NextRunAt.Valid)
+
+	updatedScheduledTransfer, err = testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:    scheduledTransfer.ID,
+		EndAt: sql.NullTime{Time: scheduledTransfer.StartAt.Add(-time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+	require.False(t, updatedScheduledTransfer.NextRunAt.Valid)
+
+	_, err = testQueries.CancelScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+
+	// cancelled scheduled transfers can't be updated
+	_, err = testQueries.UpdateScheduledTransfer(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUpdateScheduledTransferAfterRun(t *testing.T) {
+	scheduledTransfer := createRandomScheduledTransfer(t, createRandomAccount(t), createRandomAccount(t), util.FrequencyWeekly, time.Now().Add(time.Hour))
+
+	// read by the handler before the executor runs the transfer and moves its next run forward
+	readScheduledTransfer, err := testQueries.GetScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+
+	nextRunAt := readScheduledTransfer.NextRunAt.Time.AddDate(0, 0, 7)
+
+	err = testQueries.UpdateScheduledTransferNextRun(context.Background(), UpdateScheduledTransferNextRunParams{
+		ID:        scheduledTransfer.ID,
+		NextRunAt: sql.NullTime{Time: nextRunAt, Valid: true},
+	})
+	require.NoError(t, err)
+
+	updatedScheduledTransfer, err := testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     readScheduledTransfer.ID,
+		Amount: sql.NullInt64{Int64: readScheduledTransfer.Amount + 1, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// the run that was already made isn't due again
+	require.True(t, updatedScheduledTransfer.NextRunAt.Valid)
+	require.WithinDuration(t, nextRunAt, updatedScheduledTransfer.NextRunAt.Time, time.Second)
+}
+
+func TestCancelScheduledTransfer(t *testing.T) {
+	scheduledTransfer := createRandomScheduledTransfer(t, createRandomAccount(t), createRandomAccount(t), util.FrequencyMonthly, time.Now().Add(time.Hour))
+
+	cancelledScheduledTransfer, err := testQueries.CancelScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.False(t, cancelledScheduledTransfer.NextRunAt.Valid)
+	require.True(t, cancelledScheduledTransfer.CancelledAt.Valid)
+
+	_, err = testQueries.CancelScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCreateScheduledTransferExecution(t *testing.T) {
+	scheduledTransfer := createRandomScheduledTransfer(t, createRandomAccount(t), createRandomAccount(t), util.FrequencyDaily, time.Now().Add(time.Hour))
+
+	arg := CreateScheduledTransferExecutionParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		ScheduledFor:        scheduledTransfer.StartAt,
+		Error:               sql.NullString{String: ErrInsufficientFunds.Error(), Valid: true},
+	}
+
+	execution, err := testQueries.CreateScheduledTransferExecution(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, scheduledTransfer.ID, execution.ScheduledTransferID)
+	require.False(t, execution.TransferID.Valid)
+	require.Equal(t, arg.Error, execution.Error)
+	require.NotZero(t, execution.ExecutedAt)
+
+	executions, err := testQueries.ListScheduledTransferExecutions(context.Background(), ListScheduledTransferExecutionsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               5,
+		Offset:              0,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []ScheduledTransferExecution{execution}, executions)
+}
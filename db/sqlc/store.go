@@ -3,13 +3,37 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/google/uuid"
 )
 
 // Store provides all functions to execute db queries and transactions
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	RevokeSessionTx(ctx context.Context, arg RevokeSessionTxParams) error
+	RevokeAllSessionsTx(ctx context.Context, arg RevokeAllSessionsTxParams) error
+	UpdateUserRoleTx(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	ConfirmTOTPTx(ctx context.Context, arg ConfirmTOTPTxParams) (TotpSecret, error)
+	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
+	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error)
+	UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error)
+	ChangePasswordTx(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (TransferTxResult, error)
+	AuthorizeTransferTx(ctx context.Context, arg AuthorizeTransferTxParams) (TransferTxResult, error)
+	CaptureTransferTx(ctx context.Context, arg CaptureTransferTxParams) (TransferTxResult, error)
+	VoidTransferTx(ctx context.Context, arg VoidTransferTxParams) (TransferTxResult, error)
+	VoidExpiredTransferTx(ctx context.Context, arg VoidExpiredTransferTxParams) (TransferTxResult, error)
+	BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error)
 }
 
 // SQLStore provies all functions to execute SQL queries and transactions
@@ -36,7 +60,7 @@ func (store *SQLStore) execTx(ctx context.Context, callback func(*Queries) error
 
 	if err = callback(queries); err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("Transaction error: %v, Rollback error: %v", err, rbErr)
+			return fmt.Errorf("Transaction error: %w, Rollback error: %v", err, rbErr)
 		}
 
 		return err
@@ -45,10 +69,27 @@ func (store *SQLStore) execTx(ctx context.Context, callback func(*Queries) error
 	return tx.Commit()
 }
 
+// ErrInsufficientFunds is returned by TransferTx when the available balance of the from account can't cover the transfer
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
 type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// CreditedAmount is what the to account receives, in its currency.
+	// Along with the exchange rate, it defaults to the amount, as for accounts with the same currency
+	CreditedAmount        int64     `json:"credited_amount"`
+	ExchangeRate          float64   `json:"exchange_rate"`
+	ExchangeRateUpdatedAt time.Time `json:"exchange_rate_updated_at"`
+	// IdempotencyKey is saved with the result in the same transaction when set,
+	// so a retried request can't make the transfer twice
+	IdempotencyKey *TransferIdempotencyKey `json:"idempotency_key,omitempty"`
+}
+
+type TransferIdempotencyKey struct {
+	Username    string `json:"username"`
+	Key         string `json:"key"`
+	RequestHash string `json:"request_hash"`
 }
 
 type TransferTxResult struct {
@@ -59,25 +100,377 @@ type TransferTxResult struct {
 	ToEntry     Entry    `json:"toEntry"`
 }
 
+// TransferTx moves money between the accounts, failing with ErrInsufficientFunds instead of leaving the from account with a negative balance
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
 
+		result, err = transfer(ctx, q, arg, sql.NullInt64{})
+
+		if err != nil {
+			return err
+		}
+
+		return saveIdempotencyKey(ctx, q, arg.IdempotencyKey, result)
+	})
+
+	return result, err
+}
+
+// saveIdempotencyKey saves the result of a transfer with its idempotency key, if any
+func saveIdempotencyKey(ctx context.Context, q *Queries, key *TransferIdempotencyKey, result TransferTxResult) error {
+	if key == nil {
+		return nil
+	}
+
+	response, err := json.Marshal(result)
+
+	if err != nil {
+		return err
+	}
+
+	// a concurrent request with the same key makes this fail with a unique violation, rolling the transfer back
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:    key.Username,
+		Key:         key.Key,
+		RequestHash: key.RequestHash,
+		Response:    response,
+	})
+
+	return err
+}
+
+// transfer creates the transfer, its entries and updates the balances of the accounts within the transaction of the given queries
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams, reversesTransferID sql.NullInt64) (result TransferTxResult, err error) {
+	if arg.CreditedAmount == 0 {
+		arg.CreditedAmount = arg.Amount
+		arg.ExchangeRate = 1
+	}
+
+	if arg.ExchangeRateUpdatedAt.IsZero() {
+		arg.ExchangeRateUpdatedAt = time.Now()
+	}
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID:         arg.FromAccountID,
+		ToAccountID:           arg.ToAccountID,
+		Amount:                arg.Amount,
+		CreditedAmount:        arg.CreditedAmount,
+		ExchangeRate:          arg.ExchangeRate,
+		ExchangeRateUpdatedAt: arg.ExchangeRateUpdatedAt,
+		ReversesTransferID:    reversesTransferID,
+		Status:                util.TransferStatusPosted,
+	})
+
+	if err != nil {
+		return
+	}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: arg.FromAccountID,
+		Amount:    -arg.Amount,
+	})
+
+	if err != nil {
+		return
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: arg.ToAccountID,
+		Amount:    arg.CreditedAmount,
+	})
+
+	if err != nil {
+		return
+	}
+
+	if arg.FromAccountID < arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(
+			ctx,
+			q,
+			arg.FromAccountID,
+			-arg.Amount,
+			arg.ToAccountID,
+			arg.CreditedAmount,
+		)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(
+			ctx,
+			q,
+			arg.ToAccountID,
+			arg.CreditedAmount,
+			arg.FromAccountID,
+			-arg.Amount,
+		)
+	}
+
+	return
+}
+
+// BatchTransferError is returned by BatchTransferTx when one of the transfers fails, rolling all of them back
+type BatchTransferError struct {
+	// Index is the position of the transfer that failed in the batch
+	Index int
+	Err   error
+}
+
+func (err *BatchTransferError) Error() string {
+	return fmt.Sprintf("transfer %d of the batch failed: %v", err.Index, err.Err)
+}
+
+func (err *BatchTransferError) Unwrap() error {
+	return err.Err
+}
+
+type BatchTransferTxParams struct {
+	Transfers []TransferTxParams `json:"transfers"`
+}
+
+type BatchTransferTxResult struct {
+	// Transfers holds the result of each transfer, in the same order they were given
+	Transfers []TransferTxResult `json:"transfers"`
+}
+
+// BatchTransferTx makes all the transfers in a single transaction, so either all of them are made or none is.
+// Every account involved is locked up front in the order of their IDs, as TransferTx does for two accounts,
+// so batches sharing accounts can't deadlock. A transfer the available balance can't cover,
+// including the ones made before it in the batch, fails the batch with ErrInsufficientFunds wrapped in a BatchTransferError
+func (store *SQLStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		accountIDs := make([]int64, 0, 2*len(arg.Transfers))
+
+		for _, transferArg := range arg.Transfers {
+			accountIDs = append(accountIDs, transferArg.FromAccountID, transferArg.ToAccountID)
+		}
+
+		slices.Sort(accountIDs)
+
+		for _, accountID := range slices.Compact(accountIDs) {
+			if _, err := q.GetAccountForUpdate(ctx, accountID); err != nil {
+				return err
+			}
+		}
+
+		result.Transfers = make([]TransferTxResult, len(arg.Transfers))
+
+		for i, transferArg := range arg.Transfers {
+			transferResult, err := transfer(ctx, q, transferArg, sql.NullInt64{})
+
+			if err != nil {
+				return &BatchTransferError{Index: i, Err: err}
+			}
+
+			result.Transfers[i] = transferResult
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+var (
+	// ErrReversalOfReversal is returned by ReverseTransferTx for transfers that are reversals themselves
+	ErrReversalOfReversal = errors.New("reversals can't be reversed")
+	// ErrReversalExceedsTransfer is returned by ReverseTransferTx when more than what is left of the transfer would be reversed
+	ErrReversalExceedsTransfer = errors.New("reversal exceeds the amount left to reverse")
+	// ErrReversalTooSmall is returned by ReverseTransferTx when what the sender gets back would be rounded down to nothing
+	ErrReversalTooSmall = errors.New("reversal is too small to be converted back")
+	// ErrTransferNotPosted is returned by ReverseTransferTx for transfers that are still pending or were voided
+	ErrTransferNotPosted = errors.New("transfer isn't posted")
+)
+
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is taken back from the to account of the transfer, in its currency.
+	// Everything left to reverse is taken back when it is zero
+	Amount int64 `json:"amount"`
+}
+
+// ReverseTransferTx gives money of a transfer back to its from account with a compensating transfer linked to it,
+// never reversing more than the to account was credited in total. Between currencies,
+// the from account gets back its share of what is left of the original amount, so partial reversals
+// never add up to more than was debited and reversing everything left returns exactly the rest
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// concurrent reversals of the same transfer wait for each other, so they can't exceed it together
+		originalTransfer, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+
+		if err != nil {
+			return err
+		}
+
+		if originalTransfer.Status != util.TransferStatusPosted {
+			return ErrTransferNotPosted
+		}
+
+		if originalTransfer.ReversesTransferID.Valid {
+			return ErrReversalOfReversal
+		}
+
+		reversed, err := q.GetReversedAmount(ctx, originalTransfer.ID)
+
+		if err != nil {
+			return err
+		}
+
+		amountLeft := originalTransfer.CreditedAmount - reversed.ReversedAmount
+		amount := arg.Amount
+
+		if amount == 0 {
+			amount = amountLeft
+		}
+
+		if amount <= 0 || amount > amountLeft {
+			return ErrReversalExceedsTransfer
+		}
+
+		creditedAmount := reversalRefund(originalTransfer.Amount-reversed.RefundedAmount, amount, amountLeft)
+
+		if creditedAmount <= 0 {
+			return ErrReversalTooSmall
+		}
+
+		result, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID:         originalTransfer.ToAccountID,
+			ToAccountID:           originalTransfer.FromAccountID,
+			Amount:                amount,
+			CreditedAmount:        creditedAmount,
+			ExchangeRate:          inverseRate(originalTransfer.ExchangeRate),
+			ExchangeRateUpdatedAt: originalTransfer.ExchangeRateUpdatedAt,
+		}, sql.NullInt64{Int64: originalTransfer.ID, Valid: true})
+
+		return err
+	})
+
+	return result, err
+}
+
+// reversalRefund returns the share of the original amount left to refund matching the reversed share of the credited amount left,
+// rounded to the nearest unit. It is computed with exact arithmetic, and reversing all of the credited amount left refunds exactly the original amount left
+func reversalRefund(originalLeft int64, amount int64, creditedLeft int64) int64 {
+	// (2*originalLeft*amount + creditedLeft) / (2*creditedLeft), rounding halves up
+	numerator := new(big.Int).Mul(big.NewInt(originalLeft), big.NewInt(amount))
+	numerator.Lsh(numerator, 1).Add(numerator, big.NewInt(creditedLeft))
+
+	denominator := new(big.Int).Lsh(big.NewInt(creditedLeft), 1)
+
+	return numerator.Quo(numerator, denominator).Int64()
+}
+
+// inverseRate returns the exchange rate of the opposite direction of a transfer, or zero when there is no rate to invert
+func inverseRate(rate float64) float64 {
+	value := new(big.Rat)
+
+	if value.SetFloat64(rate) == nil || value.Sign() == 0 {
+		return 0
+	}
+
+	inverse, _ := value.Inv(value).Float64()
+
+	return inverse
+}
+
+var (
+	// ErrTransferNotPending is returned by CaptureTransferTx and VoidTransferTx for transfers that were already captured or voided
+	ErrTransferNotPending = errors.New("transfer isn't pending")
+	// ErrHoldExpired is returned by CaptureTransferTx when the hold of the transfer expired before it was captured
+	ErrHoldExpired = errors.New("hold expired")
+)
+
+type AuthorizeTransferTxParams struct {
+	TransferTxParams
+	// HoldExpiresAt is when the transfer is voided, releasing the money, if it isn't captured before
+	HoldExpiresAt time.Time `json:"hold_expires_at"`
+}
+
+// AuthorizeTransferTx creates a pending transfer that holds its amount on the from account, taking it from the available balance only.
+// The money only moves when the transfer is captured, and is given back to the available balance when it is voided,
+// failing with ErrInsufficientFunds when the available balance can't cover the hold
+func (store *SQLStore) AuthorizeTransferTx(ctx context.Context, arg AuthorizeTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		if arg.CreditedAmount == 0 {
+			arg.CreditedAmount = arg.Amount
+			arg.ExchangeRate = 1
+		}
+
+		if arg.ExchangeRateUpdatedAt.IsZero() {
+			arg.ExchangeRateUpdatedAt = time.Now()
+		}
+
 		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
+			FromAccountID:         arg.FromAccountID,
+			ToAccountID:           arg.ToAccountID,
+			Amount:                arg.Amount,
+			CreditedAmount:        arg.CreditedAmount,
+			ExchangeRate:          arg.ExchangeRate,
+			ExchangeRateUpdatedAt: arg.ExchangeRateUpdatedAt,
+			Status:                util.TransferStatusPending,
+			HoldExpiresAt:         sql.NullTime{Time: arg.HoldExpiresAt, Valid: true},
 		})
 
 		if err != nil {
 			return err
 		}
 
+		result.FromAccount, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			ID:     arg.FromAccountID,
+			Amount: -arg.Amount,
+		})
+
+		if err != nil {
+			return insufficientFundsError(err)
+		}
+
+		result.ToAccount, err = q.GetAccount(ctx, arg.ToAccountID)
+
+		if err != nil {
+			return err
+		}
+
+		return saveIdempotencyKey(ctx, q, arg.IdempotencyKey, result)
+	})
+
+	return result, err
+}
+
+type CaptureTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+}
+
+// CaptureTransferTx posts a pending transfer before its hold expires, creating its entries and
+// moving the held money from the balance of the from account to the to account
+func (store *SQLStore) CaptureTransferTx(ctx context.Context, arg CaptureTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		pendingTransfer, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+
+		if err != nil {
+			return err
+		}
+
+		if pendingTransfer.Status != util.TransferStatusPending {
+			return ErrTransferNotPending
+		}
+
+		if !time.Now().Before(pendingTransfer.HoldExpiresAt.Time) {
+			return ErrHoldExpired
+		}
+
 		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+			AccountID: pendingTransfer.FromAccountID,
+			Amount:    -pendingTransfer.Amount,
 		})
 
 		if err != nil {
@@ -85,48 +478,151 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 		}
 
 		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+			AccountID: pendingTransfer.ToAccountID,
+			Amount:    pendingTransfer.CreditedAmount,
 		})
 
 		if err != nil {
 			return err
 		}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(
-				ctx,
-				q,
-				arg.FromAccountID,
-				-arg.Amount,
-				arg.ToAccountID,
-				arg.Amount,
-			)
+		// the accounts are updated in the same order as in TransferTx, so concurrent transfers can't deadlock
+		if pendingTransfer.FromAccountID < pendingTransfer.ToAccountID {
+			result.FromAccount, err = captureHold(ctx, q, pendingTransfer)
 
 			if err != nil {
 				return err
 			}
+
+			result.ToAccount, err = creditHold(ctx, q, pendingTransfer)
 		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(
-				ctx,
-				q,
-				arg.ToAccountID,
-				arg.Amount,
-				arg.FromAccountID,
-				-arg.Amount,
-			)
+			result.ToAccount, err = creditHold(ctx, q, pendingTransfer)
 
 			if err != nil {
 				return err
 			}
+
+			result.FromAccount, err = captureHold(ctx, q, pendingTransfer)
 		}
 
-		return nil
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+			ID:     pendingTransfer.ID,
+			Status: util.TransferStatusPosted,
+		})
+
+		return err
 	})
 
 	return result, err
 }
 
+// captureHold takes the money held by a pending transfer from the balance of its from account, whose available balance already excludes it
+func captureHold(ctx context.Context, q *Queries, pendingTransfer Transfer) (Account, error) {
+	return q.CaptureAccountHold(ctx, CaptureAccountHoldParams{
+		ID:     pendingTransfer.FromAccountID,
+		Amount: pendingTransfer.Amount,
+	})
+}
+
+// creditHold gives the to account of a pending transfer what it is credited
+func creditHold(ctx context.Context, q *Queries, pendingTransfer Transfer) (Account, error) {
+	return q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     pendingTransfer.ToAccountID,
+		Amount: pendingTransfer.CreditedAmount,
+	})
+}
+
+type VoidTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+}
+
+// VoidTransferTx cancels a pending transfer, giving the money it held back to the available balance of the from account
+func (store *SQLStore) VoidTransferTx(ctx context.Context, arg VoidTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		pendingTransfer, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+
+		if err != nil {
+			return err
+		}
+
+		if pendingTransfer.Status != util.TransferStatusPending {
+			return ErrTransferNotPending
+		}
+
+		result, err = voidTransfer(ctx, q, pendingTransfer)
+
+		return err
+	})
+
+	return result, err
+}
+
+type VoidExpiredTransferTxParams struct {
+	// Now is when the expirer runs, so holds expiring up to it are voided
+	Now time.Time `json:"now"`
+}
+
+// VoidExpiredTransferTx claims the pending transfer whose hold expired the longest ago, skipping the ones claimed by others,
+// and voids it. sql.ErrNoRows is returned when no hold has expired
+func (store *SQLStore) VoidExpiredTransferTx(ctx context.Context, arg VoidExpiredTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		expiredTransfer, err := q.ClaimExpiredTransferHold(ctx, arg.Now)
+
+		if err != nil {
+			return err
+		}
+
+		result, err = voidTransfer(ctx, q, expiredTransfer)
+
+		return err
+	})
+
+	return result, err
+}
+
+// voidTransfer releases the hold of a pending transfer locked within the transaction of the given queries and marks it as voided
+func voidTransfer(ctx context.Context, q *Queries, pendingTransfer Transfer) (result TransferTxResult, err error) {
+	result.FromAccount, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+		ID:     pendingTransfer.FromAccountID,
+		Amount: pendingTransfer.Amount,
+	})
+
+	if err != nil {
+		return
+	}
+
+	result.ToAccount, err = q.GetAccount(ctx, pendingTransfer.ToAccountID)
+
+	if err != nil {
+		return
+	}
+
+	result.Transfer, err = q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+		ID:     pendingTransfer.ID,
+		Status: util.TransferStatusVoided,
+	})
+
+	return
+}
+
+// insufficientFundsError maps the debit of the from account matching no rows to ErrInsufficientFunds,
+// since AddAccountBalance only takes money out of accounts whose balance can cover it
+func insufficientFundsError(err error) error {
+	if err == sql.ErrNoRows {
+		return ErrInsufficientFunds
+	}
+
+	return err
+}
+
 func addMoney(
 	ctx context.Context,
 	q *Queries,
@@ -135,19 +631,459 @@ func addMoney(
 	toAccountId int64,
 	amountToAccount int64,
 ) (updatedFromAccount Account, updatedToAccount Account, err error) {
-	updatedFromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     fromAccountId,
-		Amount: amountFromAccount,
-	})
+	updatedFromAccount, err = moveAccountBalance(ctx, q, fromAccountId, amountFromAccount)
 
 	if err != nil {
 		return
 	}
 
-	updatedToAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     toAccountId,
-		Amount: amountToAccount,
-	})
+	updatedToAccount, err = moveAccountBalance(ctx, q, toAccountId, amountToAccount)
 
 	return
 }
+
+// moveAccountBalance adds the amount to the balance of the account. Only a debit matching no rows is mapped to ErrInsufficientFunds,
+// as a credit always matches an existing account, so one matching no rows is reported as the account not being found
+func moveAccountBalance(ctx context.Context, q *Queries, accountID int64, amount int64) (Account, error) {
+	account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     accountID,
+		Amount: amount,
+	})
+
+	if err == sql.ErrNoRows {
+		if amount < 0 {
+			return account, ErrInsufficientFunds
+		}
+
+		return account, fmt.Errorf("account %d: %w", accountID, err)
+	}
+
+	return account, err
+}
+
+type RevokeSessionTxParams struct {
+	Username       string    `json:"username"`
+	TokenID        uuid.UUID `json:"token_id"`
+	TokenExpiresAt time.Time `json:"token_expires_at"`
+	SessionID      uuid.UUID `json:"session_id"`
+}
+
+// RevokeSessionTx revokes the given access token and blocks the session it was issued for, if any
+func (store *SQLStore) RevokeSessionTx(ctx context.Context, arg RevokeSessionTxParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := q.RevokeToken(ctx, RevokeTokenParams{
+			ID:        arg.TokenID,
+			Username:  arg.Username,
+			ExpiresAt: arg.TokenExpiresAt,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if arg.SessionID == uuid.Nil {
+			return nil
+		}
+
+		session, err := q.BlockSession(ctx, arg.SessionID)
+
+		if err != nil {
+			return err
+		}
+
+		return q.RevokeToken(ctx, RevokeTokenParams{
+			ID:        session.ID,
+			Username:  session.Username,
+			ExpiresAt: session.ExpiresAt,
+		})
+	})
+}
+
+type RevokeAllSessionsTxParams struct {
+	Username       string    `json:"username"`
+	TokenID        uuid.UUID `json:"token_id"`
+	TokenExpiresAt time.Time `json:"token_expires_at"`
+}
+
+// RevokeAllSessionsTx revokes the given access token, blocks every session of its user and deletes their API keys
+func (store *SQLStore) RevokeAllSessionsTx(ctx context.Context, arg RevokeAllSessionsTxParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := q.RevokeToken(ctx, RevokeTokenParams{
+			ID:        arg.TokenID,
+			Username:  arg.Username,
+			ExpiresAt: arg.TokenExpiresAt,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return revokeUserCredentials(ctx, q, arg.Username)
+	})
+}
+
+// revokeUserCredentials blocks every session of the user and deletes their API keys,
+// so nothing issued before keeps working
+func revokeUserCredentials(ctx context.Context, q *Queries, username string) error {
+	if err := q.RevokeUserSessions(ctx, username); err != nil {
+		return err
+	}
+
+	if err := q.BlockUserSessions(ctx, username); err != nil {
+		return err
+	}
+
+	return q.DeleteUserAPIKeys(ctx, username)
+}
+
+// UpdateUserRoleTx changes the role of a user, blocks all of their sessions and deletes their API keys,
+// so that credentials issued under the previous role can't be used anymore
+func (store *SQLStore) UpdateUserRoleTx(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.UpdateUserRole(ctx, arg)
+
+		if err != nil {
+			return err
+		}
+
+		return revokeUserCredentials(ctx, q, arg.Username)
+	})
+
+	return user, err
+}
+
+type ConfirmTOTPTxParams struct {
+	Username            string   `json:"username"`
+	HashedRecoveryCodes []string `json:"hashed_recovery_codes"`
+}
+
+// ConfirmTOTPTx enables two-factor authentication for a user,
+// replacing any previous recovery codes with the given ones
+func (store *SQLStore) ConfirmTOTPTx(ctx context.Context, arg ConfirmTOTPTxParams) (TotpSecret, error) {
+	var totpSecret TotpSecret
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		totpSecret, err = q.ConfirmTOTPSecret(ctx, arg.Username)
+
+		if err != nil {
+			return err
+		}
+
+		if err = q.DeleteRecoveryCodes(ctx, arg.Username); err != nil {
+			return err
+		}
+
+		for _, hashedCode := range arg.HashedRecoveryCodes {
+			err = q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				Username:   arg.Username,
+				HashedCode: hashedCode,
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return totpSecret, err
+}
+
+type CreateUserTxParams struct {
+	CreateUserParams
+	HashedVerificationCode string    `json:"hashed_verification_code"`
+	VerificationExpiresAt  time.Time `json:"verification_expires_at"`
+	// AfterCreate is called before committing, so the user isn't created if it fails
+	AfterCreate func(user User, verifyEmail VerifyEmail) error `json:"-"`
+}
+
+type CreateUserTxResult struct {
+	User        User        `json:"user"`
+	VerifyEmail VerifyEmail `json:"verify_email"`
+}
+
+// CreateUserTx creates a user along with the code to verify their email,
+// and calls AfterCreate so that the code can be sent to them
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error) {
+	var result CreateUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.CreateUser(ctx, arg.CreateUserParams)
+
+		if err != nil {
+			return err
+		}
+
+		result.VerifyEmail, err = q.CreateVerifyEmail(ctx, CreateVerifyEmailParams{
+			Username:   result.User.Username,
+			Email:      result.User.Email,
+			HashedCode: arg.HashedVerificationCode,
+			ExpiredAt:  arg.VerificationExpiresAt,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return arg.AfterCreate(result.User, result.VerifyEmail)
+	})
+
+	return result, err
+}
+
+type VerifyEmailTxParams struct {
+	ID         int64  `json:"id"`
+	HashedCode string `json:"hashed_code"`
+}
+
+type VerifyEmailTxResult struct {
+	User        User        `json:"user"`
+	VerifyEmail VerifyEmail `json:"verify_email"`
+}
+
+// VerifyEmailTx uses an unexpired verification code and marks the email it was sent to as verified,
+// as long as it is still the email of the user
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error) {
+	var result VerifyEmailTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.VerifyEmail, err = q.UseVerifyEmail(ctx, UseVerifyEmailParams{
+			ID:         arg.ID,
+			HashedCode: arg.HashedCode,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.VerifyUserEmail(ctx, VerifyUserEmailParams{
+			Username: result.VerifyEmail.Username,
+			Email:    result.VerifyEmail.Email,
+		})
+
+		return err
+	})
+
+	return result, err
+}
+
+type UpdateUserTxParams struct {
+	UpdateUserParams
+	HashedVerificationCode string    `json:"hashed_verification_code"`
+	VerificationExpiresAt  time.Time `json:"verification_expires_at"`
+	// AfterEmailChange is called before committing, so the user isn't updated if it fails
+	AfterEmailChange func(user User, verifyEmail VerifyEmail) error `json:"-"`
+}
+
+type UpdateUserTxResult struct {
+	User User `json:"user"`
+	// VerifyEmail is only created when the email of the user changes
+	VerifyEmail VerifyEmail `json:"verify_email"`
+}
+
+// UpdateUserTx updates the profile of the user and, if their email changes,
+// creates a verification code for the new email, which stays unverified until it is used
+func (store *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error) {
+	var result UpdateUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		originalUser, err := q.GetUser(ctx, arg.Username)
+
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.UpdateUser(ctx, arg.UpdateUserParams)
+
+		if err != nil {
+			return err
+		}
+
+		if result.User.Email == originalUser.Email {
+			return nil
+		}
+
+		result.VerifyEmail, err = q.CreateVerifyEmail(ctx, CreateVerifyEmailParams{
+			Username:   result.User.Username,
+			Email:      result.User.Email,
+			HashedCode: arg.HashedVerificationCode,
+			ExpiredAt:  arg.VerificationExpiresAt,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return arg.AfterEmailChange(result.User, result.VerifyEmail)
+	})
+
+	return result, err
+}
+
+type ResetPasswordTxParams struct {
+	HashedCode        string    `json:"hashed_code"`
+	HashedPassword    string    `json:"hashed_password"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+}
+
+// ResetPasswordTx uses an unexpired password reset code to change the password of its user,
+// blocking all of their sessions and deleting their API keys
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		passwordReset, err := q.UsePasswordReset(ctx, arg.HashedCode)
+
+		if err != nil {
+			return err
+		}
+
+		user, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			Username:          passwordReset.Username,
+			HashedPassword:    arg.HashedPassword,
+			PasswordChangedAt: arg.PasswordChangedAt,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return revokeUserCredentials(ctx, q, user.Username)
+	})
+
+	return user, err
+}
+
+// ChangePasswordTx changes the password of a user, blocking all of their sessions and deleting their API keys,
+// so credentials taken before the change stop working with it
+func (store *SQLStore) ChangePasswordTx(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.UpdateUserPassword(ctx, arg)
+
+		if err != nil {
+			return err
+		}
+
+		return revokeUserCredentials(ctx, q, arg.Username)
+	})
+
+	return user, err
+}
+
+type ExecuteScheduledTransferTxParams struct {
+	// Now is when the executor runs, so transfers scheduled up to it are due
+	Now time.Time `json:"now"`
+}
+
+type ExecuteScheduledTransferTxResult struct {
+	ScheduledTransfer ScheduledTransfer          `json:"scheduled_transfer"`
+	Execution         ScheduledTransferExecution `json:"execution"`
+}
+
+// ExecuteScheduledTransferTx claims the scheduled transfer that has been due the longest, skipping the ones claimed by other executors,
+// makes its transfer and schedules its next run. A transfer that fails, such as for insufficient funds, is recorded in the execution
+// instead of being returned, and sql.ErrNoRows is returned when no scheduled transfer is due
+func (store *SQLStore) ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error) {
+	var result ExecuteScheduledTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		scheduledTransfer, err := q.ClaimDueScheduledTransfer(ctx, arg.Now)
+
+		if err != nil {
+			return err
+		}
+
+		executionArg := CreateScheduledTransferExecutionParams{
+			ScheduledTransferID: scheduledTransfer.ID,
+			ScheduledFor:        scheduledTransfer.NextRunAt.Time,
+		}
+
+		transferResult, err := executeScheduledTransfer(ctx, q, scheduledTransfer)
+
+		if err != nil {
+			executionArg.Error = sql.NullString{String: err.Error(), Valid: true}
+		} else {
+			executionArg.TransferID = sql.NullInt64{Int64: transferResult.Transfer.ID, Valid: true}
+		}
+
+		schedule := util.Schedule{
+			Frequency: scheduledTransfer.Frequency,
+			Interval:  scheduledTransfer.FrequencyInterval,
+			StartAt:   scheduledTransfer.StartAt,
+			EndAt:     scheduledTransfer.EndAt.Time,
+		}
+
+		nextRunAt, hasNextRun := schedule.NextRun(arg.Now)
+
+		scheduledTransfer.NextRunAt = sql.NullTime{Time: nextRunAt, Valid: hasNextRun}
+
+		err = q.UpdateScheduledTransferNextRun(ctx, UpdateScheduledTransferNextRunParams{
+			ID:        scheduledTransfer.ID,
+			NextRunAt: scheduledTransfer.NextRunAt,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.ScheduledTransfer = scheduledTransfer
+		result.Execution, err = q.CreateScheduledTransferExecution(ctx, executionArg)
+
+		return err
+	})
+
+	return result, err
+}
+
+// executeScheduledTransfer makes the transfer of a scheduled transfer within a savepoint,
+// so that if it fails the rest of the transaction can still record the failure
+func executeScheduledTransfer(ctx context.Context, q *Queries, scheduledTransfer ScheduledTransfer) (TransferTxResult, error) {
+	for _, accountID := range []int64{scheduledTransfer.FromAccountID, scheduledTransfer.ToAccountID} {
+		account, err := q.GetAccount(ctx, accountID)
+
+		if err != nil {
+			return TransferTxResult{}, err
+		}
+
+		if account.IsFrozen {
+			return TransferTxResult{}, fmt.Errorf("Account %d is frozen", accountID)
+		}
+	}
+
+	if _, err := q.db.ExecContext(ctx, "SAVEPOINT scheduled_transfer"); err != nil {
+		return TransferTxResult{}, err
+	}
+
+	result, err := transfer(ctx, q, TransferTxParams{
+		FromAccountID: scheduledTransfer.FromAccountID,
+		ToAccountID:   scheduledTransfer.ToAccountID,
+		Amount:        scheduledTransfer.Amount,
+	}, sql.NullInt64{})
+
+	if err != nil {
+		if _, rbErr := q.db.ExecContext(ctx, "ROLLBACK TO SAVEPOINT scheduled_transfer"); rbErr != nil {
+			return result, fmt.Errorf("Transfer error: %w, Rollback error: %v", err, rbErr)
+		}
+
+		return result, err
+	}
+
+	_, err = q.db.ExecContext(ctx, "RELEASE SAVEPOINT scheduled_transfer")
+
+	return result, err
+}
@@ -2,21 +2,29 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTransferTx(t *testing.T) {
 	store := NewSQLStore(testDB)
 
-	fromAccount := createRandomAccount(t)
-	toAccount := createRandomAccount(t)
-
 	// run n concurrent transfer transactions
 	n := 5
 	amount := int64(10)
 
+	fromAccount := createRandomAccountWithBalance(t, int64(n)*amount)
+	toAccount := createRandomAccount(t)
+
 	errs := make(chan error)
 	results := make(chan TransferTxResult)
 
@@ -50,6 +58,9 @@ func TestTransferTx(t *testing.T) {
 		require.Equal(t, fromAccount.ID, transfer.FromAccountID)
 		require.Equal(t, toAccount.ID, transfer.ToAccountID)
 		require.Equal(t, amount, transfer.Amount)
+		require.Equal(t, amount, transfer.CreditedAmount)
+		require.Equal(t, float64(1), transfer.ExchangeRate)
+		require.NotZero(t, transfer.ExchangeRateUpdatedAt)
 		require.NotZero(t, transfer.CreatedAt)
 
 		_, err = store.GetTransfer(context.Background(), transfer.ID)
@@ -114,13 +125,14 @@ func TestTransferTx(t *testing.T) {
 func TestTransferTxDeadlock(t *testing.T) {
 	store := NewSQLStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
-
 	// run n concurrent transfer transactions
 	n := 10
 	amount := int64(10)
 
+	// either account may send all of its transfers before receiving any
+	account1 := createRandomAccountWithBalance(t, int64(n/2)*amount)
+	account2 := createRandomAccountWithBalance(t, int64(n/2)*amount)
+
 	errs := make(chan error)
 
 	for i := 0; i < n; i++ {
@@ -158,3 +170,979 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxExchangeRate(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount := createRandomAccount(t)
+
+	arg := TransferTxParams{
+		FromAccountID:         fromAccount.ID,
+		ToAccountID:           toAccount.ID,
+		Amount:                100,
+		CreditedAmount:        20,
+		ExchangeRate:          0.2,
+		ExchangeRateUpdatedAt: time.Now().Add(-time.Minute),
+	}
+
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.Amount, result.Transfer.Amount)
+	require.Equal(t, arg.CreditedAmount, result.Transfer.CreditedAmount)
+	require.Equal(t, arg.ExchangeRate, result.Transfer.ExchangeRate)
+	require.WithinDuration(t, arg.ExchangeRateUpdatedAt, result.Transfer.ExchangeRateUpdatedAt, time.Second)
+
+	// each account moves the amount in its own currency
+	require.Equal(t, -arg.Amount, result.FromEntry.Amount)
+	require.Equal(t, arg.CreditedAmount, result.ToEntry.Amount)
+	require.Equal(t, fromAccount.Balance-arg.Amount, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+arg.CreditedAmount, result.ToAccount.Balance)
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 10)
+	toAccount := createRandomAccount(t)
+
+	arg := TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        11,
+	}
+
+	_, err := store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the whole transfer is rolled back
+	updatedToAccount, err := testQueries.GetAccount(context.Background(), toAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, toAccount.Balance, updatedToAccount.Balance)
+
+	updatedFromAccount, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, updatedFromAccount.Balance)
+
+	// the balance can be used up entirely
+	arg.Amount = 10
+
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, result.FromAccount.Balance)
+}
+
+func TestMoveAccountBalanceMissingAccount(t *testing.T) {
+	account := createRandomAccount(t)
+
+	err := testQueries.DeleteAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+
+	// a debit matching no rows is taken as the balance not covering it
+	_, err = moveAccountBalance(context.Background(), testQueries, account.ID, -1)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// while a credit can only match no rows when the account doesn't exist
+	_, err = moveAccountBalance(context.Background(), testQueries, account.ID, 1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.NotErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestTransferTxIdempotencyKey(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 10)
+	toAccount := createRandomAccount(t)
+
+	arg := TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+		IdempotencyKey: &TransferIdempotencyKey{
+			Username:    fromAccount.Owner,
+			Key:         util.RandomString(32),
+			RequestHash: util.HashSecretCode(util.RandomString(32)),
+		},
+	}
+
+	// run n concurrent transfer transactions with the same key
+	n := 5
+
+	errs := make(chan error)
+	results := make(chan TransferTxResult)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := store.TransferTx(context.Background(), arg)
+
+			errs <- err
+			results <- result
+		}()
+	}
+
+	var successfulResult TransferTxResult
+
+	for i := 0; i < n; i++ {
+		err := <-errs
+		result := <-results
+
+		if err == nil {
+			require.Empty(t, successfulResult, "only one transfer should succeed")
+			successfulResult = result
+			continue
+		}
+
+		pqErr, ok := err.(*pq.Error)
+		require.True(t, ok)
+		require.Equal(t, "unique_violation", string(pqErr.Code.Name()))
+		require.Equal(t, "idempotency_keys_pkey", pqErr.Constraint)
+	}
+
+	require.NotEmpty(t, successfulResult)
+
+	// the money was only moved once
+	updatedFromAccount, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance-arg.Amount, updatedFromAccount.Balance)
+
+	storedKey, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: arg.IdempotencyKey.Username,
+		Key:      arg.IdempotencyKey.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, arg.IdempotencyKey.RequestHash, storedKey.RequestHash)
+
+	var storedResult TransferTxResult
+
+	err = json.Unmarshal(storedKey.Response, &storedResult)
+	require.NoError(t, err)
+	require.Equal(t, successfulResult.Transfer.ID, storedResult.Transfer.ID)
+	require.Equal(t, successfulResult.FromAccount.Balance, storedResult.FromAccount.Balance)
+}
+
+func TestBatchTransferTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount1 := createRandomAccount(t)
+	toAccount2 := createRandomAccount(t)
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Transfers: []TransferTxParams{
+			{FromAccountID: fromAccount.ID, ToAccountID: toAccount1.ID, Amount: 30},
+			{FromAccountID: fromAccount.ID, ToAccountID: toAccount2.ID, Amount: 50},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Transfers, 2)
+
+	require.Equal(t, toAccount1.ID, result.Transfers[0].Transfer.ToAccountID)
+	require.Equal(t, int64(70), result.Transfers[0].FromAccount.Balance)
+	require.Equal(t, toAccount1.Balance+30, result.Transfers[0].ToAccount.Balance)
+
+	require.Equal(t, toAccount2.ID, result.Transfers[1].Transfer.ToAccountID)
+	require.Equal(t, int64(20), result.Transfers[1].FromAccount.Balance)
+	require.Equal(t, toAccount2.Balance+50, result.Transfers[1].ToAccount.Balance)
+}
+
+func TestBatchTransferTxInsufficientFunds(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount1 := createRandomAccount(t)
+	toAccount2 := createRandomAccount(t)
+
+	// each transfer can be covered on its own, but not both of them
+	_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Transfers: []TransferTxParams{
+			{FromAccountID: fromAccount.ID, ToAccountID: toAccount1.ID, Amount: 60},
+			{FromAccountID: fromAccount.ID, ToAccountID: toAccount2.ID, Amount: 60},
+		},
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	var batchErr *BatchTransferError
+	require.ErrorAs(t, err, &batchErr)
+	require.Equal(t, 1, batchErr.Index)
+
+	// the whole batch is rolled back
+	updatedFromAccount, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, updatedFromAccount.Balance)
+
+	updatedToAccount1, err := testQueries.GetAccount(context.Background(), toAccount1.ID)
+	require.NoError(t, err)
+	require.Equal(t, toAccount1.Balance, updatedToAccount1.Balance)
+}
+
+func TestBatchTransferTxDeadlock(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	n := 10
+	amount := int64(10)
+
+	accounts := []Account{
+		createRandomAccountWithBalance(t, int64(n)*amount),
+		createRandomAccountWithBalance(t, int64(n)*amount),
+		createRandomAccountWithBalance(t, int64(n)*amount),
+	}
+
+	errs := make(chan error)
+
+	// every batch moves money around all of the accounts, half of them in the opposite direction
+	for i := 0; i < n; i++ {
+		transfers := []TransferTxParams{
+			{FromAccountID: accounts[0].ID, ToAccountID: accounts[1].ID, Amount: amount},
+			{FromAccountID: accounts[1].ID, ToAccountID: accounts[2].ID, Amount: amount},
+			{FromAccountID: accounts[2].ID, ToAccountID: accounts[0].ID, Amount: amount},
+		}
+
+		if i%2 == 1 {
+			for j := range transfers {
+				transfers[j].FromAccountID, transfers[j].ToAccountID = transfers[j].ToAccountID, transfers[j].FromAccountID
+			}
+		}
+
+		go func() {
+			_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{Transfers: transfers})
+
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+	}
+
+	for _, account := range accounts {
+		updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, updatedAccount.Balance)
+	}
+}
+
+func TestReverseTransferTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     30,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, toAccount.ID, result.Transfer.FromAccountID)
+	require.Equal(t, fromAccount.ID, result.Transfer.ToAccountID)
+	require.Equal(t, int64(30), result.Transfer.Amount)
+	require.Equal(t, original.Transfer.ID, result.Transfer.ReversesTransferID.Int64)
+	require.Equal(t, int64(-30), result.FromEntry.Amount)
+	require.Equal(t, int64(30), result.ToEntry.Amount)
+	require.Equal(t, original.FromAccount.Balance+30, result.ToAccount.Balance)
+
+	reversed, err := store.GetReversedAmount(context.Background(), original.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), reversed.ReversedAmount)
+	require.Equal(t, int64(30), reversed.RefundedAmount)
+
+	// no more than what is left can be reversed
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     71,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	// reversals can't be reversed themselves
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: result.Transfer.ID})
+	require.ErrorIs(t, err, ErrReversalOfReversal)
+
+	// without an amount, everything left is reversed
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(70), result.Transfer.Amount)
+	require.Equal(t, fromAccount.Balance, result.ToAccount.Balance)
+	require.Equal(t, toAccount.Balance, result.FromAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+}
+
+func TestReverseTransferTxExchangeRate(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    toAccount.ID,
+		Amount:         100,
+		CreditedAmount: 20,
+		ExchangeRate:   0.2,
+	})
+	require.NoError(t, err)
+
+	// the amount is taken back in the currency of the to account, and the from account gets back its share
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), result.Transfer.Amount)
+	require.Equal(t, int64(25), result.Transfer.CreditedAmount)
+	require.Equal(t, float64(5), result.Transfer.ExchangeRate)
+
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(15), result.Transfer.Amount)
+	require.Equal(t, int64(75), result.Transfer.CreditedAmount)
+	require.Equal(t, fromAccount.Balance, result.ToAccount.Balance)
+}
+
+func TestReverseTransferTxRepeatedPartialReversals(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    toAccount.ID,
+		Amount:         3,
+		CreditedAmount: 2,
+		ExchangeRate:   2.0 / 3,
+	})
+	require.NoError(t, err)
+
+	// half of the credited amount rounds up to 2 of the 3 debited
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), result.Transfer.CreditedAmount)
+
+	// the rest only refunds what is left of the original amount, not another rounded share
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Transfer.CreditedAmount)
+	require.Equal(t, fromAccount.Balance, result.ToAccount.Balance)
+	require.Equal(t, toAccount.Balance, result.FromAccount.Balance)
+
+	reversed, err := store.GetReversedAmount(context.Background(), original.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, original.Transfer.CreditedAmount, reversed.ReversedAmount)
+	require.Equal(t, original.Transfer.Amount, reversed.RefundedAmount)
+}
+
+func TestReverseTransferTxInsufficientFunds(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount := createRandomAccountWithBalance(t, 0)
+	otherAccount := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	// the money already left the to account
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: toAccount.ID,
+		ToAccountID:   otherAccount.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestAuthorizeTransferTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount := createRandomAccount(t)
+
+	result, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        60,
+		},
+		HoldExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, util.TransferStatusPending, result.Transfer.Status)
+	require.True(t, result.Transfer.HoldExpiresAt.Valid)
+	require.Zero(t, result.FromEntry.ID)
+	require.Equal(t, int64(100), result.FromAccount.Balance)
+	require.Equal(t, int64(40), result.FromAccount.AvailableBalance)
+	require.Equal(t, toAccount, result.ToAccount)
+
+	// the held money can't be transferred nor held again
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        41,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        41,
+		},
+		HoldExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// pending transfers can't be reversed
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: result.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferNotPosted)
+}
+
+func TestCaptureTransferTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount := createRandomAccount(t)
+
+	authorized, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        60,
+		},
+		HoldExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	result, err := store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{TransferID: authorized.Transfer.ID})
+	require.NoError(t, err)
+
+	require.Equal(t, util.TransferStatusPosted, result.Transfer.Status)
+	require.False(t, result.Transfer.HoldExpiresAt.Valid)
+	require.Equal(t, int64(-60), result.FromEntry.Amount)
+	require.Equal(t, int64(60), result.ToEntry.Amount)
+	require.Equal(t, int64(40), result.FromAccount.Balance)
+	require.Equal(t, int64(40), result.FromAccount.AvailableBalance)
+	require.Equal(t, toAccount.Balance+60, result.ToAccount.Balance)
+	require.Equal(t, toAccount.AvailableBalance+60, result.ToAccount.AvailableBalance)
+
+	_, err = store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{TransferID: authorized.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferNotPending)
+
+	_, err = store.VoidTransferTx(context.Background(), VoidTransferTxParams{TransferID: authorized.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferNotPending)
+}
+
+func TestCaptureTransferTxHoldExpired(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount := createRandomAccount(t)
+
+	authorized, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        60,
+		},
+		HoldExpiresAt: time.Now().Add(-time.Second),
+	})
+	require.NoError(t, err)
+
+	_, err = store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{TransferID: authorized.Transfer.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+}
+
+func TestVoidTransferTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount := createRandomAccount(t)
+
+	authorized, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        60,
+		},
+		HoldExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	result, err := store.VoidTransferTx(context.Background(), VoidTransferTxParams{TransferID: authorized.Transfer.ID})
+	require.NoError(t, err)
+
+	require.Equal(t, util.TransferStatusVoided, result.Transfer.Status)
+	require.False(t, result.Transfer.HoldExpiresAt.Valid)
+	require.Equal(t, int64(100), result.FromAccount.Balance)
+	require.Equal(t, int64(100), result.FromAccount.AvailableBalance)
+	require.Equal(t, toAccount, result.ToAccount)
+
+	_, err = store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{TransferID: authorized.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferNotPending)
+}
+
+func TestVoidExpiredTransferTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount := createRandomAccount(t)
+
+	// far in the past, so it is claimed before the expired holds of other tests
+	holdExpiresAt := time.Now().Add(-24 * 365 * time.Hour)
+
+	authorized, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        60,
+		},
+		HoldExpiresAt: holdExpiresAt,
+	})
+	require.NoError(t, err)
+
+	// nothing expired before it
+	_, err = store.VoidExpiredTransferTx(context.Background(), VoidExpiredTransferTxParams{Now: holdExpiresAt.Add(-time.Second)})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	result, err := store.VoidExpiredTransferTx(context.Background(), VoidExpiredTransferTxParams{Now: holdExpiresAt})
+	require.NoError(t, err)
+
+	require.Equal(t, authorized.Transfer.ID, result.Transfer.ID)
+	require.Equal(t, util.TransferStatusVoided, result.Transfer.Status)
+	require.Equal(t, int64(100), result.FromAccount.AvailableBalance)
+}
+
+func TestRevokeSessionTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	session := createRandomSession(t)
+	tokenID := uuid.New()
+
+	err := store.RevokeSessionTx(context.Background(), RevokeSessionTxParams{
+		Username:       session.Username,
+		TokenID:        tokenID,
+		TokenExpiresAt: time.Now().Add(time.Minute),
+		SessionID:      session.ID,
+	})
+	require.NoError(t, err)
+
+	foundSession, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, foundSession.IsBlocked)
+
+	// tokens issued for the session are revoked even if their own ID is not
+	isRevoked, err := store.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:        uuid.New(),
+		SessionID: session.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, isRevoked)
+
+	isRevoked, err = store.IsTokenRevoked(context.Background(), IsTokenRevokedParams{ID: tokenID})
+	require.NoError(t, err)
+	require.True(t, isRevoked)
+}
+
+func TestRevokeAllSessionsTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	session := createRandomSession(t)
+
+	anotherSession, err := store.CreateSession(context.Background(), CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     session.Username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	user, err := store.GetUser(context.Background(), session.Username)
+	require.NoError(t, err)
+
+	apiKey := createRandomAPIKey(t, user)
+
+	err = store.RevokeAllSessionsTx(context.Background(), RevokeAllSessionsTxParams{
+		Username:       session.Username,
+		TokenID:        uuid.New(),
+		TokenExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	for _, sessionID := range []uuid.UUID{session.ID, anotherSession.ID} {
+		foundSession, err := store.GetSession(context.Background(), sessionID)
+		require.NoError(t, err)
+		require.True(t, foundSession.IsBlocked)
+
+		isRevoked, err := store.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+			ID:        uuid.New(),
+			SessionID: sessionID,
+		})
+		require.NoError(t, err)
+		require.True(t, isRevoked)
+	}
+
+	_, err = store.GetAPIKey(context.Background(), apiKey.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUpdateUserRoleTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	session := createRandomSession(t)
+	user, err := store.GetUser(context.Background(), session.Username)
+	require.NoError(t, err)
+
+	apiKey := createRandomAPIKey(t, user)
+
+	updatedUser, err := store.UpdateUserRoleTx(context.Background(), UpdateUserRoleParams{
+		Username: session.Username,
+		Role:     util.AdminRole,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.AdminRole, updatedUser.Role)
+
+	foundSession, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, foundSession.IsBlocked)
+
+	_, err = store.GetAPIKey(context.Background(), apiKey.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestConfirmTOTPTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	user := createRandomUser(t)
+	createRandomTOTPSecret(t, user)
+
+	oldHashedCode := util.HashRecoveryCode(util.RandomString(10))
+
+	err := store.CreateRecoveryCode(context.Background(), CreateRecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: oldHashedCode,
+	})
+	require.NoError(t, err)
+
+	newHashedCodes := []string{util.HashRecoveryCode(util.RandomString(10)), util.HashRecoveryCode(util.RandomString(10))}
+
+	totpSecret, err := store.ConfirmTOTPTx(context.Background(), ConfirmTOTPTxParams{
+		Username:            user.Username,
+		HashedRecoveryCodes: newHashedCodes,
+	})
+	require.NoError(t, err)
+	require.True(t, totpSecret.ConfirmedAt.Valid)
+
+	// previous recovery codes are replaced
+	_, err = store.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{Username: user.Username, HashedCode: oldHashedCode})
+	require.Error(t, err)
+
+	for _, hashedCode := range newHashedCodes {
+		_, err = store.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{Username: user.Username, HashedCode: hashedCode})
+		require.NoError(t, err)
+	}
+}
+
+func TestCreateUserTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	arg := CreateUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       util.RandomOwner(),
+			HashedPassword: hashedPassword,
+			Name:           util.RandomOwner(),
+			LastName:       util.RandomOwner(),
+			Email:          util.RandomEmail(),
+		},
+		HashedVerificationCode: util.HashSecretCode(util.RandomString(64)),
+		VerificationExpiresAt:  time.Now().Add(time.Hour),
+	}
+
+	var sentVerifyEmail VerifyEmail
+
+	arg.AfterCreate = func(user User, verifyEmail VerifyEmail) error {
+		sentVerifyEmail = verifyEmail
+		return nil
+	}
+
+	result, err := store.CreateUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, result.User.Username)
+	require.False(t, result.User.IsEmailVerified)
+	require.Equal(t, result.User.Email, result.VerifyEmail.Email)
+	require.Equal(t, arg.HashedVerificationCode, result.VerifyEmail.HashedCode)
+	require.Equal(t, result.VerifyEmail, sentVerifyEmail)
+
+	// the user isn't created when AfterCreate fails
+	arg.Username = util.RandomOwner()
+	arg.Email = util.RandomEmail()
+	arg.AfterCreate = func(user User, verifyEmail VerifyEmail) error {
+		return errors.New("could not send email")
+	}
+
+	_, err = store.CreateUserTx(context.Background(), arg)
+	require.EqualError(t, err, "could not send email")
+
+	_, err = store.GetUser(context.Background(), arg.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUpdateUserTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	user := createRandomUser(t)
+
+	arg := UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			LastName: sql.NullString{String: util.RandomOwner(), Valid: true},
+			Username: user.Username,
+		},
+		HashedVerificationCode: util.HashSecretCode(util.RandomString(64)),
+		VerificationExpiresAt:  time.Now().Add(time.Hour),
+		AfterEmailChange: func(user User, verifyEmail VerifyEmail) error {
+			return errors.New("email didn't change")
+		},
+	}
+
+	// no verification code is created while the email stays the same
+	result, err := store.UpdateUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.LastName.String, result.User.LastName)
+	require.Equal(t, user.Email, result.User.Email)
+	require.Empty(t, result.VerifyEmail)
+
+	// the user isn't updated when AfterEmailChange fails
+	arg.Email = sql.NullString{String: util.RandomEmail(), Valid: true}
+
+	_, err = store.UpdateUserTx(context.Background(), arg)
+	require.EqualError(t, err, "email didn't change")
+
+	foundUser, err := store.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, user.Email, foundUser.Email)
+
+	var sentVerifyEmail VerifyEmail
+
+	arg.AfterEmailChange = func(user User, verifyEmail VerifyEmail) error {
+		sentVerifyEmail = verifyEmail
+		return nil
+	}
+
+	result, err = store.UpdateUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Email.String, result.User.Email)
+	require.False(t, result.User.IsEmailVerified)
+	require.Equal(t, arg.Email.String, result.VerifyEmail.Email)
+	require.Equal(t, arg.HashedVerificationCode, result.VerifyEmail.HashedCode)
+	require.Equal(t, result.VerifyEmail, sentVerifyEmail)
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	user := createRandomUser(t)
+	verifyEmail, code := createRandomVerifyEmail(t, user, time.Now().Add(time.Hour))
+
+	result, err := store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		ID:         verifyEmail.ID,
+		HashedCode: util.HashSecretCode(code),
+	})
+	require.NoError(t, err)
+	require.True(t, result.VerifyEmail.IsUsed)
+	require.Equal(t, user.Username, result.User.Username)
+	require.True(t, result.User.IsEmailVerified)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	session := createRandomSession(t)
+	user, err := store.GetUser(context.Background(), session.Username)
+	require.NoError(t, err)
+
+	_, code := createRandomPasswordReset(t, user, time.Now().Add(time.Hour))
+	apiKey := createRandomAPIKey(t, user)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	updatedUser, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		HashedCode:        util.HashSecretCode(code),
+		HashedPassword:    hashedPassword,
+		PasswordChangedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, updatedUser.HashedPassword)
+	require.WithinDuration(t, time.Now(), updatedUser.PasswordChangedAt, time.Second)
+
+	foundSession, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, foundSession.IsBlocked)
+
+	_, err = store.GetAPIKey(context.Background(), apiKey.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// the code can't be used again
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		HashedCode:        util.HashSecretCode(code),
+		HashedPassword:    hashedPassword,
+		PasswordChangedAt: time.Now(),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestChangePasswordTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	session := createRandomSession(t)
+	user, err := store.GetUser(context.Background(), session.Username)
+	require.NoError(t, err)
+
+	apiKey := createRandomAPIKey(t, user)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	updatedUser, err := store.ChangePasswordTx(context.Background(), UpdateUserPasswordParams{
+		Username:          user.Username,
+		HashedPassword:    hashedPassword,
+		PasswordChangedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, updatedUser.HashedPassword)
+	require.WithinDuration(t, time.Now(), updatedUser.PasswordChangedAt, time.Second)
+
+	// sessions of the old password are blocked
+	foundSession, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, foundSession.IsBlocked)
+
+	// and its API keys stop working
+	_, err = store.GetAPIKey(context.Background(), apiKey.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// executeScheduledTransferTx executes due scheduled transfers until the given one is executed,
+// since others left due in the database are claimed first
+func executeScheduledTransferTx(t *testing.T, store Store, now time.Time, scheduledTransferID int64) ExecuteScheduledTransferTxResult {
+	for {
+		result, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{Now: now})
+		require.NoError(t, err)
+
+		if result.ScheduledTransfer.ID == scheduledTransferID {
+			return result
+		}
+	}
+}
+
+func TestExecuteScheduledTransferTx(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount := createRandomAccount(t)
+
+	startAt := time.Now().Add(-time.Minute)
+	scheduledTransfer := createRandomScheduledTransfer(t, fromAccount, toAccount, util.FrequencyMonthly, startAt)
+
+	result := executeScheduledTransferTx(t, store, time.Now(), scheduledTransfer.ID)
+
+	require.True(t, result.Execution.TransferID.Valid)
+	require.False(t, result.Execution.Error.Valid)
+	require.WithinDuration(t, startAt, result.Execution.ScheduledFor, time.Second)
+	require.WithinDuration(t, startAt.AddDate(0, 1, 0), result.ScheduledTransfer.NextRunAt.Time, time.Second)
+
+	transfer, err := store.GetTransfer(context.Background(), result.Execution.TransferID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, scheduledTransfer.Amount, transfer.Amount)
+
+	updatedFromAccount, err := store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance-scheduledTransfer.Amount, updatedFromAccount.Balance)
+
+	// it isn't due again until next month
+	foundScheduledTransfer, err := store.GetScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.ScheduledTransfer.NextRunAt, foundScheduledTransfer.NextRunAt)
+}
+
+func TestExecuteScheduledTransferTxFailure(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 0)
+	toAccount := createRandomAccount(t)
+
+	scheduledTransfer := createRandomScheduledTransfer(t, fromAccount, toAccount, util.FrequencyOnce, time.Now().Add(-time.Minute))
+
+	result := executeScheduledTransferTx(t, store, time.Now(), scheduledTransfer.ID)
+
+	// the failure is recorded and the schedule is over, without moving any money
+	require.False(t, result.Execution.TransferID.Valid)
+	require.Equal(t, ErrInsufficientFunds.Error(), result.Execution.Error.String)
+	require.False(t, result.ScheduledTransfer.NextRunAt.Valid)
+
+	updatedToAccount, err := store.GetAccount(context.Background(), toAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, toAccount.Balance, updatedToAccount.Balance)
+
+	executions, err := store.ListScheduledTransferExecutions(context.Background(), ListScheduledTransferExecutionsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               5,
+		Offset:              0,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []ScheduledTransferExecution{result.Execution}, executions)
+}
+
+func TestExecuteScheduledTransferTxFrozenAccount(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 100)
+	toAccount := createRandomAccount(t)
+
+	_, err := store.UpdateAccountFrozen(context.Background(), UpdateAccountFrozenParams{ID: toAccount.ID, IsFrozen: true})
+	require.NoError(t, err)
+
+	scheduledTransfer := createRandomScheduledTransfer(t, fromAccount, toAccount, util.FrequencyDaily, time.Now().Add(-time.Minute))
+
+	result := executeScheduledTransferTx(t, store, time.Now(), scheduledTransfer.ID)
+
+	require.False(t, result.Execution.TransferID.Valid)
+	require.Equal(t, fmt.Sprintf("Account %d is frozen", toAccount.ID), result.Execution.Error.String)
+	require.True(t, result.ScheduledTransfer.NextRunAt.Valid)
+}
+
+func TestExecuteScheduledTransferTxNoneDue(t *testing.T) {
+	store := NewSQLStore(testDB)
+
+	_, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+		Now: time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
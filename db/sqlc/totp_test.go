@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomTOTPSecret(t *testing.T, user User) TotpSecret {
+	secret, err := util.GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	totpSecret, err := testQueries.UpsertTOTPSecret(context.Background(), UpsertTOTPSecretParams{
+		Username: user.Username,
+		Secret:   secret,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, user.Username, totpSecret.Username)
+	require.Equal(t, secret, totpSecret.Secret)
+	require.False(t, totpSecret.ConfirmedAt.Valid)
+	require.NotZero(t, totpSecret.CreatedAt)
+
+	return totpSecret
+}
+
+func TestUpsertTOTPSecret(t *testing.T) {
+	user := createRandomUser(t)
+
+	firstSecret := createRandomTOTPSecret(t, user)
+
+	_, err := testQueries.ConfirmTOTPSecret(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	// enrolling again replaces the secret and requires a new confirmation
+	secondSecret := createRandomTOTPSecret(t, user)
+	require.NotEqual(t, firstSecret.Secret, secondSecret.Secret)
+
+	foundSecret, err := testQueries.GetTOTPSecret(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Exactly(t, secondSecret, foundSecret)
+}
+
+func TestConfirmTOTPSecret(t *testing.T) {
+	totpSecret := createRandomTOTPSecret(t, createRandomUser(t))
+
+	confirmedSecret, err := testQueries.ConfirmTOTPSecret(context.Background(), totpSecret.Username)
+	require.NoError(t, err)
+	require.True(t, confirmedSecret.ConfirmedAt.Valid)
+	require.Equal(t, totpSecret.Secret, confirmedSecret.Secret)
+}
+
+func TestUseTOTPStep(t *testing.T) {
+	user := createRandomUser(t)
+	totpSecret := createRandomTOTPSecret(t, user)
+	require.False(t, totpSecret.LastUsedStep.Valid)
+
+	step := util.TOTPStep(time.Now())
+
+	usedSecret, err := testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{Username: user.Username, Step: step})
+	require.NoError(t, err)
+	require.Equal(t, sql.NullInt64{Int64: step, Valid: true}, usedSecret.LastUsedStep)
+
+	// the same step, or an earlier one, can't be used again
+	_, err = testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{Username: user.Username, Step: step})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	_, err = testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{Username: user.Username, Step: step - 1})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	usedSecret, err = testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{Username: user.Username, Step: step + 1})
+	require.NoError(t, err)
+	require.Equal(t, step+1, usedSecret.LastUsedStep.Int64)
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	user := createRandomUser(t)
+	hashedCode := util.HashRecoveryCode(util.RandomString(10))
+
+	err := testQueries.CreateRecoveryCode(context.Background(), CreateRecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: hashedCode,
+	})
+	require.NoError(t, err)
+
+	arg := UseRecoveryCodeParams{Username: user.Username, HashedCode: hashedCode}
+
+	recoveryCode, err := testQueries.UseRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, recoveryCode.UsedAt.Valid)
+
+	// recovery codes can only be used once
+	recoveryCode, err = testQueries.UseRecoveryCode(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+	require.Empty(t, recoveryCode)
+}
+
+func TestDeleteRecoveryCodes(t *testing.T) {
+	user := createRandomUser(t)
+	hashedCode := util.HashRecoveryCode(util.RandomString(10))
+
+	err := testQueries.CreateRecoveryCode(context.Background(), CreateRecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: hashedCode,
+	})
+	require.NoError(t, err)
+
+	err = testQueries.DeleteRecoveryCodes(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	_, err = testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{Username: user.Username, HashedCode: hashedCode})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
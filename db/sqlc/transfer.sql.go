@@ -7,23 +7,68 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const claimExpiredTransferHold = `-- name: ClaimExpiredTransferHold :one
+SELECT id, from_account_id, to_account_id, amount, created_at, credited_amount, exchange_rate, exchange_rate_updated_at, reverses_transfer_id, status, hold_expires_at FROM transfers
+WHERE status = 'pending' AND hold_expires_at <= $1::timestamptz
+ORDER BY hold_expires_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) ClaimExpiredTransferHold(ctx context.Context, now time.Time) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, claimExpiredTransferHold, now)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.CreditedAmount,
+		&i.ExchangeRate,
+		&i.ExchangeRateUpdatedAt,
+		&i.ReversesTransferID,
+		&i.Status,
+		&i.HoldExpiresAt,
+	)
+	return i, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO
-  transfers (from_account_id, to_account_id, amount)
+  transfers (from_account_id, to_account_id, amount, credited_amount, exchange_rate, exchange_rate_updated_at, reverses_transfer_id, status, hold_expires_at)
 VALUES
-  ($1, $2, $3) RETURNING id, from_account_id, to_account_id, amount, created_at
+  ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, from_account_id, to_account_id, amount, created_at, credited_amount, exchange_rate, exchange_rate_updated_at, reverses_transfer_id, status, hold_expires_at
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID         int64         `json:"from_account_id"`
+	ToAccountID           int64         `json:"to_account_id"`
+	Amount                int64         `json:"amount"`
+	CreditedAmount        int64         `json:"credited_amount"`
+	ExchangeRate          float64       `json:"exchange_rate"`
+	ExchangeRateUpdatedAt time.Time     `json:"exchange_rate_updated_at"`
+	ReversesTransferID    sql.NullInt64 `json:"reverses_transfer_id"`
+	Status                string        `json:"status"`
+	HoldExpiresAt         sql.NullTime  `json:"hold_expires_at"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.CreditedAmount,
+		arg.ExchangeRate,
+		arg.ExchangeRateUpdatedAt,
+		arg.ReversesTransferID,
+		arg.Status,
+		arg.HoldExpiresAt,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -31,6 +76,12 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.CreditedAmount,
+		&i.ExchangeRate,
+		&i.ExchangeRateUpdatedAt,
+		&i.ReversesTransferID,
+		&i.Status,
+		&i.HoldExpiresAt,
 	)
 	return i, err
 }
@@ -44,8 +95,28 @@ func (q *Queries) DeleteTransfer(ctx context.Context, id int64) error {
 	return err
 }
 
+const getReversedAmount = `-- name: GetReversedAmount :one
+SELECT
+  COALESCE(SUM(amount), 0)::bigint AS reversed_amount,
+  COALESCE(SUM(credited_amount), 0)::bigint AS refunded_amount
+FROM transfers
+WHERE reverses_transfer_id = $1::bigint
+`
+
+type GetReversedAmountRow struct {
+	ReversedAmount int64 `json:"reversed_amount"`
+	RefundedAmount int64 `json:"refunded_amount"`
+}
+
+func (q *Queries) GetReversedAmount(ctx context.Context, transferID int64) (GetReversedAmountRow, error) {
+	row := q.db.QueryRowContext(ctx, getReversedAmount, transferID)
+	var i GetReversedAmountRow
+	err := row.Scan(&i.ReversedAmount, &i.RefundedAmount)
+	return i, err
+}
+
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, credited_amount, exchange_rate, exchange_rate_updated_at, reverses_transfer_id, status, hold_expires_at FROM transfers
 WHERE id = $1
 `
 
@@ -58,23 +129,85 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.CreditedAmount,
+		&i.ExchangeRate,
+		&i.ExchangeRateUpdatedAt,
+		&i.ReversesTransferID,
+		&i.Status,
+		&i.HoldExpiresAt,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, credited_amount, exchange_rate, exchange_rate_updated_at, reverses_transfer_id, status, hold_expires_at FROM transfers
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.CreditedAmount,
+		&i.ExchangeRate,
+		&i.ExchangeRateUpdatedAt,
+		&i.ReversesTransferID,
+		&i.Status,
+		&i.HoldExpiresAt,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, credited_amount, exchange_rate, exchange_rate_updated_at, reverses_transfer_id, status, hold_expires_at FROM transfers
+WHERE
+  (from_account_id = $1 OR to_account_id = $1)
+  AND ($2::varchar IS NULL
+    OR ($2 = 'out' AND from_account_id = $1)
+    OR ($2 = 'in' AND to_account_id = $1))
+  AND ($3::bigint IS NULL
+    OR (from_account_id = $1 AND to_account_id = $3)
+    OR (to_account_id = $1 AND from_account_id = $3))
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+  AND ($6::bigint IS NULL
+    OR CASE WHEN from_account_id = $1 THEN amount ELSE credited_amount END >= $6)
+  AND ($7::bigint IS NULL
+    OR CASE WHEN from_account_id = $1 THEN amount ELSE credited_amount END <= $7)
 ORDER BY id
-LIMIT $1 OFFSET $2
+LIMIT $8 OFFSET $9
 `
 
 type ListTransfersParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	AccountID             int64          `json:"account_id"`
+	Direction             sql.NullString `json:"direction"`
+	CounterpartyAccountID sql.NullInt64  `json:"counterparty_account_id"`
+	StartDate             sql.NullTime   `json:"start_date"`
+	EndDate               sql.NullTime   `json:"end_date"`
+	MinAmount             sql.NullInt64  `json:"min_amount"`
+	MaxAmount             sql.NullInt64  `json:"max_amount"`
+	Limit                 int32          `json:"limit"`
+	Offset                int32          `json:"offset"`
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
-	rows, err := q.db.QueryContext(ctx, listTransfers, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listTransfers,
+		arg.AccountID,
+		arg.Direction,
+		arg.CounterpartyAccountID,
+		arg.StartDate,
+		arg.EndDate,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +221,12 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.CreditedAmount,
+			&i.ExchangeRate,
+			&i.ExchangeRateUpdatedAt,
+			&i.ReversesTransferID,
+			&i.Status,
+			&i.HoldExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -105,7 +244,7 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 const updateTransfer = `-- name: UpdateTransfer :one
 UPDATE transfers
 SET amount = $2
-WHERE id = $1 RETURNING id, from_account_id, to_account_id, amount, created_at
+WHERE id = $1 RETURNING id, from_account_id, to_account_id, amount, created_at, credited_amount, exchange_rate, exchange_rate_updated_at, reverses_transfer_id, status, hold_expires_at
 `
 
 type UpdateTransferParams struct {
@@ -122,6 +261,42 @@ func (q *Queries) UpdateTransfer(ctx context.Context, arg UpdateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.CreditedAmount,
+		&i.ExchangeRate,
+		&i.ExchangeRateUpdatedAt,
+		&i.ReversesTransferID,
+		&i.Status,
+		&i.HoldExpiresAt,
+	)
+	return i, err
+}
+
+const updateTransferStatus = `-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = $2, hold_expires_at = NULL
+WHERE id = $1 RETURNING id, from_account_id, to_account_id, amount, created_at, credited_amount, exchange_rate, exchange_rate_updated_at, reverses_transfer_id, status, hold_expires_at
+`
+
+type UpdateTransferStatusParams struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateTransferStatus, arg.ID, arg.Status)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.CreditedAmount,
+		&i.ExchangeRate,
+		&i.ExchangeRateUpdatedAt,
+		&i.ReversesTransferID,
+		&i.Status,
+		&i.HoldExpiresAt,
 	)
 	return i, err
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/Andrew-2609/simple-bank/util"
 	"github.com/stretchr/testify/require"
@@ -16,9 +17,13 @@ func createRandomTransfer(t *testing.T) (transfer Transfer) {
 	amount := int64(10)
 
 	arg := CreateTransferParams{
-		FromAccountID: fromAccount.ID,
-		ToAccountID:   toAccount.ID,
-		Amount:        amount,
+		FromAccountID:         fromAccount.ID,
+		ToAccountID:           toAccount.ID,
+		Amount:                amount,
+		CreditedAmount:        amount * 5,
+		ExchangeRate:          5,
+		ExchangeRateUpdatedAt: time.Now().Add(-time.Minute),
+		Status:                util.TransferStatusPosted,
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -29,6 +34,11 @@ func createRandomTransfer(t *testing.T) (transfer Transfer) {
 	require.Equal(t, fromAccount.ID, transfer.FromAccountID)
 	require.Equal(t, toAccount.ID, transfer.ToAccountID)
 	require.Equal(t, amount, transfer.Amount)
+	require.Equal(t, arg.CreditedAmount, transfer.CreditedAmount)
+	require.Equal(t, arg.ExchangeRate, transfer.ExchangeRate)
+	require.WithinDuration(t, arg.ExchangeRateUpdatedAt, transfer.ExchangeRateUpdatedAt, time.Second)
+	require.Equal(t, util.TransferStatusPosted, transfer.Status)
+	require.False(t, transfer.HoldExpiresAt.Valid)
 	require.NotZero(t, transfer.CreatedAt)
 
 	return
@@ -60,20 +70,79 @@ func TestGetTransfer(t *testing.T) {
 }
 
 func TestListTransfers(t *testing.T) {
-	for i := 0; i < 10; i++ {
-		createRandomTransfer(t)
+	account := createRandomAccount(t)
+	counterparty := createRandomAccount(t)
+	otherAccount := createRandomAccount(t)
+
+	createTransfer := func(fromAccountID, toAccountID, amount, creditedAmount int64) Transfer {
+		transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID:         fromAccountID,
+			ToAccountID:           toAccountID,
+			Amount:                amount,
+			CreditedAmount:        creditedAmount,
+			ExchangeRate:          float64(creditedAmount) / float64(amount),
+			ExchangeRateUpdatedAt: time.Now(),
+			Status:                util.TransferStatusPosted,
+		})
+		require.NoError(t, err)
+
+		return transfer
 	}
 
-	arg := ListTransfersParams{Limit: 5, Offset: 5}
+	sent := createTransfer(account.ID, counterparty.ID, 100, 100)
+	received := createTransfer(counterparty.ID, account.ID, 10, 50)
+	sentToOther := createTransfer(account.ID, otherAccount.ID, 300, 300)
 
-	transfers, err := testQueries.ListTransfers(context.Background(), arg)
-	require.NoError(t, err)
+	// transfers between other accounts are left out
+	createTransfer(counterparty.ID, otherAccount.ID, 100, 100)
+
+	listTransferIDs := func(arg ListTransfersParams) []int64 {
+		arg.AccountID = account.ID
+		arg.Limit = 10
+
+		transfers, err := testQueries.ListTransfers(context.Background(), arg)
+		require.NoError(t, err)
 
-	require.Len(t, transfers, 5)
+		ids := make([]int64, len(transfers))
 
-	for _, transfer := range transfers {
-		require.NotEmpty(t, transfer)
+		for i, transfer := range transfers {
+			ids[i] = transfer.ID
+		}
+
+		return ids
 	}
+
+	require.Equal(t, []int64{sent.ID, received.ID, sentToOther.ID}, listTransferIDs(ListTransfersParams{}))
+	require.Equal(t, []int64{received.ID, sentToOther.ID}, listTransferIDs(ListTransfersParams{Offset: 1}))
+
+	require.Equal(t, []int64{sent.ID, sentToOther.ID}, listTransferIDs(ListTransfersParams{
+		Direction: sql.NullString{String: "out", Valid: true},
+	}))
+	require.Equal(t, []int64{received.ID}, listTransferIDs(ListTransfersParams{
+		Direction: sql.NullString{String: "in", Valid: true},
+	}))
+
+	require.Equal(t, []int64{sent.ID, received.ID}, listTransferIDs(ListTransfersParams{
+		CounterpartyAccountID: sql.NullInt64{Int64: counterparty.ID, Valid: true},
+	}))
+
+	// amounts are compared in the currency of the account, so the received transfer counts as 50
+	require.Equal(t, []int64{sent.ID}, listTransferIDs(ListTransfersParams{
+		MinAmount: sql.NullInt64{Int64: 60, Valid: true},
+		MaxAmount: sql.NullInt64{Int64: 200, Valid: true},
+	}))
+	require.Equal(t, []int64{received.ID}, listTransferIDs(ListTransfersParams{
+		MinAmount: sql.NullInt64{Int64: 50, Valid: true},
+		MaxAmount: sql.NullInt64{Int64: 50, Valid: true},
+	}))
+
+	require.Equal(t, []int64{sent.ID, received.ID, sentToOther.ID}, listTransferIDs(ListTransfersParams{
+		StartDate: sql.NullTime{Time: sent.CreatedAt, Valid: true},
+		EndDate:   sql.NullTime{Time: sentToOther.CreatedAt.Add(time.Second), Valid: true},
+	}))
+	require.Empty(t, listTransferIDs(ListTransfersParams{
+		EndDate: sql.NullTime{Time: sent.CreatedAt, Valid: true},
+	}))
 }
 
 func TestUpdateTransfer(t *testing.T) {
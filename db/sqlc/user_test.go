@@ -2,7 +2,9 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/Andrew-2609/simple-bank/util"
 	"github.com/stretchr/testify/require"
@@ -29,6 +31,8 @@ func createRandomUser(t *testing.T) (user User) {
 	require.Equal(t, arg.Name, user.Name)
 	require.Equal(t, arg.LastName, user.LastName)
 	require.Equal(t, arg.Email, user.Email)
+	require.Equal(t, util.DepositorRole, user.Role)
+	require.False(t, user.IsEmailVerified)
 	require.True(t, user.PasswordChangedAt.IsZero())
 	require.NotZero(t, user.CreatedAt)
 
@@ -49,3 +53,121 @@ func TestGetUser(t *testing.T) {
 
 	require.Exactly(t, user, foundUser)
 }
+
+func TestGetUserByEmail(t *testing.T) {
+	user := createRandomUser(t)
+
+	foundUser, err := testQueries.GetUserByEmail(context.Background(), user.Email)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, foundUser)
+
+	require.Exactly(t, user, foundUser)
+}
+
+func TestUpdateUser(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testQueries.VerifyUserEmail(context.Background(), VerifyUserEmailParams{
+		Username: user.Username,
+		Email:    user.Email,
+	})
+	require.NoError(t, err)
+
+	newName := util.RandomOwner()
+
+	// fields left as NULL are kept, and so is the verification of an unchanged email
+	updatedUser, err := testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Name:     sql.NullString{String: newName, Valid: true},
+		Email:    sql.NullString{String: user.Email, Valid: true},
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, newName, updatedUser.Name)
+	require.Equal(t, user.LastName, updatedUser.LastName)
+	require.Equal(t, user.Email, updatedUser.Email)
+	require.True(t, updatedUser.IsEmailVerified)
+
+	newEmail := util.RandomEmail()
+
+	updatedUser, err = testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Email:    sql.NullString{String: newEmail, Valid: true},
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, newName, updatedUser.Name)
+	require.Equal(t, newEmail, updatedUser.Email)
+	require.False(t, updatedUser.IsEmailVerified)
+}
+
+func TestUpdateUserPassword(t *testing.T) {
+	user := createRandomUser(t)
+
+	newHashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	arg := UpdateUserPasswordParams{
+		Username:          user.Username,
+		HashedPassword:    newHashedPassword,
+		PasswordChangedAt: time.Now(),
+	}
+
+	updatedUser, err := testQueries.UpdateUserPassword(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, updatedUser)
+
+	require.Equal(t, user.Username, updatedUser.Username)
+	require.Equal(t, newHashedPassword, updatedUser.HashedPassword)
+	require.WithinDuration(t, arg.PasswordChangedAt, updatedUser.PasswordChangedAt, time.Second)
+
+	passwordChangedAt, err := testQueries.GetUserPasswordChangedAt(context.Background(), user.Username)
+
+	require.NoError(t, err)
+	require.Equal(t, updatedUser.PasswordChangedAt, passwordChangedAt)
+}
+
+func TestRehashUserPassword(t *testing.T) {
+	user := createRandomUser(t)
+
+	newHashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	// nothing changes if the password was changed in the meantime
+	err = testQueries.RehashUserPassword(context.Background(), RehashUserPasswordParams{
+		HashedPassword:    newHashedPassword,
+		Username:          user.Username,
+		OldHashedPassword: util.RandomString(32),
+	})
+	require.NoError(t, err)
+
+	foundUser, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, user.HashedPassword, foundUser.HashedPassword)
+
+	err = testQueries.RehashUserPassword(context.Background(), RehashUserPasswordParams{
+		HashedPassword:    newHashedPassword,
+		Username:          user.Username,
+		OldHashedPassword: user.HashedPassword,
+	})
+	require.NoError(t, err)
+
+	foundUser, err = testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, newHashedPassword, foundUser.HashedPassword)
+	require.Equal(t, user.PasswordChangedAt, foundUser.PasswordChangedAt)
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	user := createRandomUser(t)
+
+	updatedUser, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Username: user.Username,
+		Role:     util.BankerRole,
+	})
+
+	require.NoError(t, err)
+
+	user.Role = util.BankerRole
+	require.Exactly(t, user, updatedUser)
+}
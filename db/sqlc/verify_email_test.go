@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomVerifyEmail(t *testing.T, user User, expiredAt time.Time) (verifyEmail VerifyEmail, code string) {
+	code, err := util.GenerateSecretCode()
+	require.NoError(t, err)
+
+	arg := CreateVerifyEmailParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		HashedCode: util.HashSecretCode(code),
+		ExpiredAt:  expiredAt,
+	}
+
+	verifyEmail, err = testQueries.CreateVerifyEmail(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, verifyEmail)
+
+	require.NotZero(t, verifyEmail.ID)
+	require.Equal(t, arg.Username, verifyEmail.Username)
+	require.Equal(t, arg.Email, verifyEmail.Email)
+	require.Equal(t, arg.HashedCode, verifyEmail.HashedCode)
+	require.False(t, verifyEmail.IsUsed)
+	require.WithinDuration(t, arg.ExpiredAt, verifyEmail.ExpiredAt, time.Second)
+	require.NotZero(t, verifyEmail.CreatedAt)
+
+	return
+}
+
+func TestCreateVerifyEmail(t *testing.T) {
+	createRandomVerifyEmail(t, createRandomUser(t), time.Now().Add(time.Hour))
+}
+
+func TestUseVerifyEmail(t *testing.T) {
+	verifyEmail, code := createRandomVerifyEmail(t, createRandomUser(t), time.Now().Add(time.Hour))
+
+	_, err := testQueries.UseVerifyEmail(context.Background(), UseVerifyEmailParams{
+		ID:         verifyEmail.ID,
+		HashedCode: util.HashSecretCode(util.RandomString(64)),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	usedVerifyEmail, err := testQueries.UseVerifyEmail(context.Background(), UseVerifyEmailParams{
+		ID:         verifyEmail.ID,
+		HashedCode: util.HashSecretCode(code),
+	})
+	require.NoError(t, err)
+	require.True(t, usedVerifyEmail.IsUsed)
+
+	// codes can only be used once
+	_, err = testQueries.UseVerifyEmail(context.Background(), UseVerifyEmailParams{
+		ID:         verifyEmail.ID,
+		HashedCode: util.HashSecretCode(code),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUseExpiredVerifyEmail(t *testing.T) {
+	verifyEmail, code := createRandomVerifyEmail(t, createRandomUser(t), time.Now().Add(-time.Minute))
+
+	_, err := testQueries.UseVerifyEmail(context.Background(), UseVerifyEmailParams{
+		ID:         verifyEmail.ID,
+		HashedCode: util.HashSecretCode(code),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
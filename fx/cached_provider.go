@@ -0,0 +1,59 @@
+package fx
+
+import (
+	"sync"
+	"time"
+)
+
+// CachedRateProvider decorates a RateProvider, reusing the rates it returns for a while
+// instead of asking it again for every conversion
+type CachedRateProvider struct {
+	provider RateProvider
+	duration time.Duration
+	now      func() time.Time
+
+	mutex sync.Mutex
+	rates map[string]cachedRate
+}
+
+type cachedRate struct {
+	rate      Rate
+	expiresAt time.Time
+}
+
+// NewCachedRateProvider creates a CachedRateProvider that keeps rates of the provider for the given duration
+func NewCachedRateProvider(provider RateProvider, duration time.Duration) *CachedRateProvider {
+	return &CachedRateProvider{
+		provider: provider,
+		duration: duration,
+		now:      time.Now,
+		rates:    make(map[string]cachedRate),
+	}
+}
+
+// Rate returns the cached rate between the currencies, asking the provider for it when it isn't cached or has expired.
+// Errors aren't cached
+func (provider *CachedRateProvider) Rate(from string, to string) (Rate, error) {
+	pair := ratePair(from, to)
+	now := provider.now()
+
+	provider.mutex.Lock()
+	cached, ok := provider.rates[pair]
+	provider.mutex.Unlock()
+
+	if ok && now.Before(cached.expiresAt) {
+		return cached.rate, nil
+	}
+
+	rate, err := provider.provider.Rate(from, to)
+
+	if err != nil {
+		return Rate{}, err
+	}
+
+	provider.mutex.Lock()
+	provider.rates[pair] = cachedRate{rate: rate, expiresAt: now.Add(provider.duration)}
+	provider.mutex.Unlock()
+
+	return rate, nil
+}
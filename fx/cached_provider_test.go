@@ -0,0 +1,68 @@
+package fx
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRateProvider returns a rate that changes with every call
+type countingRateProvider struct {
+	calls int
+	err   error
+}
+
+func (provider *countingRateProvider) Rate(from string, to string) (Rate, error) {
+	provider.calls++
+
+	if provider.err != nil {
+		return Rate{}, provider.err
+	}
+
+	return Rate{From: from, To: to, Value: float64(provider.calls)}, nil
+}
+
+func TestCachedRateProvider(t *testing.T) {
+	inner := &countingRateProvider{}
+	provider := NewCachedRateProvider(inner, time.Minute)
+
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	rate, err := provider.Rate(util.USD, util.BRL)
+	require.NoError(t, err)
+	require.Equal(t, float64(1), rate.Value)
+
+	rate, err = provider.Rate(util.USD, util.BRL)
+	require.NoError(t, err)
+	require.Equal(t, float64(1), rate.Value)
+	require.Equal(t, 1, inner.calls)
+
+	// pairs are cached separately
+	rate, err = provider.Rate(util.BRL, util.USD)
+	require.NoError(t, err)
+	require.Equal(t, float64(2), rate.Value)
+
+	// expired rates are asked for again
+	now = now.Add(time.Minute)
+
+	rate, err = provider.Rate(util.USD, util.BRL)
+	require.NoError(t, err)
+	require.Equal(t, float64(3), rate.Value)
+	require.Equal(t, 3, inner.calls)
+}
+
+func TestCachedRateProviderError(t *testing.T) {
+	inner := &countingRateProvider{err: errors.New("provider is down")}
+	provider := NewCachedRateProvider(inner, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		_, err := provider.Rate(util.USD, util.BRL)
+		require.EqualError(t, err, "provider is down")
+	}
+
+	require.Equal(t, 2, inner.calls)
+}
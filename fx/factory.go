@@ -0,0 +1,35 @@
+package fx
+
+import (
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/util"
+)
+
+// NewRateProvider creates the RateProvider set in the config.
+// Rates come from the FX_RATES_PATH file when it is set, or from FX_RATES otherwise,
+// and are cached for FX_RATE_CACHE_DURATION when it isn't zero
+func NewRateProvider(config util.Config) (RateProvider, error) {
+	var (
+		rates map[string]float64
+		err   error
+	)
+
+	if len(config.FXRatesPath) > 0 {
+		rates, err = LoadRatesFile(config.FXRatesPath)
+	} else {
+		rates, err = ParseRates(config.FXRates)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var provider RateProvider = NewStaticRateProvider(rates, time.Now())
+
+	if config.FXRateCacheDuration > 0 {
+		provider = NewCachedRateProvider(provider, config.FXRateCacheDuration)
+	}
+
+	return provider, nil
+}
@@ -0,0 +1,40 @@
+package fx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRateProvider(t *testing.T) {
+	provider, err := NewRateProvider(util.Config{FXRates: "USD/BRL=5"})
+	require.NoError(t, err)
+	require.IsType(t, &StaticRateProvider{}, provider)
+
+	rate, err := provider.Rate(util.USD, util.BRL)
+	require.NoError(t, err)
+	require.Equal(t, float64(5), rate.Value)
+
+	provider, err = NewRateProvider(util.Config{FXRates: "USD/BRL=5", FXRateCacheDuration: time.Minute})
+	require.NoError(t, err)
+	require.IsType(t, &CachedRateProvider{}, provider)
+
+	// the rates file replaces the rates in the config
+	path := filepath.Join(t.TempDir(), "rates.txt")
+	require.NoError(t, os.WriteFile(path, []byte("USD/BRL=4\n"), 0600))
+
+	provider, err = NewRateProvider(util.Config{FXRates: "USD/BRL=5", FXRatesPath: path})
+	require.NoError(t, err)
+
+	rate, err = provider.Rate(util.USD, util.BRL)
+	require.NoError(t, err)
+	require.Equal(t, float64(4), rate.Value)
+
+	provider, err = NewRateProvider(util.Config{FXRates: "USD/BRL"})
+	require.Error(t, err)
+	require.Nil(t, provider)
+}
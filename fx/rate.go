@@ -0,0 +1,74 @@
+package fx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var (
+	// ErrRateNotFound is returned by rate providers that can't convert between the two currencies
+	ErrRateNotFound = errors.New("exchange rate not found")
+	// ErrConversionOverflow is returned when a converted amount doesn't fit in an int64
+	ErrConversionOverflow = errors.New("converted amount is too large")
+)
+
+// Rate is how much one unit of the From currency is worth in the To currency
+type Rate struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Value     float64   `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Convert returns the amount, in the minor unit of the From currency, in the minor unit of the To currency.
+// The exponents are the number of decimal places of each currency, and the result is rounded to the nearest unit.
+// It is computed with exact arithmetic, so large amounts don't lose precision, and ErrConversionOverflow is returned
+// when the result doesn't fit in an int64
+func (rate Rate) Convert(amount int64, fromExponent int32, toExponent int32) (int64, error) {
+	value := new(big.Rat)
+
+	if value.SetFloat64(rate.Value) == nil {
+		return 0, fmt.Errorf("invalid exchange rate %v from %s to %s", rate.Value, rate.From, rate.To)
+	}
+
+	converted := value.Mul(value, new(big.Rat).SetInt64(amount))
+
+	exponentDiff := int64(toExponent - fromExponent)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(max(exponentDiff, -exponentDiff)), nil))
+
+	if exponentDiff >= 0 {
+		converted.Mul(converted, scale)
+	} else {
+		converted.Quo(converted, scale)
+	}
+
+	rounded := roundRat(converted)
+
+	if !rounded.IsInt64() {
+		return 0, ErrConversionOverflow
+	}
+
+	return rounded.Int64(), nil
+}
+
+// roundRat rounds the number to the nearest integer, with halves rounded away from zero like math.Round
+func roundRat(number *big.Rat) *big.Int {
+	// floor((2*|num| + den) / (2*den))
+	numerator := new(big.Int).Abs(number.Num())
+	numerator.Lsh(numerator, 1).Add(numerator, number.Denom())
+
+	rounded := numerator.Quo(numerator, new(big.Int).Lsh(number.Denom(), 1))
+
+	if number.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+
+	return rounded
+}
+
+// RateProvider is an interface for getting exchange rates between currencies
+type RateProvider interface {
+	Rate(from string, to string) (Rate, error)
+}
@@ -0,0 +1,99 @@
+package fx
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StaticRateProvider serves exchange rates from a fixed table, such as one set in the config.
+// Rates that aren't in the table are derived from their inverse when possible
+type StaticRateProvider struct {
+	rates     map[string]float64
+	updatedAt time.Time
+}
+
+// NewStaticRateProvider creates a StaticRateProvider with rates keyed by FROM/TO currency pairs, such as USD/BRL
+func NewStaticRateProvider(rates map[string]float64, updatedAt time.Time) *StaticRateProvider {
+	return &StaticRateProvider{
+		rates:     rates,
+		updatedAt: updatedAt,
+	}
+}
+
+// Rate returns the rate between the currencies, which is always 1 for the same currency
+func (provider *StaticRateProvider) Rate(from string, to string) (Rate, error) {
+	rate := Rate{From: from, To: to, UpdatedAt: provider.updatedAt}
+
+	if from == to {
+		rate.Value = 1
+		return rate, nil
+	}
+
+	if value, ok := provider.rates[ratePair(from, to)]; ok {
+		rate.Value = value
+		return rate, nil
+	}
+
+	if value, ok := provider.rates[ratePair(to, from)]; ok {
+		rate.Value = 1 / value
+		return rate, nil
+	}
+
+	return Rate{}, fmt.Errorf("%w from %s to %s", ErrRateNotFound, from, to)
+}
+
+func ratePair(from string, to string) string {
+	return from + "/" + to
+}
+
+// ParseRates parses a comma or newline separated table of FROM/TO=rate entries, such as USD/BRL=4.95
+func ParseRates(table string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+
+	scanner := bufio.NewScanner(strings.NewReader(strings.ReplaceAll(table, ",", "\n")))
+
+	for scanner.Scan() {
+		entry := strings.TrimSpace(scanner.Text())
+
+		if len(entry) == 0 || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		pair, value, ok := strings.Cut(entry, "=")
+
+		if !ok {
+			return nil, fmt.Errorf("invalid exchange rate entry %q", entry)
+		}
+
+		from, to, ok := strings.Cut(strings.TrimSpace(pair), "/")
+
+		if !ok || len(from) == 0 || len(to) == 0 || from == to {
+			return nil, fmt.Errorf("invalid currency pair in exchange rate entry %q", entry)
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid rate in exchange rate entry %q", entry)
+		}
+
+		rates[ratePair(from, to)] = rate
+	}
+
+	return rates, scanner.Err()
+}
+
+// LoadRatesFile reads a table of exchange rates from a file with a FROM/TO=rate entry per line
+func LoadRatesFile(path string) (map[string]float64, error) {
+	table, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read exchange rates file: %w", err)
+	}
+
+	return ParseRates(string(table))
+}
@@ -0,0 +1,118 @@
+package fx
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// convert converts the amount with the rate, failing the test if it can't
+func convert(t *testing.T, rate Rate, amount int64, fromExponent int32, toExponent int32) int64 {
+	converted, err := rate.Convert(amount, fromExponent, toExponent)
+	require.NoError(t, err)
+	return converted
+}
+
+func TestStaticRateProvider(t *testing.T) {
+	updatedAt := time.Now()
+	provider := NewStaticRateProvider(map[string]float64{"USD/BRL": 5}, updatedAt)
+
+	rate, err := provider.Rate(util.USD, util.BRL)
+	require.NoError(t, err)
+	require.Equal(t, Rate{From: util.USD, To: util.BRL, Value: 5, UpdatedAt: updatedAt}, rate)
+	require.Equal(t, int64(5000), convert(t, rate, 1000, 2, 2))
+
+	// inverse rates are derived from the table
+	rate, err = provider.Rate(util.BRL, util.USD)
+	require.NoError(t, err)
+	require.Equal(t, 0.2, rate.Value)
+	require.Equal(t, int64(200), convert(t, rate, 1000, 2, 2))
+
+	rate, err = provider.Rate(util.EUR, util.EUR)
+	require.NoError(t, err)
+	require.Equal(t, float64(1), rate.Value)
+
+	_, err = provider.Rate(util.EUR, util.BRL)
+	require.ErrorIs(t, err, ErrRateNotFound)
+	require.EqualError(t, err, "exchange rate not found from EUR to BRL")
+}
+
+func TestRateConvert(t *testing.T) {
+	rate := Rate{From: util.USD, To: util.BRL, Value: 4.956}
+
+	require.Equal(t, int64(496), convert(t, rate, 100, 2, 2))
+	require.Equal(t, int64(5), convert(t, rate, 1, 2, 2))
+	require.Equal(t, int64(0), convert(t, rate, 0, 2, 2))
+
+	// amounts are scaled between currencies with different minor units
+	rate = Rate{From: util.USD, To: util.JPY, Value: 149.5}
+
+	require.Equal(t, int64(14950), convert(t, rate, 10000, 2, 0))
+	require.Equal(t, int64(224), convert(t, rate, 150, 2, 0))
+
+	rate = Rate{From: util.JPY, To: util.BHD, Value: 0.0025}
+
+	require.Equal(t, int64(2500), convert(t, rate, 1000, 0, 3))
+}
+
+func TestRateConvertLargeAmounts(t *testing.T) {
+	// amounts past 2^53 keep every digit, which a float64 conversion would lose
+	rate := Rate{From: util.USD, To: util.EUR, Value: 1}
+
+	require.Equal(t, int64(math.MaxInt64), convert(t, rate, math.MaxInt64, 2, 2))
+	require.Equal(t, int64(9007199254740993), convert(t, rate, 9007199254740993, 2, 2))
+
+	rate = Rate{From: util.EUR, To: util.USD, Value: 2}
+
+	converted, err := rate.Convert(math.MaxInt64/2+1, 2, 2)
+	require.ErrorIs(t, err, ErrConversionOverflow)
+	require.Zero(t, converted)
+
+	// scaling up to a currency with more decimal places can overflow as well
+	rate = Rate{From: util.JPY, To: util.BHD, Value: 1}
+
+	_, err = rate.Convert(math.MaxInt64/100, 0, 3)
+	require.ErrorIs(t, err, ErrConversionOverflow)
+}
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates(" USD/BRL = 4.95, EUR/USD=1.08\n# comment\n\nEUR/BRL=5.35")
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{"USD/BRL": 4.95, "EUR/USD": 1.08, "EUR/BRL": 5.35}, rates)
+
+	rates, err = ParseRates("")
+	require.NoError(t, err)
+	require.Empty(t, rates)
+
+	_, err = ParseRates("USD/BRL")
+	require.EqualError(t, err, `invalid exchange rate entry "USD/BRL"`)
+
+	_, err = ParseRates("USD=4.95")
+	require.EqualError(t, err, `invalid currency pair in exchange rate entry "USD=4.95"`)
+
+	_, err = ParseRates("USD/USD=2")
+	require.EqualError(t, err, `invalid currency pair in exchange rate entry "USD/USD=2"`)
+
+	_, err = ParseRates("USD/BRL=-1")
+	require.EqualError(t, err, `invalid rate in exchange rate entry "USD/BRL=-1"`)
+
+	_, err = ParseRates("USD/BRL=five")
+	require.EqualError(t, err, `invalid rate in exchange rate entry "USD/BRL=five"`)
+}
+
+func TestLoadRatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.txt")
+	require.NoError(t, os.WriteFile(path, []byte("USD/BRL=4.95\nEUR/USD=1.08\n"), 0600))
+
+	rates, err := LoadRatesFile(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{"USD/BRL": 4.95, "EUR/USD": 1.08}, rates)
+
+	_, err = LoadRatesFile(filepath.Join(t.TempDir(), "missing.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Andrew-2609/simple-bank/util"
+)
+
+// Email sender types that can be selected with the EMAIL_SENDER_TYPE config.
+const (
+	LogSenderType = "log"
+)
+
+// NewSender creates the EmailSender of the type set in the config, defaulting to logging emails.
+// Logged emails go to the standard output, unless EMAIL_LOG_PATH is set.
+func NewSender(config util.Config) (EmailSender, error) {
+	switch config.EmailSenderType {
+	case LogSenderType, "":
+		if len(config.EmailLogPath) == 0 {
+			return NewLogSender(config.EmailSenderAddress, os.Stdout), nil
+		}
+
+		file, err := os.OpenFile(config.EmailLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+
+		if err != nil {
+			return nil, fmt.Errorf("could not open email log file: %w", err)
+		}
+
+		return NewLogSender(config.EmailSenderAddress, file), nil
+	default:
+		return nil, fmt.Errorf("unsupported email sender type %q", config.EmailSenderType)
+	}
+}
@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSender(t *testing.T) {
+	sender, err := NewSender(util.Config{})
+	require.NoError(t, err)
+	require.IsType(t, &LogSender{}, sender)
+
+	logPath := filepath.Join(t.TempDir(), "emails.log")
+
+	sender, err = NewSender(util.Config{EmailSenderType: LogSenderType, EmailLogPath: logPath})
+	require.NoError(t, err)
+
+	email := util.RandomEmail()
+	require.NoError(t, sender.SendEmail("Test email", util.RandomString(32), []string{email}))
+
+	logged, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	require.Contains(t, string(logged), "To: "+email)
+
+	sender, err = NewSender(util.Config{EmailSenderType: "smtp"})
+	require.EqualError(t, err, `unsupported email sender type "smtp"`)
+	require.Nil(t, sender)
+}
@@ -0,0 +1,35 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// LogSender is a stand-in for a real email provider, to be used locally.
+// Instead of sending emails, it writes them to a log.
+type LogSender struct {
+	fromAddress string
+	logger      *log.Logger
+}
+
+// NewLogSender creates a new LogSender that writes emails to the given writer
+func NewLogSender(fromAddress string, writer io.Writer) *LogSender {
+	return &LogSender{
+		fromAddress: fromAddress,
+		logger:      log.New(writer, "", log.LstdFlags),
+	}
+}
+
+// SendEmail writes the email to the log
+func (sender *LogSender) SendEmail(subject string, content string, to []string) error {
+	if len(to) == 0 {
+		return errors.New("email has no recipients")
+	}
+
+	email := fmt.Sprintf("From: %s\nTo: %s\nSubject: %s\n\n%s\n", sender.fromAddress, strings.Join(to, ", "), subject, content)
+
+	return sender.logger.Output(2, email)
+}
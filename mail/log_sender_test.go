@@ -0,0 +1,36 @@
+package mail
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogSender(t *testing.T) {
+	var output bytes.Buffer
+
+	sender := NewLogSender("no-reply@simplebank.local", &output)
+
+	to := []string{util.RandomEmail(), util.RandomEmail()}
+	content := util.RandomString(32)
+
+	err := sender.SendEmail("Test email", content, to)
+	require.NoError(t, err)
+
+	require.Contains(t, output.String(), "From: no-reply@simplebank.local\n")
+	require.Contains(t, output.String(), "To: "+to[0]+", "+to[1]+"\n")
+	require.Contains(t, output.String(), "Subject: Test email\n")
+	require.Contains(t, output.String(), content)
+}
+
+func TestLogSenderNoRecipients(t *testing.T) {
+	var output bytes.Buffer
+
+	sender := NewLogSender("no-reply@simplebank.local", &output)
+
+	err := sender.SendEmail("Test email", util.RandomString(32), nil)
+	require.EqualError(t, err, "email has no recipients")
+	require.Empty(t, output.String())
+}
@@ -0,0 +1,6 @@
+package mail
+
+// EmailSender is an interface for sending emails to the users of the bank
+type EmailSender interface {
+	SendEmail(subject string, content string, to []string) error
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 
 	"github.com/Andrew-2609/simple-bank/api"
 	db "github.com/Andrew-2609/simple-bank/db/sqlc"
 	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/Andrew-2609/simple-bank/worker"
 	_ "github.com/lib/pq"
 )
 
@@ -23,12 +25,32 @@ func main() {
 		log.Fatalf("ERROR: could not connect to the Database: %v", err)
 	}
 
-	server, err := api.NewServer(config, db.NewSQLStore(conn))
+	store := db.NewSQLStore(conn)
+
+	server, err := api.NewServer(config, store)
 
 	if err != nil {
 		log.Fatalf("Could not create server: %v", err)
 	}
 
+	if err := server.LoadCurrencies(context.Background()); err != nil {
+		log.Fatalf("Could not load currencies: %v", err)
+	}
+
+	if config.CurrencyReloadInterval > 0 {
+		go server.ReloadCurrencies(context.Background(), config.CurrencyReloadInterval)
+	}
+
+	if config.ScheduledTransferPollInterval > 0 {
+		executor := worker.NewScheduledTransferExecutor(store, config.ScheduledTransferPollInterval)
+		go executor.Start(context.Background())
+	}
+
+	if config.TransferHoldExpiryInterval > 0 {
+		expirer := worker.NewTransferHoldExpirer(store, config.TransferHoldExpiryInterval)
+		go expirer.Start(context.Background())
+	}
+
 	err = server.Start(config.ServerAddress)
 
 	if err != nil {
@@ -0,0 +1,65 @@
+package token
+
+import (
+	"fmt"
+
+	"github.com/Andrew-2609/simple-bank/util"
+)
+
+// Token types that can be selected with the TOKEN_TYPE config.
+const (
+	PasetoLocalType  = "paseto-local"
+	PasetoPublicType = "paseto-public"
+	JWTHS256Type     = "jwt-hs256"
+)
+
+// NewMaker creates the Maker of the token type set in the config, defaulting to PASETO v2.local tokens.
+// Symmetric makers use a keyring when multiple keys are configured, so keys can be rotated.
+func NewMaker(config util.Config) (Maker, error) {
+	switch config.TokenType {
+	case PasetoLocalType, "":
+		if config.TokenSymmetricKeys == "" {
+			return NewPasetoMaker(config.TokenSymmetricKey)
+		}
+
+		keyring, err := newConfigKeyring(config)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewPasetoMakerWithKeyring(keyring)
+	case JWTHS256Type:
+		if config.TokenSymmetricKeys == "" {
+			return NewJWTMaker(config.TokenSymmetricKey)
+		}
+
+		keyring, err := newConfigKeyring(config)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewJWTMakerWithKeyring(keyring)
+	case PasetoPublicType:
+		return NewPasetoPublicMaker(config.TokenAsymmetricPrivateKey)
+	default:
+		return nil, fmt.Errorf("unsupported token type %q", config.TokenType)
+	}
+}
+
+// newConfigKeyring parses the keyring of the config. TOKEN_SYMMETRIC_KEY, when still set, is kept as an unnamed key
+// that only verifies, so tokens signed before moving to a keyring, which carry no key ID, stay valid until they expire.
+func newConfigKeyring(config util.Config) (*Keyring, error) {
+	keyring, err := ParseKeyring(config.TokenActiveKeyID, config.TokenSymmetricKeys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if config.TokenSymmetricKey != "" {
+		keyring.keys[""] = []byte(config.TokenSymmetricKey)
+	}
+
+	return keyring, nil
+}
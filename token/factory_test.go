@@ -0,0 +1,129 @@
+package token
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMaker(t *testing.T) {
+	symmetricKey := util.RandomString(32)
+	keys := fmt.Sprintf("k1:%s,k2:%s", util.RandomString(32), util.RandomString(32))
+	privateKey := "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+
+	testCases := []struct {
+		name        string
+		config      util.Config
+		tokenRegexp string
+	}{
+		{
+			name:        "Default",
+			config:      util.Config{TokenSymmetricKey: symmetricKey},
+			tokenRegexp: `^v2\.local\..+$`,
+		},
+		{
+			name:        "PASETO Local",
+			config:      util.Config{TokenType: PasetoLocalType, TokenSymmetricKey: symmetricKey},
+			tokenRegexp: `^v2\.local\..+$`,
+		},
+		{
+			name:        "PASETO Local Keyring",
+			config:      util.Config{TokenType: PasetoLocalType, TokenSymmetricKeys: keys, TokenActiveKeyID: "k2"},
+			tokenRegexp: `^v2\.local\..+\..+$`,
+		},
+		{
+			name:        "JWT HS256",
+			config:      util.Config{TokenType: JWTHS256Type, TokenSymmetricKey: symmetricKey},
+			tokenRegexp: `^eyJ.+\..+\..+$`,
+		},
+		{
+			name:        "JWT HS256 Keyring",
+			config:      util.Config{TokenType: JWTHS256Type, TokenSymmetricKeys: keys, TokenActiveKeyID: "k1"},
+			tokenRegexp: `^eyJ.+\..+\..+$`,
+		},
+		{
+			name:        "PASETO Public",
+			config:      util.Config{TokenType: PasetoPublicType, TokenAsymmetricPrivateKey: privateKey},
+			tokenRegexp: `^v2\.public\..+$`,
+		},
+	}
+
+	for i := range testCases {
+		testCase := testCases[i]
+
+		t.Run(testCase.name, func(t *testing.T) {
+			maker, err := NewMaker(testCase.config)
+			require.NoError(t, err)
+
+			token, protoPayload := createPasetoToken(t, maker)
+			require.Regexp(t, testCase.tokenRegexp, token)
+
+			payload, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, protoPayload.Username, payload.Username)
+		})
+	}
+}
+
+func TestNewMakerKeyringKeepsSymmetricKey(t *testing.T) {
+	symmetricKey := util.RandomString(32)
+	keys := fmt.Sprintf("k1:%s", util.RandomString(32))
+
+	makers := []struct {
+		name      string
+		tokenType string
+		newMaker  func(symmetricKey string) (Maker, error)
+	}{
+		{name: "PASETO Local", tokenType: PasetoLocalType, newMaker: NewPasetoMaker},
+		{name: "JWT HS256", tokenType: JWTHS256Type, newMaker: NewJWTMaker},
+	}
+
+	for i := range makers {
+		testCase := makers[i]
+
+		t.Run(testCase.name, func(t *testing.T) {
+			singleKeyMaker, err := testCase.newMaker(symmetricKey)
+			require.NoError(t, err)
+
+			token, protoPayload := createPasetoToken(t, singleKeyMaker)
+
+			// tokens signed before moving to a keyring have no key ID, and are verified with the previous key
+			keyringMaker, err := NewMaker(util.Config{
+				TokenType:          testCase.tokenType,
+				TokenSymmetricKey:  symmetricKey,
+				TokenSymmetricKeys: keys,
+				TokenActiveKeyID:   "k1",
+			})
+			require.NoError(t, err)
+
+			payload, err := keyringMaker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, protoPayload.Username, payload.Username)
+
+			// new tokens are signed with the active key, which the previous maker doesn't know
+			newToken, _ := createPasetoToken(t, keyringMaker)
+
+			_, err = singleKeyMaker.VerifyToken(newToken)
+			require.Error(t, err)
+
+			// once the previous key is unset, its tokens are rejected
+			keyringOnlyMaker, err := NewMaker(util.Config{
+				TokenType:          testCase.tokenType,
+				TokenSymmetricKeys: keys,
+				TokenActiveKeyID:   "k1",
+			})
+			require.NoError(t, err)
+
+			_, err = keyringOnlyMaker.VerifyToken(token)
+			require.ErrorIs(t, err, ErrUnknownKey)
+		})
+	}
+}
+
+func TestNewMakerUnsupportedType(t *testing.T) {
+	maker, err := NewMaker(util.Config{TokenType: "jwt-none", TokenSymmetricKey: util.RandomString(32)})
+	require.EqualError(t, err, `unsupported token type "jwt-none"`)
+	require.Nil(t, maker)
+}
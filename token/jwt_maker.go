@@ -3,7 +3,6 @@ package token
 import (
 	"errors"
 	"fmt"
-	"time"
 
 	"github.com/dgrijalva/jwt-go"
 )
@@ -12,28 +11,40 @@ const minSecretKeySize = 32
 
 // JWTMaker is a JavaScript Web Token Maker.
 type JWTMaker struct {
-	secretKey string
+	keyring *Keyring
 }
 
 // NewJWTMaker creates a new JWTMaker.
 func NewJWTMaker(secretKey string) (Maker, error) {
-	if len(secretKey) < minSecretKeySize {
-		return nil, fmt.Errorf("Invalid secret key size: must have at least %d characters.", minSecretKeySize)
-	}
-
-	return &JWTMaker{secretKey}, nil
+	return NewJWTMakerWithKeyring(newSingleKeyring(secretKey))
 }
 
-func (maker *JWTMaker) CreateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+// NewJWTMakerWithKeyring creates a new JWTMaker that stamps tokens with the ID of the signing key in the "kid" header.
+func NewJWTMakerWithKeyring(keyring *Keyring) (Maker, error) {
+	err := keyring.validate(func(secret []byte) error {
+		if len(secret) < minSecretKeySize {
+			return fmt.Errorf("Invalid secret key size: must have at least %d characters.", minSecretKeySize)
+		}
+		return nil
+	})
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	return &JWTMaker{keyring}, nil
+}
+
+func (maker *JWTMaker) CreateToken(payload *Payload) (string, error) {
+	keyID, secretKey := maker.keyring.ActiveKey()
+
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
 
-	return jwtToken.SignedString([]byte(maker.secretKey))
+	if keyID != "" {
+		jwtToken.Header["kid"] = keyID
+	}
+
+	return jwtToken.SignedString(secretKey)
 }
 
 func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
@@ -41,7 +52,10 @@ func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, ErrInvalidToken
 		}
-		return []byte(maker.secretKey), nil
+
+		keyID, _ := token.Header["kid"].(string)
+
+		return maker.keyring.Key(keyID)
 	})
 
 	if err != nil {
@@ -49,6 +63,9 @@ func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
 		if ok && errors.Is(validationErr.Inner, ErrExpiredToken) {
 			return nil, ErrExpiredToken
 		}
+		if ok && errors.Is(validationErr.Inner, ErrUnknownKey) {
+			return nil, ErrUnknownKey
+		}
 		return nil, ErrInvalidToken
 	}
 
@@ -18,11 +18,15 @@ func createJWTToken(t *testing.T, maker Maker) (jwtToken string, protoPayload *P
 
 	protoPayload = &Payload{
 		Username:  username,
+		Role:      util.DepositorRole,
 		IssuedAt:  issuedAt,
 		ExpiredAt: expiredAt,
 	}
 
-	jwtToken, err := maker.CreateToken(username, duration)
+	payload, err := NewPayload(username, util.DepositorRole, duration)
+	require.NoError(t, err)
+
+	jwtToken, err = maker.CreateToken(payload)
 	require.NoError(t, err)
 	require.NotEmpty(t, jwtToken)
 
@@ -48,6 +52,7 @@ func TestJWTMakerVerifyToken(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, protoPayload.Username, payload.Username)
+	require.Equal(t, protoPayload.Role, payload.Role)
 	require.WithinDuration(t, protoPayload.IssuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, protoPayload.ExpiredAt, payload.ExpiredAt, time.Second)
 }
@@ -56,7 +61,10 @@ func TestExpiredJWTToken(t *testing.T) {
 	maker, err := NewJWTMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	jwtToken, err := maker.CreateToken(util.RandomOwner(), -time.Second)
+	expiredPayload, err := NewPayload(util.RandomOwner(), util.DepositorRole, -time.Second)
+	require.NoError(t, err)
+
+	jwtToken, err := maker.CreateToken(expiredPayload)
 	require.NoError(t, err)
 
 	payload, err := maker.VerifyToken(jwtToken)
@@ -66,7 +74,7 @@ func TestExpiredJWTToken(t *testing.T) {
 }
 
 func TestInvalidJWTTokenAlgNone(t *testing.T) {
-	payload, err := NewPayload(util.RandomOwner(), time.Minute)
+	payload, err := NewPayload(util.RandomOwner(), util.DepositorRole, time.Minute)
 	require.NoError(t, err)
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
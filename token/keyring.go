@@ -0,0 +1,94 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// This error occurs when a token was signed with a key that isn't in the keyring, usually because it was retired.
+var ErrUnknownKey = errors.New("token was signed with an unknown key")
+
+// Keyring holds every key a Maker accepts, indexed by key ID.
+// Only the active key signs new tokens; the other ones are kept so tokens they signed remain valid
+// until they expire, and a key is retired by removing it from the keyring.
+type Keyring struct {
+	activeKeyID string
+	keys        map[string][]byte
+}
+
+// NewKeyring creates a new Keyring from a map of key IDs to secrets, signing with the key of activeKeyID.
+func NewKeyring(activeKeyID string, keys map[string]string) (*Keyring, error) {
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active key %q is not in the keyring", activeKeyID)
+	}
+
+	keyring := &Keyring{
+		activeKeyID: activeKeyID,
+		keys:        make(map[string][]byte, len(keys)),
+	}
+
+	for id, secret := range keys {
+		keyring.keys[id] = []byte(secret)
+	}
+
+	return keyring, nil
+}
+
+// ParseKeyring creates a new Keyring from a comma separated list of "id:secret" pairs.
+func ParseKeyring(activeKeyID string, spec string) (*Keyring, error) {
+	keys := make(map[string]string)
+
+	for _, entry := range strings.Split(spec, ",") {
+		id, secret, found := strings.Cut(strings.TrimSpace(entry), ":")
+
+		if !found || id == "" {
+			return nil, fmt.Errorf("invalid keyring entry %q: must be formatted as id:secret", entry)
+		}
+
+		if _, exists := keys[id]; exists {
+			return nil, fmt.Errorf("duplicated key %q in the keyring", id)
+		}
+
+		keys[id] = secret
+	}
+
+	return NewKeyring(activeKeyID, keys)
+}
+
+// newSingleKeyring creates a Keyring with an unnamed key, for Makers created from a single secret.
+// Tokens signed by it aren't stamped with a key ID, just like before keyrings existed.
+func newSingleKeyring(secret string) *Keyring {
+	return &Keyring{keys: map[string][]byte{"": []byte(secret)}}
+}
+
+// ActiveKey returns the ID and secret of the key used to sign new tokens.
+func (keyring *Keyring) ActiveKey() (string, []byte) {
+	return keyring.activeKeyID, keyring.keys[keyring.activeKeyID]
+}
+
+// Key returns the secret of the given key ID, or ErrUnknownKey if it isn't in the keyring.
+func (keyring *Keyring) Key(id string) ([]byte, error) {
+	secret, ok := keyring.keys[id]
+
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return secret, nil
+}
+
+// validate returns an error if any key in the keyring doesn't satisfy the given size check.
+func (keyring *Keyring) validate(check func(secret []byte) error) error {
+	for id, secret := range keyring.keys {
+		if err := check(secret); err != nil {
+			if id == "" {
+				return err
+			}
+
+			return fmt.Errorf("key %q: %w", id, err)
+		}
+	}
+
+	return nil
+}
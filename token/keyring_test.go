@@ -0,0 +1,119 @@
+package token
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeyring(t *testing.T) {
+	oldSecret := util.RandomString(32)
+	newSecret := util.RandomString(32)
+
+	keyring, err := ParseKeyring("2024-02", fmt.Sprintf("2024-01:%s, 2024-02:%s", oldSecret, newSecret))
+	require.NoError(t, err)
+
+	activeKeyID, activeSecret := keyring.ActiveKey()
+	require.Equal(t, "2024-02", activeKeyID)
+	require.Equal(t, []byte(newSecret), activeSecret)
+
+	secret, err := keyring.Key("2024-01")
+	require.NoError(t, err)
+	require.Equal(t, []byte(oldSecret), secret)
+
+	secret, err = keyring.Key("2023-12")
+	require.EqualError(t, err, ErrUnknownKey.Error())
+	require.Nil(t, secret)
+}
+
+func TestParseInvalidKeyring(t *testing.T) {
+	secret := util.RandomString(32)
+
+	keyring, err := ParseKeyring("k1", secret)
+	require.EqualError(t, err, fmt.Sprintf("invalid keyring entry %q: must be formatted as id:secret", secret))
+	require.Nil(t, keyring)
+
+	keyring, err = ParseKeyring("k1", fmt.Sprintf("k1:%s,k1:%s", secret, secret))
+	require.EqualError(t, err, `duplicated key "k1" in the keyring`)
+	require.Nil(t, keyring)
+
+	keyring, err = ParseKeyring("k2", fmt.Sprintf("k1:%s", secret))
+	require.EqualError(t, err, `active key "k2" is not in the keyring`)
+	require.Nil(t, keyring)
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldSecret := util.RandomString(32)
+	newSecret := util.RandomString(32)
+
+	oldKeyring, err := NewKeyring("k1", map[string]string{"k1": oldSecret})
+	require.NoError(t, err)
+
+	rotatedKeyring, err := NewKeyring("k2", map[string]string{"k1": oldSecret, "k2": newSecret})
+	require.NoError(t, err)
+
+	retiredKeyring, err := NewKeyring("k2", map[string]string{"k2": newSecret})
+	require.NoError(t, err)
+
+	makers := []struct {
+		name      string
+		newMaker  func(keyring *Keyring) (Maker, error)
+		createFor func(t *testing.T, maker Maker) (string, *Payload)
+	}{
+		{name: "JWT", newMaker: NewJWTMakerWithKeyring, createFor: createJWTToken},
+		{name: "PASETO", newMaker: NewPasetoMakerWithKeyring, createFor: createPasetoToken},
+	}
+
+	for i := range makers {
+		testCase := makers[i]
+
+		t.Run(testCase.name, func(t *testing.T) {
+			oldMaker, err := testCase.newMaker(oldKeyring)
+			require.NoError(t, err)
+
+			rotatedMaker, err := testCase.newMaker(rotatedKeyring)
+			require.NoError(t, err)
+
+			retiredMaker, err := testCase.newMaker(retiredKeyring)
+			require.NoError(t, err)
+
+			oldToken, protoPayload := testCase.createFor(t, oldMaker)
+
+			// tokens signed before the rotation are still accepted
+			payload, err := rotatedMaker.VerifyToken(oldToken)
+			require.NoError(t, err)
+			require.Equal(t, protoPayload.Username, payload.Username)
+
+			// new tokens are signed with the new key, which the old maker doesn't know yet
+			newToken, _ := testCase.createFor(t, rotatedMaker)
+
+			payload, err = oldMaker.VerifyToken(newToken)
+			require.EqualError(t, err, ErrUnknownKey.Error())
+			require.Nil(t, payload)
+
+			payload, err = retiredMaker.VerifyToken(newToken)
+			require.NoError(t, err)
+			require.NotNil(t, payload)
+
+			// once the old key is retired, its tokens are rejected
+			payload, err = retiredMaker.VerifyToken(oldToken)
+			require.EqualError(t, err, ErrUnknownKey.Error())
+			require.Nil(t, payload)
+		})
+	}
+}
+
+func TestKeyringInvalidKeySize(t *testing.T) {
+	keyring, err := NewKeyring("k1", map[string]string{"k1": util.RandomString(32), "k0": util.RandomString(31)})
+	require.NoError(t, err)
+
+	maker, err := NewJWTMakerWithKeyring(keyring)
+	require.EqualError(t, err, `key "k0": Invalid secret key size: must have at least 32 characters.`)
+	require.Nil(t, maker)
+
+	maker, err = NewPasetoMakerWithKeyring(keyring)
+	require.EqualError(t, err, `key "k0": invalid key size: must have exactly 32 characters.`)
+	require.Nil(t, maker)
+}
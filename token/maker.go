@@ -1,11 +1,17 @@
 package token
 
-import "time"
+import "crypto/ed25519"
 
 // Maker is an interface for managing tokens.
 type Maker interface {
-	// CreateToken creates a new token for a specific username and duration.
-	CreateToken(username string, duration time.Duration) (string, error)
+	// CreateToken creates a new signed token carrying the given payload.
+	CreateToken(payload *Payload) (string, error)
 	// Veirfy token checks if the token is valid or not.
 	VerifyToken(token string) (*Payload, error)
 }
+
+// PublicKeyProvider is implemented by the Makers whose tokens can be verified with a public key.
+type PublicKeyProvider interface {
+	// PublicKey returns the key used to verify tokens.
+	PublicKey() ed25519.PublicKey
+}
@@ -2,7 +2,6 @@ package token
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/o1egl/paseto"
 	"golang.org/x/crypto/chacha20poly1305"
@@ -10,37 +9,66 @@ import (
 
 // PasetoMaker is a PASETO token Maker.
 type PasetoMaker struct {
-	paseto       *paseto.V2
-	symmetricKey []byte
+	paseto  *paseto.V2
+	keyring *Keyring
+}
+
+// pasetoFooter is the unencrypted (but authenticated) footer of the tokens, carrying the ID of the signing key.
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
 }
 
 func NewPasetoMaker(symmetricKey string) (Maker, error) {
-	if len(symmetricKey) != chacha20poly1305.KeySize {
-		return nil, fmt.Errorf("invalid key size: must have exactly %d characters.", chacha20poly1305.KeySize)
+	return NewPasetoMakerWithKeyring(newSingleKeyring(symmetricKey))
+}
+
+// NewPasetoMakerWithKeyring creates a new PasetoMaker that stamps tokens with the ID of the signing key in the footer.
+func NewPasetoMakerWithKeyring(keyring *Keyring) (Maker, error) {
+	err := keyring.validate(func(secret []byte) error {
+		if len(secret) != chacha20poly1305.KeySize {
+			return fmt.Errorf("invalid key size: must have exactly %d characters.", chacha20poly1305.KeySize)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
 	maker := &PasetoMaker{
-		paseto:       paseto.NewV2(),
-		symmetricKey: []byte(symmetricKey),
+		paseto:  paseto.NewV2(),
+		keyring: keyring,
 	}
 
 	return maker, nil
 }
 
-func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+func (maker *PasetoMaker) CreateToken(payload *Payload) (string, error) {
+	keyID, symmetricKey := maker.keyring.ActiveKey()
 
-	if err != nil {
-		return "", err
+	if keyID == "" {
+		return maker.paseto.Encrypt(symmetricKey, payload, nil)
 	}
 
-	return maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+	return maker.paseto.Encrypt(symmetricKey, payload, pasetoFooter{KeyID: keyID})
 }
 
 func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
+	footer := pasetoFooter{}
+
+	if err := paseto.ParseFooter(token, &footer); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	symmetricKey, err := maker.keyring.Key(footer.KeyID)
+
+	if err != nil {
+		return nil, err
+	}
+
 	payload := &Payload{}
 
-	if err := maker.paseto.Decrypt(token, maker.symmetricKey, payload, nil); err != nil {
+	if err := maker.paseto.Decrypt(token, symmetricKey, payload, nil); err != nil {
 		return nil, ErrInvalidToken
 	}
 
@@ -17,11 +17,15 @@ func createPasetoToken(t *testing.T, maker Maker) (pasetoToken string, protoPayl
 
 	protoPayload = &Payload{
 		Username:  username,
+		Role:      util.DepositorRole,
 		IssuedAt:  issuedAt,
 		ExpiredAt: expiredAt,
 	}
 
-	pasetoToken, err := maker.CreateToken(username, duration)
+	payload, err := NewPayload(username, util.DepositorRole, duration)
+	require.NoError(t, err)
+
+	pasetoToken, err = maker.CreateToken(payload)
 	require.NoError(t, err)
 	require.NotEmpty(t, pasetoToken)
 
@@ -40,6 +44,7 @@ func TestPasetoMaker(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, protoPayload.Username, payload.Username)
+	require.Equal(t, protoPayload.Role, payload.Role)
 	require.WithinDuration(t, protoPayload.IssuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, protoPayload.ExpiredAt, payload.ExpiredAt, time.Second)
 }
@@ -48,7 +53,10 @@ func TestExpiredPasetoToken(t *testing.T) {
 	maker, err := NewPasetoMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	pasetoToken, err := maker.CreateToken(util.RandomOwner(), -time.Second)
+	expiredPayload, err := NewPayload(util.RandomOwner(), util.DepositorRole, -time.Second)
+	require.NoError(t, err)
+
+	pasetoToken, err := maker.CreateToken(expiredPayload)
 	require.NoError(t, err)
 
 	payload, err := maker.VerifyToken(pasetoToken)
@@ -71,7 +79,10 @@ func TestInvalidPasetoToken(t *testing.T) {
 	anotherMaker, err := NewPasetoMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	token, err := anotherMaker.CreateToken(util.RandomOwner(), time.Minute)
+	anotherPayload, err := NewPayload(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	token, err := anotherMaker.CreateToken(anotherPayload)
 	require.NoError(t, err)
 
 	payload, err := maker.VerifyToken(token)
@@ -0,0 +1,98 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/o1egl/paseto"
+)
+
+// This error occurs when a verify-only maker is asked to create a token.
+var ErrSigningNotSupported = errors.New("token maker has no private key to sign tokens")
+
+// PasetoPublicMaker is a PASETO v2.public token Maker.
+// Tokens are signed with an Ed25519 private key and can be verified by anyone holding the public key.
+type PasetoPublicMaker struct {
+	paseto     *paseto.V2
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// NewPasetoPublicMaker creates a new PasetoPublicMaker from a hex encoded Ed25519 private key,
+// either as a 32 bytes seed or as the full 64 bytes key.
+func NewPasetoPublicMaker(privateKeyHex string) (Maker, error) {
+	key, err := hex.DecodeString(privateKeyHex)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: must be hex encoded: %w", err)
+	}
+
+	var privateKey ed25519.PrivateKey
+
+	switch len(key) {
+	case ed25519.SeedSize:
+		privateKey = ed25519.NewKeyFromSeed(key)
+	case ed25519.PrivateKeySize:
+		privateKey = ed25519.PrivateKey(key)
+	default:
+		return nil, fmt.Errorf("invalid private key size: must have exactly %d or %d bytes.", ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+
+	maker := &PasetoPublicMaker{
+		paseto:     paseto.NewV2(),
+		privateKey: privateKey,
+		publicKey:  privateKey.Public().(ed25519.PublicKey),
+	}
+
+	return maker, nil
+}
+
+// NewPasetoPublicVerifier creates a PasetoPublicMaker from a hex encoded Ed25519 public key.
+// It can only verify tokens, so services that don't issue tokens never need to hold a secret.
+func NewPasetoPublicVerifier(publicKeyHex string) (Maker, error) {
+	key, err := hex.DecodeString(publicKeyHex)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: must be hex encoded: %w", err)
+	}
+
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size: must have exactly %d bytes.", ed25519.PublicKeySize)
+	}
+
+	maker := &PasetoPublicMaker{
+		paseto:    paseto.NewV2(),
+		publicKey: ed25519.PublicKey(key),
+	}
+
+	return maker, nil
+}
+
+func (maker *PasetoPublicMaker) CreateToken(payload *Payload) (string, error) {
+	if maker.privateKey == nil {
+		return "", ErrSigningNotSupported
+	}
+
+	return maker.paseto.Sign(maker.privateKey, payload, nil)
+}
+
+func (maker *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	payload := &Payload{}
+
+	if err := maker.paseto.Verify(token, maker.publicKey, payload, nil); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := payload.Valid(); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// PublicKey returns the key used to verify the tokens of this maker.
+func (maker *PasetoPublicMaker) PublicKey() ed25519.PublicKey {
+	return maker.publicKey
+}
@@ -0,0 +1,124 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Andrew-2609/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomPrivateKeyHex(t *testing.T) string {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return hex.EncodeToString(privateKey.Seed())
+}
+
+func TestPasetoPublicMaker(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(randomPrivateKeyHex(t))
+	require.NoError(t, err)
+
+	pasetoToken, protoPayload := createPasetoToken(t, maker)
+	require.Regexp(t, `^v2\.public\..+$`, pasetoToken)
+
+	payload, err := maker.VerifyToken(pasetoToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, protoPayload.Username, payload.Username)
+	require.Equal(t, protoPayload.Role, payload.Role)
+	require.WithinDuration(t, protoPayload.IssuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, protoPayload.ExpiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestPasetoPublicVerifier(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(randomPrivateKeyHex(t))
+	require.NoError(t, err)
+
+	publicKey := maker.(PublicKeyProvider).PublicKey()
+
+	verifier, err := NewPasetoPublicVerifier(hex.EncodeToString(publicKey))
+	require.NoError(t, err)
+
+	pasetoToken, protoPayload := createPasetoToken(t, maker)
+
+	payload, err := verifier.VerifyToken(pasetoToken)
+	require.NoError(t, err)
+	require.Equal(t, protoPayload.Username, payload.Username)
+
+	anotherPayload, err := NewPayload(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	token, err := verifier.CreateToken(anotherPayload)
+	require.EqualError(t, err, ErrSigningNotSupported.Error())
+	require.Empty(t, token)
+}
+
+func TestPasetoPublicMakerFullPrivateKey(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	maker, err := NewPasetoPublicMaker(hex.EncodeToString(privateKey))
+	require.NoError(t, err)
+	require.Equal(t, privateKey.Public(), maker.(PublicKeyProvider).PublicKey())
+}
+
+func TestExpiredPasetoPublicToken(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(randomPrivateKeyHex(t))
+	require.NoError(t, err)
+
+	expiredPayload, err := NewPayload(util.RandomOwner(), util.DepositorRole, -time.Second)
+	require.NoError(t, err)
+
+	pasetoToken, err := maker.CreateToken(expiredPayload)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(pasetoToken)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestInvalidPasetoPublicKeySize(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(hex.EncodeToString([]byte(util.RandomString(31))))
+	require.EqualError(t, err, fmt.Errorf("invalid private key size: must have exactly 32 or 64 bytes.").Error())
+	require.Nil(t, maker)
+
+	maker, err = NewPasetoPublicVerifier(hex.EncodeToString([]byte(util.RandomString(31))))
+	require.EqualError(t, err, fmt.Errorf("invalid public key size: must have exactly 32 bytes.").Error())
+	require.Nil(t, maker)
+}
+
+func TestInvalidPasetoPublicToken(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(randomPrivateKeyHex(t))
+	require.NoError(t, err)
+
+	anotherMaker, err := NewPasetoPublicMaker(randomPrivateKeyHex(t))
+	require.NoError(t, err)
+
+	anotherPayload, err := NewPayload(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	token, err := anotherMaker.CreateToken(anotherPayload)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+
+	// symmetric tokens aren't accepted either
+	symmetricMaker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, err = symmetricMaker.CreateToken(anotherPayload)
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
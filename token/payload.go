@@ -14,16 +14,28 @@ var (
 	ErrInvalidToken = errors.New("invalid token")
 )
 
+// PurposeMFAChallenge marks the tokens issued after a correct password when two-factor authentication is enabled.
+// They can only be exchanged for an access token together with a second factor.
+const PurposeMFAChallenge = "mfa_challenge"
+
+// PurposeRefresh marks the refresh tokens of login sessions.
+// They can only be exchanged for a new access token.
+const PurposeRefresh = "refresh"
+
 // Payload contains the payload data of the token
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
+	SessionID uuid.UUID `json:"session_id"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	Scopes    []string  `json:"scopes"`
+	Purpose   string    `json:"purpose,omitempty"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-// NewPayload creates a new token payload with a specific username and duration.
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+// NewPayload creates a new token payload with a specific username, role and duration.
+func NewPayload(username string, role string, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewUUID()
 
 	if err != nil {
@@ -33,6 +45,7 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        tokenID,
 		Username:  username,
+		Role:      role,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
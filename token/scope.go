@@ -0,0 +1,31 @@
+package token
+
+import "slices"
+
+// Scopes that can be granted to a token, each one allowing a group of routes.
+const (
+	ScopeAccountsRead    = "accounts:read"
+	ScopeAccountsWrite   = "accounts:write"
+	ScopeTransfersCreate = "transfers:create"
+	ScopeUsersRead       = "users:read"
+	ScopeUsersWrite      = "users:write"
+)
+
+// AllScopes are granted when no scopes are requested, giving the token full access.
+var AllScopes = []string{
+	ScopeAccountsRead,
+	ScopeAccountsWrite,
+	ScopeTransfersCreate,
+	ScopeUsersRead,
+	ScopeUsersWrite,
+}
+
+// IsSupportedScope returns true if the scope is supported.
+func IsSupportedScope(scope string) bool {
+	return slices.Contains(AllScopes, scope)
+}
+
+// HasScope returns true if the token was granted the given scope.
+func (payload *Payload) HasScope(scope string) bool {
+	return slices.Contains(payload.Scopes, scope)
+}
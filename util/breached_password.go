@@ -0,0 +1,83 @@
+package util
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+const breachedPasswordPrefixLength = 5
+
+// BreachedPasswordSource returns the SHA-1 hash suffixes of the breached passwords whose hash starts with the prefix.
+// Only the first 5 characters of a password hash are ever handed to a source, so sources backed
+// by a remote service, such as the Have I Been Pwned range API, never learn which password is checked.
+type BreachedPasswordSource interface {
+	Range(prefix string) ([]string, error)
+}
+
+// BreachedPasswordFile is a BreachedPasswordSource loaded from a local file
+type BreachedPasswordFile struct {
+	ranges map[string][]string
+}
+
+// LoadBreachedPasswordFile loads a file with the uppercase SHA-1 hash of a breached password per line,
+// optionally followed by :count, as in the files downloaded from Have I Been Pwned
+func LoadBreachedPasswordFile(path string) (*BreachedPasswordFile, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not open breached password file: %w", err)
+	}
+
+	defer file.Close()
+
+	breachedPasswords := &BreachedPasswordFile{ranges: make(map[string][]string)}
+
+	scanner := bufio.NewScanner(file)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+
+		if len(hash) == 0 {
+			continue
+		}
+
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != 2*sha1.Size {
+			return nil, fmt.Errorf("invalid SHA-1 hash on line %d of breached password file", lineNumber)
+		}
+
+		hash = strings.ToUpper(hash)
+		prefix := hash[:breachedPasswordPrefixLength]
+
+		breachedPasswords.ranges[prefix] = append(breachedPasswords.ranges[prefix], hash[breachedPasswordPrefixLength:])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read breached password file: %w", err)
+	}
+
+	return breachedPasswords, nil
+}
+
+// Range returns the hash suffixes of the breached passwords whose hash starts with the prefix
+func (file *BreachedPasswordFile) Range(prefix string) ([]string, error) {
+	return file.ranges[strings.ToUpper(prefix)], nil
+}
+
+// IsBreachedPassword reports whether the password is one of the breached passwords of the source
+func IsBreachedPassword(source BreachedPasswordSource, password string) (bool, error) {
+	hashedPassword := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(hashedPassword[:]))
+
+	suffixes, err := source.Range(hash[:breachedPasswordPrefixLength])
+
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Contains(suffixes, hash[breachedPasswordPrefixLength:]), nil
+}
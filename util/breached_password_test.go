@@ -0,0 +1,44 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// SHA-1 hashes of "password" and "123456"
+const breachedPasswordList = `5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824
+7c4a8d09ca3762af61e59520943dc26494f8941b
+
+`
+
+func writeBreachedPasswordFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestBreachedPasswordFile(t *testing.T) {
+	breachedPasswords, err := LoadBreachedPasswordFile(writeBreachedPasswordFile(t, breachedPasswordList))
+	require.NoError(t, err)
+
+	suffixes, err := breachedPasswords.Range("5baa6")
+	require.NoError(t, err)
+	require.Equal(t, []string{"1E4C9B93F3F0682250B6CF8331B7EE68FD8"}, suffixes)
+
+	for password, isBreached := range map[string]bool{"password": true, "123456": true, RandomString(16): false} {
+		breached, err := IsBreachedPassword(breachedPasswords, password)
+		require.NoError(t, err)
+		require.Equal(t, isBreached, breached, password)
+	}
+}
+
+func TestLoadInvalidBreachedPasswordFile(t *testing.T) {
+	_, err := LoadBreachedPasswordFile(filepath.Join(t.TempDir(), "missing.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = LoadBreachedPasswordFile(writeBreachedPasswordFile(t, breachedPasswordList+"password\n"))
+	require.EqualError(t, err, "invalid SHA-1 hash on line 4 of breached password file")
+}
@@ -7,11 +7,46 @@ import (
 )
 
 type Config struct {
-	DBDriver            string        `mapstructure:"DB_DRIVER"`
-	DBSource            string        `mapstructure:"DB_SOURCE"`
-	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	DBDriver                      string        `mapstructure:"DB_DRIVER"`
+	DBSource                      string        `mapstructure:"DB_SOURCE"`
+	ServerAddress                 string        `mapstructure:"SERVER_ADDRESS"`
+	AppBaseURL                    string        `mapstructure:"APP_BASE_URL"`
+	TokenType                     string        `mapstructure:"TOKEN_TYPE"`
+	TokenSymmetricKey             string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenSymmetricKeys            string        `mapstructure:"TOKEN_SYMMETRIC_KEYS"`
+	TokenActiveKeyID              string        `mapstructure:"TOKEN_ACTIVE_KEY_ID"`
+	TokenAsymmetricPrivateKey     string        `mapstructure:"TOKEN_ASYMMETRIC_PRIVATE_KEY"`
+	AccessTokenDuration           time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration          time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	MFAChallengeDuration          time.Duration `mapstructure:"MFA_CHALLENGE_DURATION"`
+	PasswordHashAlgorithm         string        `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	PasswordBcryptCost            int           `mapstructure:"PASSWORD_BCRYPT_COST"`
+	PasswordArgon2Memory          uint32        `mapstructure:"PASSWORD_ARGON2_MEMORY"`
+	PasswordArgon2Time            uint32        `mapstructure:"PASSWORD_ARGON2_TIME"`
+	PasswordArgon2Parallelism     uint8         `mapstructure:"PASSWORD_ARGON2_PARALLELISM"`
+	PasswordMinLength             int           `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMaxLength             int           `mapstructure:"PASSWORD_MAX_LENGTH"`
+	PasswordRequireLowercase      bool          `mapstructure:"PASSWORD_REQUIRE_LOWERCASE"`
+	PasswordRequireUppercase      bool          `mapstructure:"PASSWORD_REQUIRE_UPPERCASE"`
+	PasswordRequireDigit          bool          `mapstructure:"PASSWORD_REQUIRE_DIGIT"`
+	PasswordRequireSymbol         bool          `mapstructure:"PASSWORD_REQUIRE_SYMBOL"`
+	PasswordBreachedListPath      string        `mapstructure:"PASSWORD_BREACHED_LIST_PATH"`
+	LoginMaxFailedAttempts        int64         `mapstructure:"LOGIN_MAX_FAILED_ATTEMPTS"`
+	LoginMaxFailedAttemptsPerIP   int64         `mapstructure:"LOGIN_MAX_FAILED_ATTEMPTS_PER_IP"`
+	LoginAttemptWindow            time.Duration `mapstructure:"LOGIN_ATTEMPT_WINDOW"`
+	LoginLockoutDuration          time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	EmailSenderType               string        `mapstructure:"EMAIL_SENDER_TYPE"`
+	EmailSenderAddress            string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
+	EmailLogPath                  string        `mapstructure:"EMAIL_LOG_PATH"`
+	EmailVerificationDuration     time.Duration `mapstructure:"EMAIL_VERIFICATION_DURATION"`
+	PasswordResetDuration         time.Duration `mapstructure:"PASSWORD_RESET_DURATION"`
+	FXRates                       string        `mapstructure:"FX_RATES"`
+	FXRatesPath                   string        `mapstructure:"FX_RATES_PATH"`
+	FXRateCacheDuration           time.Duration `mapstructure:"FX_RATE_CACHE_DURATION"`
+	CurrencyReloadInterval        time.Duration `mapstructure:"CURRENCY_RELOAD_INTERVAL"`
+	ScheduledTransferPollInterval time.Duration `mapstructure:"SCHEDULED_TRANSFER_POLL_INTERVAL"`
+	TransferHoldDuration          time.Duration `mapstructure:"TRANSFER_HOLD_DURATION"`
+	TransferHoldExpiryInterval    time.Duration `mapstructure:"TRANSFER_HOLD_EXPIRY_INTERVAL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -1,23 +1,86 @@
 package util
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-
-	"golang.org/x/crypto/bcrypt"
+	"strings"
 )
 
-// HashPassword returns the bcrypt hash of the password
-func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+const apiKeyPrefix = "sbk_"
+
+// GenerateAPIKey returns a new random API key
+func GenerateAPIKey() (string, error) {
+	key := make([]byte, 32)
+
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("Failed to generate API key: %v", err)
+	}
+
+	return apiKeyPrefix + hex.EncodeToString(key), nil
+}
+
+// HashAPIKey returns the SHA-256 hash of the API key.
+// Unlike passwords, keys are random enough not to need a slow hash, and can be looked up by their hash
+func HashAPIKey(key string) string {
+	return sha256Hex(key)
+}
+
+// GenerateRecoveryCodes returns n random single use codes, formatted as xxxxx-xxxxx
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+
+	for i := range codes {
+		code := make([]byte, 5)
+
+		if _, err := rand.Read(code); err != nil {
+			return nil, fmt.Errorf("Failed to generate recovery codes: %v", err)
+		}
+
+		encoded := hex.EncodeToString(code)
+		codes[i] = encoded[:5] + "-" + encoded[5:]
+	}
+
+	return codes, nil
+}
+
+// HashRecoveryCode returns the SHA-256 hash of the recovery code, ignoring case and surrounding spaces
+func HashRecoveryCode(code string) string {
+	return sha256Hex(strings.ToLower(strings.TrimSpace(code)))
+}
+
+// HashRequest returns the SHA-256 hash of the request encoded as JSON,
+// so a reused idempotency key can be told apart from a retry of the same request
+func HashRequest(request any) (string, error) {
+	body, err := json.Marshal(request)
 
 	if err != nil {
-		return "", fmt.Errorf("Failed to hash password: %v", err)
+		return "", err
+	}
+
+	return sha256Hex(string(body)), nil
+}
+
+func sha256Hex(value string) string {
+	hashedValue := sha256.Sum256([]byte(value))
+
+	return hex.EncodeToString(hashedValue[:])
+}
+
+// GenerateSecretCode returns a new random code, to be sent to users for one time actions such as verifying their email
+func GenerateSecretCode() (string, error) {
+	code := make([]byte, 32)
+
+	if _, err := rand.Read(code); err != nil {
+		return "", fmt.Errorf("Failed to generate secret code: %v", err)
 	}
 
-	return string(hashedPassword), nil
+	return hex.EncodeToString(code), nil
 }
 
-// CheckPassword checks if provided password matches the hashed password after being hashed too
-func CheckPassword(hashedPassword string, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+// HashSecretCode returns the SHA-256 hash of the secret code, so it can be stored and looked up without being exposed
+func HashSecretCode(code string) string {
+	return sha256Hex(code)
 }
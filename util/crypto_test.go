@@ -1,52 +1,67 @@
 package util
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
-	"golang.org/x/crypto/bcrypt"
 )
 
-func TestCorrectPassword(t *testing.T) {
-	password := RandomString(8)
-
-	hashedPassword, err := HashPassword(password)
+func TestGenerateAPIKey(t *testing.T) {
+	firstKey, err := GenerateAPIKey()
 	require.NoError(t, err)
-	require.NotEmpty(t, hashedPassword)
+	require.Regexp(t, `^sbk_[0-9a-f]{64}$`, firstKey)
 
-	err = CheckPassword(hashedPassword, password)
+	secondKey, err := GenerateAPIKey()
 	require.NoError(t, err)
-}
+	require.NotEqual(t, firstKey, secondKey)
 
-func TestWrongPassword(t *testing.T) {
-	password := RandomString(8)
+	require.Equal(t, HashAPIKey(firstKey), HashAPIKey(firstKey))
+	require.NotEqual(t, HashAPIKey(firstKey), HashAPIKey(secondKey))
+}
 
-	hashedPassword, err := HashPassword(password)
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
 	require.NoError(t, err)
-	require.NotEmpty(t, hashedPassword)
-
-	wrongPassword := RandomString(8)
+	require.Len(t, codes, 10)
 
-	err = CheckPassword(hashedPassword, wrongPassword)
-	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+	for _, code := range codes {
+		require.Regexp(t, `^[0-9a-f]{5}-[0-9a-f]{5}$`, code)
+		require.Equal(t, HashRecoveryCode(code), HashRecoveryCode(" "+strings.ToUpper(code)+" "))
+	}
 }
 
-func TestDifferentHashPasswordOutputs(t *testing.T) {
-	password := RandomString(8)
+func TestGenerateSecretCode(t *testing.T) {
+	firstCode, err := GenerateSecretCode()
+	require.NoError(t, err)
+	require.Regexp(t, `^[0-9a-f]{64}$`, firstCode)
 
-	firstHashedPassword, err := HashPassword(password)
+	secondCode, err := GenerateSecretCode()
 	require.NoError(t, err)
-	require.NotEmpty(t, firstHashedPassword)
+	require.NotEqual(t, firstCode, secondCode)
+
+	require.Equal(t, HashSecretCode(firstCode), HashSecretCode(firstCode))
+	require.NotEqual(t, HashSecretCode(firstCode), HashSecretCode(secondCode))
+}
+
+func TestHashRequest(t *testing.T) {
+	type request struct {
+		Amount   int64  `json:"amount"`
+		Currency string `json:"currency"`
+	}
 
-	err = CheckPassword(firstHashedPassword, password)
+	firstHash, err := HashRequest(request{Amount: 10, Currency: USD})
 	require.NoError(t, err)
+	require.Regexp(t, `^[0-9a-f]{64}$`, firstHash)
 
-	secondHashedPassword, err := HashPassword(password)
+	secondHash, err := HashRequest(request{Amount: 10, Currency: USD})
 	require.NoError(t, err)
-	require.NotEmpty(t, secondHashedPassword)
+	require.Equal(t, firstHash, secondHash)
 
-	err = CheckPassword(secondHashedPassword, password)
+	otherHash, err := HashRequest(request{Amount: 11, Currency: USD})
 	require.NoError(t, err)
+	require.NotEqual(t, firstHash, otherHash)
 
-	require.NotEqual(t, firstHashedPassword, secondHashedPassword)
+	_, err = HashRequest(func() {})
+	require.Error(t, err)
 }
@@ -1,15 +1,129 @@
 package util
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
 const (
 	USD = "USD"
 	EUR = "EUR"
 	BRL = "BRL"
+	GBP = "GBP"
+	JPY = "JPY"
+	BHD = "BHD"
 )
 
-func IsSupportedCurrency(currency string) bool {
-	switch currency {
-	case USD, EUR, BRL:
-		return true
+// Currency is an ISO 4217 currency accounts can be opened in, as stored in the currencies table
+type Currency struct {
+	Code        string `json:"code"`
+	NumericCode int32  `json:"numeric_code"`
+	// Exponent is the number of digits of the minor unit amounts are stored in, such as 2 for cents
+	Exponent  int32  `json:"exponent"`
+	Symbol    string `json:"symbol"`
+	IsEnabled bool   `json:"is_enabled"`
+}
+
+// FormatAmount formats the amount, given in the minor unit of the currency, with its symbol and decimal places
+func (currency Currency) FormatAmount(amount int64) string {
+	sign := ""
+
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	if currency.Exponent <= 0 {
+		return fmt.Sprintf("%s%s%d", sign, currency.Symbol, amount)
+	}
+
+	digits := fmt.Sprintf("%0*d", currency.Exponent+1, amount)
+	separator := len(digits) - int(currency.Exponent)
+
+	return fmt.Sprintf("%s%s%s.%s", sign, currency.Symbol, digits[:separator], digits[separator:])
+}
+
+// DefaultCurrencies are the currencies the currencies table is seeded with,
+// used until the registry is loaded from the database
+var DefaultCurrencies = []Currency{
+	{Code: BHD, NumericCode: 48, Exponent: 3, Symbol: "BD", IsEnabled: false},
+	{Code: BRL, NumericCode: 986, Exponent: 2, Symbol: "R$", IsEnabled: true},
+	{Code: EUR, NumericCode: 978, Exponent: 2, Symbol: "€", IsEnabled: true},
+	{Code: GBP, NumericCode: 826, Exponent: 2, Symbol: "£", IsEnabled: false},
+	{Code: JPY, NumericCode: 392, Exponent: 0, Symbol: "¥", IsEnabled: false},
+	{Code: USD, NumericCode: 840, Exponent: 2, Symbol: "$", IsEnabled: true},
+}
+
+// CurrencyRegistry keeps the currencies of the bank in memory, so validating and formatting amounts doesn't hit the database.
+// It is safe for concurrent use
+type CurrencyRegistry struct {
+	mutex      sync.RWMutex
+	currencies map[string]Currency
+}
+
+// NewCurrencyRegistry creates a CurrencyRegistry with the given currencies
+func NewCurrencyRegistry(currencies []Currency) *CurrencyRegistry {
+	registry := &CurrencyRegistry{}
+	registry.Replace(currencies)
+
+	return registry
+}
+
+// Replace swaps all currencies of the registry, such as after loading them from the database
+func (registry *CurrencyRegistry) Replace(currencies []Currency) {
+	currenciesByCode := make(map[string]Currency, len(currencies))
+
+	for _, currency := range currencies {
+		currenciesByCode[currency.Code] = currency
 	}
-	return false
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	registry.currencies = currenciesByCode
+}
+
+// Set adds the currency to the registry, or updates it if it is already there
+func (registry *CurrencyRegistry) Set(currency Currency) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	registry.currencies[currency.Code] = currency
+}
+
+// Get returns the currency with the code, whether it is enabled or not
+func (registry *CurrencyRegistry) Get(code string) (Currency, bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	currency, ok := registry.currencies[code]
+
+	return currency, ok
+}
+
+// IsSupported reports whether the currency is in the registry and enabled
+func (registry *CurrencyRegistry) IsSupported(code string) bool {
+	currency, ok := registry.Get(code)
+
+	return ok && currency.IsEnabled
+}
+
+// List returns all currencies of the registry, sorted by code
+func (registry *CurrencyRegistry) List() []Currency {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	currencies := make([]Currency, 0, len(registry.currencies))
+
+	for _, currency := range registry.currencies {
+		currencies = append(currencies, currency)
+	}
+
+	slices.SortFunc(currencies, func(a, b Currency) int {
+		return strings.Compare(a.Code, b.Code)
+	})
+
+	return currencies
 }
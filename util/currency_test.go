@@ -0,0 +1,59 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyFormatAmount(t *testing.T) {
+	usd := Currency{Code: USD, Exponent: 2, Symbol: "$"}
+
+	require.Equal(t, "$123.45", usd.FormatAmount(12345))
+	require.Equal(t, "$0.05", usd.FormatAmount(5))
+	require.Equal(t, "$0.00", usd.FormatAmount(0))
+	require.Equal(t, "-$1.50", usd.FormatAmount(-150))
+
+	jpy := Currency{Code: JPY, Exponent: 0, Symbol: "¥"}
+
+	require.Equal(t, "¥12345", jpy.FormatAmount(12345))
+	require.Equal(t, "-¥7", jpy.FormatAmount(-7))
+
+	bhd := Currency{Code: BHD, Exponent: 3, Symbol: "BD"}
+
+	require.Equal(t, "BD12.345", bhd.FormatAmount(12345))
+	require.Equal(t, "BD0.001", bhd.FormatAmount(1))
+}
+
+func TestCurrencyRegistry(t *testing.T) {
+	registry := NewCurrencyRegistry(DefaultCurrencies)
+
+	require.Equal(t, DefaultCurrencies, registry.List())
+	require.True(t, registry.IsSupported(USD))
+	require.False(t, registry.IsSupported(JPY))
+	require.False(t, registry.IsSupported("AUD"))
+
+	jpy, ok := registry.Get(JPY)
+	require.True(t, ok)
+	require.Equal(t, int32(392), jpy.NumericCode)
+
+	jpy.IsEnabled = true
+	registry.Set(jpy)
+	require.True(t, registry.IsSupported(JPY))
+
+	registry.Replace([]Currency{{Code: EUR, NumericCode: 978, Exponent: 2, Symbol: "€", IsEnabled: true}})
+	require.True(t, registry.IsSupported(EUR))
+	require.False(t, registry.IsSupported(USD))
+	require.Len(t, registry.List(), 1)
+
+	_, ok = registry.Get(USD)
+	require.False(t, ok)
+}
+
+func TestRandomCurrency(t *testing.T) {
+	registry := NewCurrencyRegistry(DefaultCurrencies)
+
+	for i := 0; i < 20; i++ {
+		require.True(t, registry.IsSupported(RandomCurrency()))
+	}
+}
@@ -0,0 +1,208 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms that can be selected with the PASSWORD_HASH_ALGORITHM config.
+const (
+	Argon2idAlgorithm = "argon2id"
+	BcryptAlgorithm   = "bcrypt"
+)
+
+const (
+	argon2idPrefix     = "$argon2id$"
+	argon2idSaltLength = 16
+	argon2idKeyLength  = 32
+)
+
+// ErrMismatchedPassword is returned when a password doesn't match the hashed password, whatever algorithm hashed it
+var ErrMismatchedPassword = errors.New("password doesn't match the hashed password")
+
+var errInvalidArgon2idHash = errors.New("invalid argon2id hash")
+
+// Argon2idParams are the cost parameters of argon2id, with the memory in KiB
+type Argon2idParams struct {
+	Memory      uint32
+	Time        uint32
+	Parallelism uint8
+}
+
+// DefaultArgon2idParams follow the OWASP recommendation for argon2id
+var DefaultArgon2idParams = Argon2idParams{Memory: 46 * 1024, Time: 1, Parallelism: 1}
+
+// PasswordHasher hashes passwords with a single algorithm and cost.
+// Hashes carry their algorithm, version and cost, so passwords hashed by older
+// hashers can still be checked, and rehashed once NeedsRehash reports they are outdated.
+type PasswordHasher struct {
+	algorithm      string
+	bcryptCost     int
+	argon2idParams Argon2idParams
+}
+
+// DefaultPasswordHasher hashes passwords with argon2id and the default params
+var DefaultPasswordHasher = PasswordHasher{
+	algorithm:      Argon2idAlgorithm,
+	bcryptCost:     bcrypt.DefaultCost,
+	argon2idParams: DefaultArgon2idParams,
+}
+
+// NewPasswordHasher creates the PasswordHasher set in the config.
+// Unset fields keep the values of DefaultPasswordHasher.
+func NewPasswordHasher(config Config) (PasswordHasher, error) {
+	hasher := DefaultPasswordHasher
+
+	switch config.PasswordHashAlgorithm {
+	case "":
+	case Argon2idAlgorithm, BcryptAlgorithm:
+		hasher.algorithm = config.PasswordHashAlgorithm
+	default:
+		return PasswordHasher{}, fmt.Errorf("unsupported password hash algorithm %q", config.PasswordHashAlgorithm)
+	}
+
+	if config.PasswordBcryptCost != 0 {
+		if config.PasswordBcryptCost < bcrypt.MinCost || config.PasswordBcryptCost > bcrypt.MaxCost {
+			return PasswordHasher{}, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+
+		hasher.bcryptCost = config.PasswordBcryptCost
+	}
+
+	if config.PasswordArgon2Memory != 0 {
+		hasher.argon2idParams.Memory = config.PasswordArgon2Memory
+	}
+
+	if config.PasswordArgon2Time != 0 {
+		hasher.argon2idParams.Time = config.PasswordArgon2Time
+	}
+
+	if config.PasswordArgon2Parallelism != 0 {
+		hasher.argon2idParams.Parallelism = config.PasswordArgon2Parallelism
+	}
+
+	return hasher, nil
+}
+
+// Hash returns the hash of the password
+func (hasher PasswordHasher) Hash(password string) (string, error) {
+	if hasher.algorithm == BcryptAlgorithm {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), hasher.bcryptCost)
+
+		if err != nil {
+			return "", fmt.Errorf("Failed to hash password: %v", err)
+		}
+
+		return string(hashedPassword), nil
+	}
+
+	salt := make([]byte, argon2idSaltLength)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("Failed to hash password: %v", err)
+	}
+
+	params := hasher.argon2idParams
+	key := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Parallelism, argon2idKeyLength)
+
+	return fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		params.Memory,
+		params.Time,
+		params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// NeedsRehash reports whether the hashed password was hashed with another algorithm or cost than the hasher's
+func (hasher PasswordHasher) NeedsRehash(hashedPassword string) bool {
+	if hasher.algorithm == BcryptAlgorithm {
+		cost, err := bcrypt.Cost([]byte(hashedPassword))
+		return err != nil || cost != hasher.bcryptCost
+	}
+
+	params, _, key, err := parseArgon2idHash(hashedPassword)
+
+	return err != nil || params != hasher.argon2idParams || len(key) != argon2idKeyLength
+}
+
+// HashPassword returns the hash of the password, using DefaultPasswordHasher
+func HashPassword(password string) (string, error) {
+	return DefaultPasswordHasher.Hash(password)
+}
+
+// CheckPassword checks if provided password matches the hashed password after being hashed too,
+// with the algorithm and cost the hashed password was hashed with
+func CheckPassword(hashedPassword string, password string) error {
+	if !strings.HasPrefix(hashedPassword, argon2idPrefix) {
+		err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrMismatchedPassword
+		}
+
+		return err
+	}
+
+	params, salt, key, err := parseArgon2idHash(hashedPassword)
+
+	if err != nil {
+		return err
+	}
+
+	passwordKey := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Parallelism, uint32(len(key)))
+
+	if subtle.ConstantTimeCompare(passwordKey, key) != 1 {
+		return ErrMismatchedPassword
+	}
+
+	return nil
+}
+
+// parseArgon2idHash parses a hash in the $argon2id$v=19$m=47104,t=1,p=1$salt$key format
+func parseArgon2idHash(hashedPassword string) (params Argon2idParams, salt []byte, key []byte, err error) {
+	fields := strings.Split(hashedPassword, "$")
+
+	if len(fields) != 6 || fields[1] != Argon2idAlgorithm {
+		err = errInvalidArgon2idHash
+		return
+	}
+
+	var version int
+
+	if _, err = fmt.Sscanf(fields[2], "v=%d", &version); err != nil {
+		err = errInvalidArgon2idHash
+		return
+	}
+
+	if version != argon2.Version {
+		err = fmt.Errorf("unsupported argon2 version %d", version)
+		return
+	}
+
+	if _, err = fmt.Sscanf(fields[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Parallelism); err != nil {
+		err = errInvalidArgon2idHash
+		return
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(fields[4]); err != nil {
+		err = errInvalidArgon2idHash
+		return
+	}
+
+	if key, err = base64.RawStdEncoding.DecodeString(fields[5]); err != nil || len(key) == 0 {
+		err = errInvalidArgon2idHash
+	}
+
+	return
+}
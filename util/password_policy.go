@@ -0,0 +1,176 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rules of the password policy, reported in PasswordPolicyError
+const (
+	PasswordMinLengthRule        = "min_length"
+	PasswordMaxLengthRule        = "max_length"
+	PasswordLowercaseRule        = "lowercase"
+	PasswordUppercaseRule        = "uppercase"
+	PasswordDigitRule            = "digit"
+	PasswordSymbolRule           = "symbol"
+	PasswordContainsUsernameRule = "contains_username"
+	PasswordContainsEmailRule    = "contains_email"
+	PasswordBreachedRule         = "breached"
+)
+
+const (
+	minPasswordLength        = 8
+	defaultMaxPasswordLength = 64
+	// bcrypt refuses to hash longer passwords
+	bcryptMaxPasswordBytes = 72
+	// shorter usernames and emails would forbid too many passwords
+	minPasswordUserInputLength = 3
+)
+
+// PasswordPolicy holds the rules new passwords must follow.
+// MaxBytes limits the length in bytes for hash algorithms that can't take longer passwords, and is unlimited when zero.
+type PasswordPolicy struct {
+	MinLength         int
+	MaxLength         int
+	MaxBytes          int
+	RequireLowercase  bool
+	RequireUppercase  bool
+	RequireDigit      bool
+	RequireSymbol     bool
+	BreachedPasswords BreachedPasswordSource
+}
+
+// PasswordPolicyViolation is a rule the password failed to follow
+type PasswordPolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError lists every rule the password failed to follow
+type PasswordPolicyError struct {
+	Violations []PasswordPolicyViolation
+}
+
+func (err *PasswordPolicyError) Error() string {
+	messages := make([]string, len(err.Violations))
+
+	for i, violation := range err.Violations {
+		messages[i] = violation.Message
+	}
+
+	return "password " + strings.Join(messages, ", ")
+}
+
+// NewPasswordPolicy creates the password policy set in the config.
+// Lengths default to 8 and 64 characters, and breached passwords are only checked when PASSWORD_BREACHED_LIST_PATH is set.
+// Passwords are also limited to 72 bytes when they are hashed with bcrypt, which can't hash longer ones.
+func NewPasswordPolicy(config Config) (PasswordPolicy, error) {
+	policy := PasswordPolicy{
+		MinLength:        config.PasswordMinLength,
+		MaxLength:        config.PasswordMaxLength,
+		RequireLowercase: config.PasswordRequireLowercase,
+		RequireUppercase: config.PasswordRequireUppercase,
+		RequireDigit:     config.PasswordRequireDigit,
+		RequireSymbol:    config.PasswordRequireSymbol,
+	}
+
+	if policy.MinLength == 0 {
+		policy.MinLength = minPasswordLength
+	}
+
+	if policy.MaxLength == 0 {
+		policy.MaxLength = defaultMaxPasswordLength
+	}
+
+	if policy.MinLength < minPasswordLength {
+		return PasswordPolicy{}, fmt.Errorf("password min length can't be lower than %d", minPasswordLength)
+	}
+
+	if policy.MaxLength < policy.MinLength {
+		return PasswordPolicy{}, fmt.Errorf("password max length can't be lower than its min length")
+	}
+
+	if config.PasswordHashAlgorithm == BcryptAlgorithm {
+		policy.MaxBytes = bcryptMaxPasswordBytes
+	}
+
+	if len(config.PasswordBreachedListPath) > 0 {
+		breachedPasswords, err := LoadBreachedPasswordFile(config.PasswordBreachedListPath)
+
+		if err != nil {
+			return PasswordPolicy{}, err
+		}
+
+		policy.BreachedPasswords = breachedPasswords
+	}
+
+	return policy, nil
+}
+
+// Validate checks the password against every rule of the policy, returning a PasswordPolicyError listing the failed ones.
+// The username and email are those of the user the password is for, which it must not contain.
+func (policy PasswordPolicy) Validate(password string, username string, email string) error {
+	var violations []PasswordPolicyViolation
+
+	violate := func(rule string, message string) {
+		violations = append(violations, PasswordPolicyViolation{Rule: rule, Message: message})
+	}
+
+	if length := utf8.RuneCountInString(password); length < policy.MinLength {
+		violate(PasswordMinLengthRule, fmt.Sprintf("must have at least %d characters", policy.MinLength))
+	} else if length > policy.MaxLength {
+		violate(PasswordMaxLengthRule, fmt.Sprintf("must have at most %d characters", policy.MaxLength))
+	} else if policy.MaxBytes > 0 && len(password) > policy.MaxBytes {
+		violate(PasswordMaxLengthRule, fmt.Sprintf("must have at most %d bytes", policy.MaxBytes))
+	}
+
+	if policy.RequireLowercase && !strings.ContainsFunc(password, unicode.IsLower) {
+		violate(PasswordLowercaseRule, "must contain a lowercase letter")
+	}
+
+	if policy.RequireUppercase && !strings.ContainsFunc(password, unicode.IsUpper) {
+		violate(PasswordUppercaseRule, "must contain an uppercase letter")
+	}
+
+	if policy.RequireDigit && !strings.ContainsFunc(password, unicode.IsDigit) {
+		violate(PasswordDigitRule, "must contain a digit")
+	}
+
+	if policy.RequireSymbol && !strings.ContainsFunc(password, isPasswordSymbol) {
+		violate(PasswordSymbolRule, "must contain a symbol")
+	}
+
+	lowerPassword := strings.ToLower(password)
+
+	if len(username) >= minPasswordUserInputLength && strings.Contains(lowerPassword, strings.ToLower(username)) {
+		violate(PasswordContainsUsernameRule, "must not contain the username")
+	}
+
+	if localPart, _, _ := strings.Cut(email, "@"); len(localPart) >= minPasswordUserInputLength && strings.Contains(lowerPassword, strings.ToLower(localPart)) {
+		violate(PasswordContainsEmailRule, "must not contain the email")
+	}
+
+	if policy.BreachedPasswords != nil {
+		isBreached, err := IsBreachedPassword(policy.BreachedPasswords, password)
+
+		if err != nil {
+			return err
+		}
+
+		if isBreached {
+			violate(PasswordBreachedRule, "has appeared in a data breach")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+func isPasswordSymbol(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r)
+}
@@ -0,0 +1,127 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPasswordPolicy(t *testing.T) {
+	policy, err := NewPasswordPolicy(Config{})
+	require.NoError(t, err)
+	require.Equal(t, PasswordPolicy{MinLength: 8, MaxLength: 64}, policy)
+
+	policy, err = NewPasswordPolicy(Config{PasswordBreachedListPath: writeBreachedPasswordFile(t, breachedPasswordList)})
+	require.NoError(t, err)
+	require.NotNil(t, policy.BreachedPasswords)
+
+	policy, err = NewPasswordPolicy(Config{PasswordHashAlgorithm: BcryptAlgorithm})
+	require.NoError(t, err)
+	require.Equal(t, PasswordPolicy{MinLength: 8, MaxLength: 64, MaxBytes: 72}, policy)
+
+	_, err = NewPasswordPolicy(Config{PasswordMinLength: 6})
+	require.EqualError(t, err, "password min length can't be lower than 8")
+
+	_, err = NewPasswordPolicy(Config{PasswordMinLength: 12, PasswordMaxLength: 10})
+	require.EqualError(t, err, "password max length can't be lower than its min length")
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	breachedPasswords, err := LoadBreachedPasswordFile(writeBreachedPasswordFile(t, breachedPasswordList))
+	require.NoError(t, err)
+
+	policy := PasswordPolicy{
+		MinLength:         8,
+		MaxLength:         16,
+		RequireLowercase:  true,
+		RequireUppercase:  true,
+		RequireDigit:      true,
+		RequireSymbol:     true,
+		BreachedPasswords: breachedPasswords,
+	}
+
+	testCases := []struct {
+		name          string
+		password      string
+		expectedRules []string
+	}{
+		{
+			name:     "OK",
+			password: "Correct-Horse-9",
+		},
+		{
+			name:          "Too Short",
+			password:      "Ab1!",
+			expectedRules: []string{PasswordMinLengthRule},
+		},
+		{
+			name:          "Too Long",
+			password:      "Correct-Horse-Battery-9",
+			expectedRules: []string{PasswordMaxLengthRule},
+		},
+		{
+			name:          "Missing Character Classes",
+			password:      "correcthorse",
+			expectedRules: []string{PasswordUppercaseRule, PasswordDigitRule, PasswordSymbolRule},
+		},
+		{
+			name:          "Only Symbols",
+			password:      "!!!!!!!!",
+			expectedRules: []string{PasswordLowercaseRule, PasswordUppercaseRule, PasswordDigitRule},
+		},
+		{
+			name:          "Contains Username And Email",
+			password:      "John.Doe-johnny9",
+			expectedRules: []string{PasswordContainsUsernameRule, PasswordContainsEmailRule},
+		},
+		{
+			name:          "Breached",
+			password:      "password",
+			expectedRules: []string{PasswordUppercaseRule, PasswordDigitRule, PasswordSymbolRule, PasswordBreachedRule},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := policy.Validate(testCase.password, "johnny", "john.doe@example.com")
+
+			if len(testCase.expectedRules) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var policyErr *PasswordPolicyError
+			require.ErrorAs(t, err, &policyErr)
+
+			rules := make([]string, len(policyErr.Violations))
+
+			for i, violation := range policyErr.Violations {
+				rules[i] = violation.Rule
+			}
+
+			require.Equal(t, testCase.expectedRules, rules)
+		})
+	}
+}
+
+func TestPasswordPolicyValidateMaxBytes(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 8, MaxLength: 64, MaxBytes: 72}
+
+	// 30 characters, but 90 bytes
+	err := policy.Validate(strings.Repeat("€", 30), "", "")
+	require.EqualError(t, err, "password must have at most 72 bytes")
+
+	var policyErr *PasswordPolicyError
+	require.ErrorAs(t, err, &policyErr)
+	require.Equal(t, []PasswordPolicyViolation{{Rule: PasswordMaxLengthRule, Message: "must have at most 72 bytes"}}, policyErr.Violations)
+
+	require.NoError(t, policy.Validate(strings.Repeat("€", 24), "", ""))
+}
+
+func TestPasswordPolicyErrorMessage(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 8, MaxLength: 64, RequireDigit: true}
+
+	err := policy.Validate("short", "", "")
+	require.EqualError(t, err, "password must have at least 8 characters, must contain a digit")
+}
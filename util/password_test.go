@@ -0,0 +1,162 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCorrectPassword(t *testing.T) {
+	password := RandomString(8)
+
+	hashedPassword, err := HashPassword(password)
+	require.NoError(t, err)
+	require.NotEmpty(t, hashedPassword)
+
+	err = CheckPassword(hashedPassword, password)
+	require.NoError(t, err)
+}
+
+func TestWrongPassword(t *testing.T) {
+	password := RandomString(8)
+
+	hashedPassword, err := HashPassword(password)
+	require.NoError(t, err)
+	require.NotEmpty(t, hashedPassword)
+
+	wrongPassword := RandomString(8)
+
+	err = CheckPassword(hashedPassword, wrongPassword)
+	require.ErrorIs(t, err, ErrMismatchedPassword)
+}
+
+func TestDifferentHashPasswordOutputs(t *testing.T) {
+	password := RandomString(8)
+
+	firstHashedPassword, err := HashPassword(password)
+	require.NoError(t, err)
+	require.NotEmpty(t, firstHashedPassword)
+
+	err = CheckPassword(firstHashedPassword, password)
+	require.NoError(t, err)
+
+	secondHashedPassword, err := HashPassword(password)
+	require.NoError(t, err)
+	require.NotEmpty(t, secondHashedPassword)
+
+	err = CheckPassword(secondHashedPassword, password)
+	require.NoError(t, err)
+
+	require.NotEqual(t, firstHashedPassword, secondHashedPassword)
+}
+
+func TestArgon2idHashFormat(t *testing.T) {
+	hashedPassword, err := HashPassword(RandomString(8))
+	require.NoError(t, err)
+	require.Regexp(t, `^\$argon2id\$v=19\$m=47104,t=1,p=1\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`, hashedPassword)
+}
+
+func TestLongPassword(t *testing.T) {
+	// bcrypt only looks at the first 72 bytes of a password, argon2id uses all of them
+	password := RandomString(72)
+
+	hashedPassword, err := HashPassword(password + "a")
+	require.NoError(t, err)
+
+	require.NoError(t, CheckPassword(hashedPassword, password+"a"))
+	require.ErrorIs(t, CheckPassword(hashedPassword, password+"b"), ErrMismatchedPassword)
+}
+
+func TestCheckBcryptPassword(t *testing.T) {
+	password := RandomString(8)
+
+	hasher, err := NewPasswordHasher(Config{PasswordHashAlgorithm: BcryptAlgorithm, PasswordBcryptCost: bcrypt.MinCost})
+	require.NoError(t, err)
+
+	hashedPassword, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hashedPassword, "$2a$"))
+
+	require.NoError(t, CheckPassword(hashedPassword, password))
+	require.ErrorIs(t, CheckPassword(hashedPassword, RandomString(8)), ErrMismatchedPassword)
+}
+
+func TestCheckInvalidHash(t *testing.T) {
+	password := RandomString(8)
+
+	hashedPassword, err := HashPassword(password)
+	require.NoError(t, err)
+
+	fields := strings.Split(hashedPassword, "$")
+
+	invalidHashes := []string{
+		"",
+		"$argon2id$",
+		strings.Join(fields[:5], "$"),
+		strings.Replace(hashedPassword, "v=19", "v=16", 1),
+		strings.Replace(hashedPassword, "m=47104", "m=x", 1),
+		strings.Replace(hashedPassword, fields[4], "!!!", 1),
+	}
+
+	for _, invalidHash := range invalidHashes {
+		err := CheckPassword(invalidHash, password)
+		require.Error(t, err, invalidHash)
+		require.NotErrorIs(t, err, ErrMismatchedPassword)
+	}
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	hasher, err := NewPasswordHasher(Config{})
+	require.NoError(t, err)
+	require.Equal(t, DefaultPasswordHasher, hasher)
+
+	hasher, err = NewPasswordHasher(Config{
+		PasswordHashAlgorithm:     Argon2idAlgorithm,
+		PasswordArgon2Memory:      1024,
+		PasswordArgon2Time:        1,
+		PasswordArgon2Parallelism: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, Argon2idParams{Memory: 1024, Time: 1, Parallelism: 1}, hasher.argon2idParams)
+
+	_, err = NewPasswordHasher(Config{PasswordHashAlgorithm: "md5"})
+	require.EqualError(t, err, `unsupported password hash algorithm "md5"`)
+
+	_, err = NewPasswordHasher(Config{PasswordHashAlgorithm: BcryptAlgorithm, PasswordBcryptCost: 40})
+	require.EqualError(t, err, fmt.Sprintf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost))
+}
+
+func TestNeedsRehash(t *testing.T) {
+	password := RandomString(8)
+
+	bcryptHasher, err := NewPasswordHasher(Config{PasswordHashAlgorithm: BcryptAlgorithm, PasswordBcryptCost: bcrypt.MinCost})
+	require.NoError(t, err)
+
+	cheapArgon2idHasher, err := NewPasswordHasher(Config{PasswordArgon2Memory: 1024, PasswordArgon2Time: 1, PasswordArgon2Parallelism: 1})
+	require.NoError(t, err)
+
+	bcryptHash, err := bcryptHasher.Hash(password)
+	require.NoError(t, err)
+
+	cheapArgon2idHash, err := cheapArgon2idHasher.Hash(password)
+	require.NoError(t, err)
+	require.NoError(t, CheckPassword(cheapArgon2idHash, password))
+
+	defaultHash, err := HashPassword(password)
+	require.NoError(t, err)
+
+	require.False(t, DefaultPasswordHasher.NeedsRehash(defaultHash))
+	require.True(t, DefaultPasswordHasher.NeedsRehash(bcryptHash))
+	require.True(t, DefaultPasswordHasher.NeedsRehash(cheapArgon2idHash))
+
+	require.False(t, bcryptHasher.NeedsRehash(bcryptHash))
+	require.True(t, bcryptHasher.NeedsRehash(defaultHash))
+
+	// a higher bcrypt cost is also outdated
+	strongerBcryptHasher, err := NewPasswordHasher(Config{PasswordHashAlgorithm: BcryptAlgorithm, PasswordBcryptCost: bcrypt.MinCost + 1})
+	require.NoError(t, err)
+	require.True(t, strongerBcryptHasher.NeedsRehash(bcryptHash))
+}
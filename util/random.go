@@ -43,7 +43,13 @@ func RandomAmount() int64 {
 }
 
 func RandomCurrency() string {
-	currencies := []string{USD, EUR, BRL}
+	var currencies []string
+
+	for _, currency := range DefaultCurrencies {
+		if currency.IsEnabled {
+			currencies = append(currencies, currency.Code)
+		}
+	}
 
 	n := len(currencies)
 
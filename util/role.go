@@ -0,0 +1,7 @@
+package util
+
+const (
+	DepositorRole = "depositor"
+	BankerRole    = "banker"
+	AdminRole     = "admin"
+)
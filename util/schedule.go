@@ -0,0 +1,74 @@
+package util
+
+import (
+	"slices"
+	"time"
+)
+
+// Frequencies a scheduled transfer can be repeated with
+const (
+	FrequencyOnce    = "once"
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+var frequencies = []string{FrequencyOnce, FrequencyDaily, FrequencyWeekly, FrequencyMonthly}
+
+// IsSupportedFrequency returns true if the frequency is supported
+func IsSupportedFrequency(frequency string) bool {
+	return slices.Contains(frequencies, frequency)
+}
+
+// Schedule is when a scheduled transfer runs: at StartAt and then every Interval days, weeks or months, until EndAt, if set
+type Schedule struct {
+	Frequency string
+	Interval  int32
+	StartAt   time.Time
+	EndAt     time.Time
+}
+
+// Occurrence returns the n-th run of the schedule, starting at zero.
+// Monthly runs are always computed from StartAt, so runs on the 31st fall on the last day of shorter months
+func (schedule Schedule) Occurrence(n int) time.Time {
+	step := n * int(max(schedule.Interval, 1))
+
+	switch schedule.Frequency {
+	case FrequencyDaily:
+		return schedule.StartAt.AddDate(0, 0, step)
+	case FrequencyWeekly:
+		return schedule.StartAt.AddDate(0, 0, 7*step)
+	case FrequencyMonthly:
+		return addMonths(schedule.StartAt, step)
+	default:
+		return schedule.StartAt
+	}
+}
+
+// NextRun returns the first run of the schedule after the given time, or false if it has no more runs.
+// Runs missed in between, such as while the executor was down, are skipped
+func (schedule Schedule) NextRun(after time.Time) (time.Time, bool) {
+	for n := 0; ; n++ {
+		run := schedule.Occurrence(n)
+
+		if !schedule.EndAt.IsZero() && run.After(schedule.EndAt) {
+			return time.Time{}, false
+		}
+
+		if run.After(after) {
+			return run, true
+		}
+
+		if schedule.Frequency == FrequencyOnce || !IsSupportedFrequency(schedule.Frequency) {
+			return time.Time{}, false
+		}
+	}
+}
+
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	firstOfMonth := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	return firstOfMonth.AddDate(0, 0, min(day, lastDay)-1)
+}
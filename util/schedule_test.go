@@ -0,0 +1,62 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleNextRun(t *testing.T) {
+	startAt := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	once := Schedule{Frequency: FrequencyOnce, StartAt: startAt}
+
+	run, ok := once.NextRun(startAt.Add(-time.Second))
+	require.True(t, ok)
+	require.Equal(t, startAt, run)
+
+	_, ok = once.NextRun(startAt)
+	require.False(t, ok)
+
+	daily := Schedule{Frequency: FrequencyDaily, Interval: 2, StartAt: startAt}
+
+	run, ok = daily.NextRun(startAt)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, time.February, 2, 9, 0, 0, 0, time.UTC), run)
+
+	// runs missed in between are skipped
+	run, ok = daily.NextRun(startAt.AddDate(0, 0, 5))
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC), run)
+
+	weekly := Schedule{Frequency: FrequencyWeekly, Interval: 1, StartAt: startAt, EndAt: startAt.AddDate(0, 0, 10)}
+
+	run, ok = weekly.NextRun(startAt)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, time.February, 7, 9, 0, 0, 0, time.UTC), run)
+
+	_, ok = weekly.NextRun(run)
+	require.False(t, ok)
+
+	monthly := Schedule{Frequency: FrequencyMonthly, Interval: 1, StartAt: startAt}
+
+	run, ok = monthly.NextRun(startAt)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC), run)
+
+	run, ok = monthly.NextRun(run)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC), run)
+
+	quarterly := Schedule{Frequency: FrequencyMonthly, Interval: 3, StartAt: startAt}
+
+	run, ok = quarterly.NextRun(startAt)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, time.April, 30, 9, 0, 0, 0, time.UTC), run)
+}
+
+func TestIsSupportedFrequency(t *testing.T) {
+	require.True(t, IsSupportedFrequency(FrequencyMonthly))
+	require.False(t, IsSupportedFrequency("yearly"))
+}
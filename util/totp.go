@@ -0,0 +1,109 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	totpDigits = 6
+	totpPeriod = 30 * time.Second
+	// totpSkew is the number of periods before and after the current one in which a code is still accepted
+	totpSkew = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a new random base32 encoded TOTP secret
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("Failed to generate TOTP secret: %v", err)
+	}
+
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPCode returns the RFC 6238 code of the secret at the given time
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+
+	if err != nil {
+		return "", fmt.Errorf("Invalid TOTP secret: %v", err)
+	}
+
+	return hotpCode(key, uint64(TOTPStep(t))), nil
+}
+
+// TOTPStep returns the time step of the given time, which identifies the code valid at it
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(totpPeriod.Seconds())
+}
+
+// ValidateTOTPCode checks if the code matches the secret at the given time, tolerating a small clock skew
+func ValidateTOTPCode(secret string, code string, t time.Time) bool {
+	_, ok := MatchTOTPCode(secret, code, t)
+	return ok
+}
+
+// MatchTOTPCode is like ValidateTOTPCode, but also returns the time step the code matched,
+// so callers can reject a code that was already used
+func MatchTOTPCode(secret string, code string, t time.Time) (int64, bool) {
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		skewedTime := t.Add(time.Duration(skew) * totpPeriod)
+		expectedCode, err := TOTPCode(secret, skewedTime)
+
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expectedCode), []byte(code)) == 1 {
+			return TOTPStep(skewedTime), true
+		}
+	}
+
+	return 0, false
+}
+
+// TOTPURI returns the otpauth:// URI used by authenticator apps to enroll the secret
+func TOTPURI(issuer string, accountName string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+
+	label := url.PathEscape(issuer + ":" + accountName)
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+// hotpCode implements the RFC 4226 HOTP algorithm, on which TOTP is based
+func hotpCode(key []byte, counter uint64) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", totpDigits, value%modulo)
+}
@@ -0,0 +1,76 @@
+package util
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA1 secret of the RFC 6238 test vectors, base32 encoded
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	testCases := []struct {
+		unixTime int64
+		code     string
+	}{
+		{unixTime: 59, code: "287082"},
+		{unixTime: 1111111109, code: "081804"},
+		{unixTime: 1111111111, code: "050471"},
+		{unixTime: 1234567890, code: "005924"},
+		{unixTime: 2000000000, code: "279037"},
+	}
+
+	for _, testCase := range testCases {
+		code, err := TOTPCode(rfc6238Secret, time.Unix(testCase.unixTime, 0))
+		require.NoError(t, err)
+		require.Equal(t, testCase.code, code)
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 32)
+
+	now := time.Now()
+
+	code, err := TOTPCode(secret, now)
+	require.NoError(t, err)
+
+	require.True(t, ValidateTOTPCode(secret, code, now))
+	require.True(t, ValidateTOTPCode(secret, code, now.Add(30*time.Second)))
+	require.False(t, ValidateTOTPCode(secret, code, now.Add(2*time.Minute)))
+	require.False(t, ValidateTOTPCode(secret, "12345", now))
+	require.False(t, ValidateTOTPCode("not base32!", code, now))
+}
+
+func TestMatchTOTPCode(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	code, err := TOTPCode(secret, now)
+	require.NoError(t, err)
+
+	step, ok := MatchTOTPCode(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, TOTPStep(now), step)
+
+	// the code of the current step is still accepted in the next one, but matches its own step
+	step, ok = MatchTOTPCode(secret, code, now.Add(30*time.Second))
+	require.True(t, ok)
+	require.Equal(t, TOTPStep(now), step)
+
+	_, ok = MatchTOTPCode(secret, "12345", now)
+	require.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Simple Bank", "johndoe", rfc6238Secret)
+
+	require.Equal(t, "otpauth://totp/Simple%20Bank:johndoe?algorithm=SHA1&digits=6&issuer=Simple+Bank&period=30&secret="+rfc6238Secret, uri)
+}
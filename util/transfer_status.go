@@ -0,0 +1,9 @@
+package util
+
+// Statuses a transfer goes through. Transfers are posted right away unless they are authorized first,
+// in which case they stay pending, holding the money, until they are captured or voided
+const (
+	TransferStatusPending = "pending"
+	TransferStatusPosted  = "posted"
+	TransferStatusVoided  = "voided"
+)
@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+)
+
+// ScheduledTransferExecutor polls the database for scheduled transfers that are due and makes them.
+// Several executors can run at once, even in different processes, since each run is claimed by a single one of them
+type ScheduledTransferExecutor struct {
+	store        db.Store
+	pollInterval time.Duration
+	now          func() time.Time
+}
+
+// NewScheduledTransferExecutor creates a ScheduledTransferExecutor that looks for due scheduled transfers every poll interval
+func NewScheduledTransferExecutor(store db.Store, pollInterval time.Duration) *ScheduledTransferExecutor {
+	return &ScheduledTransferExecutor{
+		store:        store,
+		pollInterval: pollInterval,
+		now:          time.Now,
+	}
+}
+
+// Start executes the due scheduled transfers every poll interval, until the context is done
+func (executor *ScheduledTransferExecutor) Start(ctx context.Context) {
+	ticker := time.NewTicker(executor.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := executor.ExecuteDue(ctx); err != nil {
+			log.Printf("could not execute scheduled transfers: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExecuteDue executes the scheduled transfers that are due, one transaction each, and returns how many were executed.
+// Failed transfers are recorded as failed executions and don't stop the others
+func (executor *ScheduledTransferExecutor) ExecuteDue(ctx context.Context) (int, error) {
+	now := executor.now()
+	executed := 0
+
+	for ctx.Err() == nil {
+		result, err := executor.store.ExecuteScheduledTransferTx(ctx, db.ExecuteScheduledTransferTxParams{Now: now})
+
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return executed, nil
+			}
+
+			return executed, err
+		}
+
+		executed++
+
+		if result.Execution.Error.Valid {
+			log.Printf("scheduled transfer %d failed: %s", result.ScheduledTransfer.ID, result.Execution.Error.String)
+		}
+	}
+
+	return executed, ctx.Err()
+}
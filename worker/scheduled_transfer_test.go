@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	mockdb "github.com/Andrew-2609/simple-bank/db/mock"
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+)
+
+func TestExecuteDue(t *testing.T) {
+	now := time.Now()
+
+	succeeded := db.ExecuteScheduledTransferTxResult{
+		ScheduledTransfer: db.ScheduledTransfer{ID: 1},
+		Execution:         db.ScheduledTransferExecution{ID: 1, TransferID: sql.NullInt64{Int64: 1, Valid: true}},
+	}
+
+	failed := db.ExecuteScheduledTransferTxResult{
+		ScheduledTransfer: db.ScheduledTransfer{ID: 2},
+		Execution:         db.ScheduledTransferExecution{ID: 2, Error: sql.NullString{String: "insufficient funds", Valid: true}},
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, executed int, err error)
+	}{
+		{
+			name: "Executes Until None Is Due",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ExecuteScheduledTransferTxParams{Now: now}
+
+				gomock.InOrder(
+					store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(succeeded, nil),
+					store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(failed, nil),
+					store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.ExecuteScheduledTransferTxResult{}, sql.ErrNoRows),
+				)
+			},
+			checkResponse: func(t *testing.T, executed int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 2, executed)
+			},
+		},
+		{
+			name: "None Due",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ExecuteScheduledTransferTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, executed int, err error) {
+				require.NoError(t, err)
+				require.Zero(t, executed)
+			},
+		},
+		{
+			name: "Internal Error",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(succeeded, nil),
+					store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ExecuteScheduledTransferTxResult{}, sql.ErrConnDone),
+				)
+			},
+			checkResponse: func(t *testing.T, executed int, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Equal(t, 1, executed)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+
+			executor := NewScheduledTransferExecutor(store, time.Minute)
+			executor.now = func() time.Time { return now }
+
+			executed, err := executor.ExecuteDue(context.Background())
+
+			testCase.checkResponse(t, executed, err)
+		})
+	}
+}
+
+func TestExecuteDueCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	executed, err := NewScheduledTransferExecutor(store, time.Minute).ExecuteDue(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, executed)
+}
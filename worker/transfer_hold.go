@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+)
+
+// TransferHoldExpirer polls the database for pending transfers whose hold expired and voids them,
+// giving the money they held back to their from accounts
+type TransferHoldExpirer struct {
+	store        db.Store
+	pollInterval time.Duration
+	now          func() time.Time
+}
+
+// NewTransferHoldExpirer creates a TransferHoldExpirer that looks for expired holds every poll interval
+func NewTransferHoldExpirer(store db.Store, pollInterval time.Duration) *TransferHoldExpirer {
+	return &TransferHoldExpirer{
+		store:        store,
+		pollInterval: pollInterval,
+		now:          time.Now,
+	}
+}
+
+// Start voids the expired holds every poll interval, until the context is done
+func (expirer *TransferHoldExpirer) Start(ctx context.Context) {
+	ticker := time.NewTicker(expirer.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := expirer.VoidExpired(ctx); err != nil {
+			log.Printf("could not void expired transfer holds: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// VoidExpired voids the pending transfers whose hold expired, one transaction each, and returns how many were voided
+func (expirer *TransferHoldExpirer) VoidExpired(ctx context.Context) (int, error) {
+	now := expirer.now()
+	voided := 0
+
+	for ctx.Err() == nil {
+		_, err := expirer.store.VoidExpiredTransferTx(ctx, db.VoidExpiredTransferTxParams{Now: now})
+
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return voided, nil
+			}
+
+			return voided, err
+		}
+
+		voided++
+	}
+
+	return voided, ctx.Err()
+}
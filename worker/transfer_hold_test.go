@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	mockdb "github.com/Andrew-2609/simple-bank/db/mock"
+	db "github.com/Andrew-2609/simple-bank/db/sqlc"
+	"github.com/Andrew-2609/simple-bank/util"
+)
+
+func TestVoidExpired(t *testing.T) {
+	now := time.Now()
+
+	voided := db.TransferTxResult{
+		Transfer: db.Transfer{ID: 1, Status: util.TransferStatusVoided},
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, voided int, err error)
+	}{
+		{
+			name: "Voids Until None Expired",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.VoidExpiredTransferTxParams{Now: now}
+
+				gomock.InOrder(
+					store.EXPECT().VoidExpiredTransferTx(gomock.Any(), gomock.Eq(arg)).Times(2).Return(voided, nil),
+					store.EXPECT().VoidExpiredTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{}, sql.ErrNoRows),
+				)
+			},
+			checkResponse: func(t *testing.T, voided int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 2, voided)
+			},
+		},
+		{
+			name: "None Expired",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VoidExpiredTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, voided int, err error) {
+				require.NoError(t, err)
+				require.Zero(t, voided)
+			},
+		},
+		{
+			name: "Internal Error",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().VoidExpiredTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(voided, nil),
+					store.EXPECT().VoidExpiredTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, sql.ErrConnDone),
+				)
+			},
+			checkResponse: func(t *testing.T, voided int, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Equal(t, 1, voided)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+
+			expirer := NewTransferHoldExpirer(store, time.Minute)
+			expirer.now = func() time.Time { return now }
+
+			voided, err := expirer.VoidExpired(context.Background())
+
+			testCase.checkResponse(t, voided, err)
+		})
+	}
+}